@@ -0,0 +1,43 @@
+#!/usr/bin/env bash
+# Licensed to the Apache Software Foundation (ASF) under one or more
+# contributor license agreements.  See the NOTICE file distributed with
+# this work for additional information regarding copyright ownership.
+# The ASF licenses this file to You under the Apache License, Version 2.0
+# (the "License"); you may not use this file except in compliance with
+# the License.  You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Applies the schema migrations of the versions after FROM_VERSION up to and
+# including TO_VERSION. A migration is upgrade/<version>.sql, e.g.
+# upgrade/0.7.0.sql, and is run against the submarine database.
+# It is run by the migration Job of submarine-operator.
+
+set -euo pipefail
+
+UPGRADE_DIR=$(dirname "$0")/upgrade
+
+if [ ! -d "${UPGRADE_DIR}" ]; then
+  echo "No migrations from ${FROM_VERSION} to ${TO_VERSION}"
+  exit 0
+fi
+
+for script in $(ls "${UPGRADE_DIR}" | grep '\.sql$' | sort -V); do
+  version=${script%.sql}
+  # Skip versions up to and including FROM_VERSION
+  if [ "$(printf '%s\n%s\n' "${version}" "${FROM_VERSION}" | sort -V | tail -n 1)" = "${FROM_VERSION}" ]; then
+    continue
+  fi
+  # Stop after TO_VERSION
+  if [ "$(printf '%s\n%s\n' "${version}" "${TO_VERSION}" | sort -V | tail -n 1)" != "${TO_VERSION}" ]; then
+    break
+  fi
+  echo "Applying ${script}"
+  mysql -h "${DATABASE_HOST}" -uroot -p"${MYSQL_ROOT_PASSWORD}" submarine < "${UPGRADE_DIR}/${script}"
+done
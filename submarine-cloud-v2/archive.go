@@ -0,0 +1,383 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"submarine-cloud-v2/pkg/logarchive"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// logArchiveAnnotation holds the location of the archived logs of a
+	// finished job, read by the server once the pods are gone
+	logArchiveAnnotation = "submarine.k8s.io/log-archive"
+	// logsNotFoundAnnotation marks a finished job whose pods were gone
+	// before its logs could be archived
+	logsNotFoundAnnotation = "submarine.k8s.io/logs-not-found"
+	// experimentIDEnv is set by the server in the containers of the jobs of
+	// the experiments
+	experimentIDEnv = "JOB_ID"
+	// logArchiverMountPath is where the archiver mounts the claim of
+	// spec.logArchive.persistentVolumeClaim
+	logArchiverMountPath = "/archive"
+	// logArchiveTimeout bounds the archival of the logs of a job, which runs
+	// in the worker of the Submarines
+	logArchiveTimeout = 5 * time.Minute
+)
+
+const (
+	// LogsArchived is used as part of the Event 'reason' when the logs of a
+	// finished job are archived
+	LogsArchived = "LogsArchived"
+	// MessageLogsArchived is the message used for an Event fired when the
+	// logs of a finished job are archived
+	MessageLogsArchived = "Archived the logs of %d containers of %s %s to %s"
+	// ErrLogArchive is used as part of the Event 'reason' when the logs of a
+	// finished job cannot be archived
+	ErrLogArchive = "ErrLogArchive"
+	// MessageErrLogArchive is the message used for an Event fired when the
+	// logs of a finished job cannot be archived. The archival is retried on
+	// the next sync.
+	MessageErrLogArchive = "Cannot archive the logs of %s %s: %v"
+	// LogsNotFound is used as part of the Event 'reason' when the pods of a
+	// finished job are gone before its logs are archived
+	LogsNotFound = "LogsNotFound"
+	// MessageLogsNotFound is the message used for an Event fired when the
+	// pods of a finished job are gone before its logs are archived. The job
+	// is marked with the logsNotFoundAnnotation and is not archived again.
+	MessageLogsNotFound = "No pods of %s %s are left to archive the logs of"
+)
+
+func logArchiverName(submarine *v1alpha1.Submarine) string {
+	return submarine.Name + "-log-archiver"
+}
+
+// newLogStoreFunc returns a function building the store of
+// spec.logArchive. The credentials of a bucket are read from their Secret
+// on every call, so that they can be rotated.
+func (c *Controller) newLogStoreFunc(restConfig *rest.Config) func(submarine *v1alpha1.Submarine, namespace string) (logarchive.Store, error) {
+	return func(submarine *v1alpha1.Submarine, namespace string) (logarchive.Store, error) {
+		archive := submarine.Spec.LogArchive
+		switch {
+		case archive.S3 != nil:
+			secret, err := c.kubeclientset.CoreV1().Secrets(namespace).Get(context.TODO(), archive.S3.CredentialsSecret, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return logarchive.NewS3Store(logarchive.S3Config{
+				Endpoint:  archive.S3.Endpoint,
+				Bucket:    archive.S3.Bucket,
+				Region:    archive.S3.Region,
+				Prefix:    archive.S3.Prefix,
+				AccessKey: string(secret.Data["accessKey"]),
+				SecretKey: string(secret.Data["secretKey"]),
+			})
+		case archive.PersistentVolumeClaim != nil:
+			return logarchive.NewVolumeStore(restConfig, c.kubeclientset, logarchive.VolumeConfig{
+				Namespace: namespace,
+				Selector:  map[string]string{"app": logArchiverName(submarine)},
+				Container: "archiver",
+				MountPath: logArchiverMountPath,
+				ClaimName: archive.PersistentVolumeClaim.ClaimName,
+			}), nil
+		}
+		return nil, fmt.Errorf("spec.logArchive needs s3 or persistentVolumeClaim")
+	}
+}
+
+// logArchiverEnabled returns whether the logs are archived into a volume,
+// which needs the Deployment of the archiver
+func logArchiverEnabled(submarine *v1alpha1.Submarine) bool {
+	return submarine.Spec.LogArchive != nil && submarine.Spec.LogArchive.PersistentVolumeClaim != nil
+}
+
+// newLogArchiverDeployment returns the Deployment mounting the claim of
+// spec.logArchive.persistentVolumeClaim. The operator writes the logs
+// through it.
+func newLogArchiverDeployment(submarine *v1alpha1.Submarine) *appsv1.Deployment {
+	name := logArchiverName(submarine)
+	selector := map[string]string{"app": name}
+	replicas := int32(1)
+	gracePeriod := int64(0)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: submarineLabels(submarine, "log-archiver", selector),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+			// A ReadWriteOnce claim cannot be mounted by two pods of
+			// different nodes
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: submarineLabels(submarine, "log-archiver", selector),
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "archiver",
+							Image:   logArchiverImage(submarine),
+							Command: []string{"tail", "-f", "/dev/null"},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "archive",
+									MountPath: logArchiverMountPath,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "archive",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: submarine.Spec.LogArchive.PersistentVolumeClaim.ClaimName,
+								},
+							},
+						},
+					},
+					ImagePullSecrets:              submarine.Spec.ImagePullSecrets,
+					TerminationGracePeriodSeconds: &gracePeriod,
+				},
+			},
+		},
+	}
+}
+
+// reconcileLogArchiver maintains the Deployment of the archiver while
+// spec.logArchive.persistentVolumeClaim is set, and deletes it otherwise
+func (c *Controller) reconcileLogArchiver(submarine *v1alpha1.Submarine, namespace string) error {
+	client := c.kubeclientset.AppsV1().Deployments(namespace)
+	if !logArchiverEnabled(submarine) {
+		deployment, err := c.deploymentLister.Deployments(namespace).Get(logArchiverName(submarine))
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(deployment, submarine) {
+			return nil
+		}
+		klog.Info("	Delete Deployment: ", deployment.Name)
+		return client.Delete(context.TODO(), deployment.Name, metav1.DeleteOptions{})
+	}
+
+	desired := newLogArchiverDeployment(submarine)
+	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(desired.Name)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(deployment_err) {
+		deployment, deployment_err = client.Create(context.TODO(), desired, metav1.CreateOptions{})
+		if deployment_err != nil {
+			klog.Info(deployment_err)
+		}
+		klog.Info("	Create Deployment: ", desired.Name)
+	}
+	if deployment_err != nil {
+		return deployment_err
+	}
+	if err := c.claimObject(submarine, deployment, "log-archiver", func(patch []byte) error {
+		deployment, deployment_err = client.Patch(context.TODO(), deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return deployment_err
+	}); err != nil {
+		return err
+	}
+	// Update the Deployment if the claim or the image changed
+	if !equality.Semantic.DeepDerivative(desired.Spec.Template.Spec, deployment.Spec.Template.Spec) {
+		deploymentCopy := deployment.DeepCopy()
+		deploymentCopy.Spec.Template = desired.Spec.Template
+		_, deployment_err = client.Update(context.TODO(), deploymentCopy, metav1.UpdateOptions{})
+		return deployment_err
+	}
+	return nil
+}
+
+// experimentID returns the ID of the experiment of a job, set by the server
+// in the environment of its containers. Jobs created without the server
+// use their name.
+func experimentID(job *unstructured.Unstructured, jobType experimentJob) string {
+	for _, spec := range replicaSpecs(job, jobType) {
+		spec, ok := spec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(spec, "template", "spec", "containers")
+		for _, container := range containers {
+			container, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			env, _, _ := unstructured.NestedSlice(container, "env")
+			for _, variable := range env {
+				variable, ok := variable.(map[string]interface{})
+				if ok && variable["name"] == experimentIDEnv && variable["value"] != "" && variable["value"] != nil {
+					return fmt.Sprint(variable["value"])
+				}
+			}
+		}
+	}
+	return job.GetName()
+}
+
+// archiveJobLogs copies the logs of the containers of the finished TFJobs
+// and PyTorchJobs of the Submarine, as selected by isSubmarineJob, into the
+// store of spec.logArchive, then records the location in the
+// logArchiveAnnotation of each job. A job that cannot be archived is retried
+// on the next sync, and is not deleted by the cleanup until then.
+func (c *Controller) archiveJobLogs(submarine *v1alpha1.Submarine, namespace string) error {
+	if err := c.reconcileLogArchiver(submarine, namespace); err != nil {
+		return err
+	}
+	if submarine.Spec.LogArchive == nil {
+		return nil
+	}
+	alone, err := c.aloneInNamespace(submarine, namespace)
+	if err != nil {
+		return err
+	}
+
+	var store logarchive.Store
+	for resource, jobType := range queuedJobTypes {
+		jobs, err := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(resource)).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		c.watchJobs(resource)
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			if job.GetDeletionTimestamp() != nil || logsArchived(job) || !isSubmarineJob(job, submarine, alone) {
+				continue
+			}
+			if phase, _ := jobPhase(job); phase != v1alpha1.ExperimentSucceeded && phase != v1alpha1.ExperimentFailed {
+				continue
+			}
+			// The store is only built once a job needs it
+			if store == nil {
+				if store, err = c.logStore(submarine, namespace); err != nil {
+					c.recorder.Eventf(submarine, corev1.EventTypeWarning, ErrLogArchive, MessageErrLogArchive, job.GetKind(), job.GetName(), err)
+					return nil
+				}
+			}
+			if err := c.archiveJob(submarine, namespace, resource, job, jobType, store); err != nil {
+				klog.Info("Cannot archive the logs of ", job.GetKind(), " ", job.GetName(), ": ", err)
+				c.recorder.Eventf(submarine, corev1.EventTypeWarning, ErrLogArchive, MessageErrLogArchive, job.GetKind(), job.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// logsArchived returns whether archiveJob is done with a job: its logs are
+// archived, or its pods were gone
+func logsArchived(job *unstructured.Unstructured) bool {
+	annotations := job.GetAnnotations()
+	return annotations[logArchiveAnnotation] != "" || annotations[logsNotFoundAnnotation] != ""
+}
+
+// annotateJob merges annotations into the annotations of a job
+func (c *Controller) annotateJob(namespace string, resource string, job *unstructured.Unstructured, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(resource)).Namespace(namespace).Patch(context.TODO(), job.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// archiveJob streams the logs of the containers of the pods of a job into
+// store and annotates the job with their location. A job without pods gets
+// the logsNotFoundAnnotation instead, so that it is reported once and the
+// cleanup can delete it.
+func (c *Controller) archiveJob(submarine *v1alpha1.Submarine, namespace string, resource string, job *unstructured.Unstructured, jobType experimentJob, store logarchive.Store) error {
+	id := experimentID(job, jobType)
+	pods, err := c.jobPods(namespace, job, fields.Everything())
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		if err := c.annotateJob(namespace, resource, job, map[string]string{logsNotFoundAnnotation: "true"}); err != nil {
+			return err
+		}
+		c.recorder.Eventf(submarine, corev1.EventTypeWarning, LogsNotFound, MessageLogsNotFound, job.GetKind(), job.GetName())
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), logArchiveTimeout)
+	defer cancel()
+	archived := 0
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if err := c.archiveContainer(ctx, namespace, pod.Name, container.Name, logarchive.Key(id, pod.Name, container.Name), store); err != nil {
+				return err
+			}
+			archived++
+		}
+	}
+
+	location := store.Location(logarchive.ExperimentDir(id)) + "/"
+	if err := c.annotateJob(namespace, resource, job, map[string]string{logArchiveAnnotation: location}); err != nil {
+		return err
+	}
+	c.recorder.Eventf(submarine, corev1.EventTypeNormal, LogsArchived, MessageLogsArchived, archived, job.GetKind(), job.GetName(), location)
+	return nil
+}
+
+// archiveContainer streams the log of a container into store under key.
+// Cancelling ctx closes the log stream, which ends the copy.
+func (c *Controller) archiveContainer(ctx context.Context, namespace string, pod string, container string, key string, store logarchive.Store) error {
+	logs, err := c.kubeclientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read the logs of container %s of pod %s: %v", container, pod, err)
+	}
+	defer logs.Close()
+	return store.Put(ctx, key, logs)
+}
@@ -0,0 +1,232 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"submarine-cloud-v2/pkg/logarchive"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// memoryStore is a logarchive.Store keeping the logs in a map
+type memoryStore struct {
+	logs map[string]string
+	err  error
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, data io.Reader) error {
+	if s.err != nil {
+		return s.err
+	}
+	logs, err := ioutil.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	s.logs[key] = string(logs)
+	return nil
+}
+
+func (s *memoryStore) Location(key string) string {
+	return "memory://" + key
+}
+
+// newArchiveTestJob returns a TFJob in phase, whose containers have the
+// experiment ID id if it is set
+func newArchiveTestJob(name string, id string, phase v1alpha1.ExperimentPhase) *unstructured.Unstructured {
+	job := newQueueTestJob(name, 2, "1", "", "", 0)
+	if id != "" {
+		unstructured.SetNestedSlice(job.Object, []interface{}{
+			map[string]interface{}{
+				"name": "tensorflow",
+				"env": []interface{}{
+					map[string]interface{}{"name": experimentIDEnv, "value": id},
+				},
+			},
+		}, "spec", "tfReplicaSpecs", "Worker", "template", "spec", "containers")
+	}
+	unstructured.SetNestedSlice(job.Object, []interface{}{
+		map[string]interface{}{"type": string(phase), "status": "True"},
+	}, "status", "conditions")
+	return job
+}
+
+// newArchiveTestPod returns a pod of job with containers
+func newArchiveTestPod(name string, job *unstructured.Unstructured, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "submarine-user-test",
+			Labels:    map[string]string{"training.kubeflow.org/job-name": job.GetName()},
+		},
+	}
+	pod.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(job, kubeflowGroupVersion.WithKind("TFJob")),
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+	}
+	return pod
+}
+
+func TestArchiveJobLogs(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.LogArchive = &v1alpha1.SubmarineLogArchive{
+		S3: &v1alpha1.SubmarineS3Archive{Endpoint: "http://minio:9000", Bucket: "logs", CredentialsSecret: "minio"},
+	}
+	succeeded := newArchiveTestJob("experiment-1", "experiment-1616390573484-0001", v1alpha1.ExperimentSucceeded)
+	failed := newArchiveTestJob("failed", "", v1alpha1.ExperimentFailed)
+	running := newArchiveTestJob("running", "", v1alpha1.ExperimentRunning)
+	// The pods were deleted by the operator of the job
+	withoutPods := newArchiveTestJob("without-pods", "", v1alpha1.ExperimentSucceeded)
+	// The jobs of the other Submarines of the namespace are archived by them
+	other := newArchiveTestJob("other", "", v1alpha1.ExperimentSucceeded)
+	other.SetLabels(map[string]string{submarineNameLabel: "other"})
+	c, _, dynamicClient := newTestController(t, testControllerOptions{
+		objects: []runtime.Object{
+			succeeded,
+			failed,
+			running,
+			withoutPods,
+			other,
+			newArchiveTestPod("experiment-1-worker-0", succeeded, "tensorflow", "sidecar"),
+			newArchiveTestPod("experiment-1-worker-1", succeeded, "tensorflow"),
+			newArchiveTestPod("failed-worker-0", failed, "tensorflow"),
+			newArchiveTestPod("running-worker-0", running, "tensorflow"),
+			newArchiveTestPod("other-worker-0", other, "tensorflow"),
+		},
+	})
+	store := &memoryStore{logs: map[string]string{}, err: fmt.Errorf("connection refused")}
+	c.logStore = func(*v1alpha1.Submarine, string) (logarchive.Store, error) {
+		return store, nil
+	}
+	annotation := func(name string) string {
+		job, err := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job.GetAnnotations()[logArchiveAnnotation]
+	}
+
+	// The store is unavailable: nothing is archived, and retried later
+	if err := c.archiveJobLogs(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := annotation("experiment-1"); got != "" {
+		t.Errorf("annotation = %q before the logs are archived", got)
+	}
+	if event := <-c.recorder.(*record.FakeRecorder).Events; event == "" {
+		t.Error("no event")
+	}
+
+	store.err = nil
+	if err := c.archiveJobLogs(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"experiment-1616390573484-0001/experiment-1-worker-0/tensorflow.log": "fake logs",
+		"experiment-1616390573484-0001/experiment-1-worker-0/sidecar.log":    "fake logs",
+		"experiment-1616390573484-0001/experiment-1-worker-1/tensorflow.log": "fake logs",
+		"failed/failed-worker-0/tensorflow.log":                              "fake logs",
+	}
+	if !reflect.DeepEqual(store.logs, want) {
+		t.Errorf("logs = %v, want %v", store.logs, want)
+	}
+	if got := annotation("experiment-1"); got != "memory://experiment-1616390573484-0001/" {
+		t.Errorf("annotation = %q", got)
+	}
+	if got := annotation("failed"); got != "memory://failed/" {
+		t.Errorf("annotation = %q", got)
+	}
+	if got := annotation("running"); got != "" {
+		t.Errorf("annotation of a running job = %q", got)
+	}
+	if got := annotation("without-pods"); got != "" {
+		t.Errorf("annotation of a job without pods = %q", got)
+	}
+	if job, err := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test").Get(context.TODO(), "without-pods", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	} else if !logsArchived(job) {
+		t.Errorf("expected the job without pods to be marked with %s", logsNotFoundAnnotation)
+	}
+	if got := annotation("other"); got != "" {
+		t.Errorf("annotation of a job of another Submarine = %q", got)
+	}
+	notFound := false
+	for len(c.recorder.(*record.FakeRecorder).Events) > 0 {
+		if event := <-c.recorder.(*record.FakeRecorder).Events; strings.Contains(event, LogsNotFound) {
+			notFound = true
+		}
+	}
+	if !notFound {
+		t.Errorf("expected a %s event", LogsNotFound)
+	}
+
+	// Archived jobs and jobs without pods are skipped
+	store.logs = map[string]string{}
+	if err := c.archiveJobLogs(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.logs) != 0 {
+		t.Errorf("logs archived again: %v", store.logs)
+	}
+	if events := len(c.recorder.(*record.FakeRecorder).Events); events != 0 {
+		t.Errorf("expected no events, got %d", events)
+	}
+}
+
+func TestLogArchiver(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.LogArchive = &v1alpha1.SubmarineLogArchive{
+		PersistentVolumeClaim: &v1alpha1.SubmarineVolumeArchive{ClaimName: "logs"},
+	}
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+
+	if err := c.reconcileLogArchiver(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	deployment, err := kubeClient.AppsV1().Deployments("submarine-user-test").Get(context.TODO(), logArchiverName(submarine), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim := deployment.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; claim != "logs" {
+		t.Errorf("claim = %q, want logs", claim)
+	}
+
+	// Without the claim: deleted
+	submarine.Spec.LogArchive = nil
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{deployment}})
+	if err := c.reconcileLogArchiver(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.AppsV1().Deployments("submarine-user-test").Get(context.TODO(), logArchiverName(submarine), metav1.GetOptions{}); err == nil {
+		t.Error("the archiver is not deleted")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"submarine-cloud-v2/pkg/charts"
+	"submarine-cloud-v2/pkg/helm"
+)
+
+// chartOverrides replaces embedded subcharts by name. It is set with the
+// repeatable flag -chart NAME=SOURCE.
+type chartOverrides map[string]helm.ChartSource
+
+func (o chartOverrides) String() string {
+	var overrides []string
+	for name, source := range o {
+		overrides = append(overrides, name+"="+source.String())
+	}
+	sort.Strings(overrides)
+	return strings.Join(overrides, ",")
+}
+
+func (o chartOverrides) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("expected NAME=SOURCE, got %q", value)
+	}
+	o[parts[0]] = parseChartSource(parts[1])
+	return nil
+}
+
+// parseChartSource parses the source of a chart: a chart repository
+// followed by the chart name and optionally a version, e.g.
+// https://helm.traefik.io/traefik/traefik@9.1.0, or else a directory
+func parseChartSource(source string) helm.ChartSource {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return helm.LocalChart{Path: source}
+	}
+	version := ""
+	if i := strings.LastIndex(source, "@"); i > strings.LastIndex(source, "/") {
+		source, version = source[:i], source[i+1:]
+	}
+	i := strings.LastIndex(source, "/")
+	return helm.RepoChart{URL: source[:i], Name: source[i+1:], Version: version}
+}
+
+// subChartSource returns the source of a subchart: its override, else the
+// chart embedded in the binary
+func subChartSource(name string, overrides chartOverrides) helm.ChartSource {
+	if source, ok := overrides[name]; ok {
+		return source
+	}
+	return helm.EmbeddedChart{FS: charts.FS, Name: name}
+}
+
+// localChartOverrides loads every subchart from chartsDir/<name>. An empty
+// chartsDir keeps the embedded charts.
+func localChartOverrides(chartsDir string) chartOverrides {
+	overrides := chartOverrides{}
+	if chartsDir == "" {
+		return overrides
+	}
+	for _, operator := range workloadOperators {
+		name := string(operator.name)
+		overrides[name] = helm.LocalChart{Path: filepath.Join(chartsDir, name)}
+	}
+	overrides["traefik"] = helm.LocalChart{Path: filepath.Join(chartsDir, "traefik")}
+	return overrides
+}
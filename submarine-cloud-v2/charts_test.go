@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"submarine-cloud-v2/pkg/helm"
+)
+
+func TestParseChartSource(t *testing.T) {
+	for _, test := range []struct {
+		source   string
+		expected helm.ChartSource
+	}{
+		{"./charts/traefik", helm.LocalChart{Path: "./charts/traefik"}},
+		{"/opt/charts/tfjob-0.2.0.tgz", helm.LocalChart{Path: "/opt/charts/tfjob-0.2.0.tgz"}},
+		{"https://helm.traefik.io/traefik/traefik@9.1.0", helm.RepoChart{URL: "https://helm.traefik.io/traefik", Name: "traefik", Version: "9.1.0"}},
+		{"http://charts.example.com:8080/tfjob", helm.RepoChart{URL: "http://charts.example.com:8080", Name: "tfjob"}},
+	} {
+		if actual := parseChartSource(test.source); actual != test.expected {
+			t.Errorf("parseChartSource(%q) = %#v, expected %#v", test.source, actual, test.expected)
+		}
+	}
+}
+
+func TestChartOverrides(t *testing.T) {
+	overrides := chartOverrides{}
+	if err := overrides.Set("tfjob"); err == nil {
+		t.Errorf("expected an error without a source")
+	}
+	if err := overrides.Set("tfjob=https://charts.example.com/tfjob@0.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if source := subChartSource("tfjob", overrides); source.String() != "https://charts.example.com/tfjob@0.2.0" {
+		t.Errorf("subChartSource(tfjob) = %s, expected the override", source)
+	}
+	if source := subChartSource("pytorchjob", overrides); source.String() != "embedded" {
+		t.Errorf("subChartSource(pytorchjob) = %s, expected the embedded chart", source)
+	}
+}
+
+func TestEmbeddedCharts(t *testing.T) {
+	for _, name := range []string{"traefik", "notebook-controller", "tfjob", "pytorchjob", "mpijob", "xgboostjob"} {
+		chart, err := subChartSource(name, nil).Load()
+		if err != nil {
+			t.Errorf("error loading embedded chart %s: %v", name, err)
+			continue
+		}
+		if chart.Name() != name || chart.Metadata.Version == "" {
+			t.Errorf("embedded chart %s has name %q and version %q", name, chart.Name(), chart.Metadata.Version)
+		}
+	}
+}
+
+func TestChartOperation(t *testing.T) {
+	c, _, _ := newTestController(t, testControllerOptions{})
+	submarine := newTestSubmarine()
+	release := make(chan struct{})
+	started := 0
+	operation := func() error {
+		started++
+		<-release
+		return fmt.Errorf("timed out waiting for the condition")
+	}
+
+	c.startChartOperation(submarine, "submarine-user-test", "traefik", operation)
+	// The release is busy: not started again
+	c.startChartOperation(submarine, "submarine-user-test", "traefik", operation)
+	if running, err := c.chartOperationResult("submarine-user-test", "traefik"); !running || err != nil {
+		t.Fatalf("running, err = %t, %v, want a running operation", running, err)
+	}
+
+	close(release)
+	// The Submarine is synced once the operation finishes
+	item, _ := c.workqueue.Get()
+	if item.(WorkQueueItem).key != "submarine-user-test/example-submarine" {
+		t.Errorf("unexpected item %v", item)
+	}
+	if started != 1 {
+		t.Errorf("started %d operations, want 1", started)
+	}
+	if running, err := c.chartOperationResult("submarine-user-test", "traefik"); running || err == nil {
+		t.Errorf("running, err = %t, %v, want the error of the operation", running, err)
+	}
+	// The error is reported once
+	if running, err := c.chartOperationResult("submarine-user-test", "traefik"); running || err != nil {
+		t.Errorf("running, err = %t, %v, want no operation", running, err)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// JobDeleted is used as part of the Event 'reason' when a finished job
+	// is deleted by the cleanup
+	JobDeleted = "JobDeleted"
+	// MessageJobDeleted is the message used for an Event fired when a
+	// finished job is deleted by the cleanup
+	MessageJobDeleted = "Deleted %s %s, %s since %s"
+)
+
+// completedJob is a job that succeeded or failed
+type completedJob struct {
+	resource string
+	job      *unstructured.Unstructured
+	phase    v1alpha1.ExperimentPhase
+	finished time.Time
+}
+
+// jobResources are the resources of the jobs of the workload operators
+func jobResources() []string {
+	var resources []string
+	for _, operator := range workloadOperators {
+		if operator.name != v1alpha1.OperatorNotebookController {
+			resources = append(resources, operator.resources[0])
+		}
+	}
+	return resources
+}
+
+// cleanupWorkloads deletes the finished jobs of the Submarine whose TTL
+// passed and stops the idle notebooks of the namespace, as set by
+// spec.cleanup. The resources of operators that are not installed are
+// skipped.
+func (c *Controller) cleanupWorkloads(submarine *v1alpha1.Submarine, namespace string) error {
+	cleanup := submarine.Spec.Cleanup
+	if cleanup == nil {
+		return nil
+	}
+	now := time.Now()
+
+	if err := c.cleanupJobs(submarine, namespace, cleanup, now); err != nil {
+		return err
+	}
+	if cleanup.NotebookIdleTimeoutSeconds != nil {
+		return c.cullNotebooks(submarine, namespace, time.Duration(*cleanup.NotebookIdleTimeoutSeconds)*time.Second, now)
+	}
+	return nil
+}
+
+// aloneInNamespace returns whether submarine is the only Submarine of the
+// namespace that is not being deleted
+func (c *Controller) aloneInNamespace(submarine *v1alpha1.Submarine, namespace string) (bool, error) {
+	submarines, err := c.submarinesLister.Submarines(namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, other := range submarines {
+		if other.Name != submarine.Name && other.DeletionTimestamp == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isSubmarineJob returns whether a job belongs to the Submarine: the jobs
+// labelled with its name, e.g. the jobs of its SubmarineExperiments, and the
+// jobs without that label when it is alone in the namespace, i.e. the jobs
+// created by its server or its users
+func isSubmarineJob(job *unstructured.Unstructured, submarine *v1alpha1.Submarine, alone bool) bool {
+	if owner, labelled := job.GetLabels()[submarineNameLabel]; labelled {
+		return owner == submarine.Name
+	}
+	return alone
+}
+
+// cleanupJobs deletes the finished jobs of the Submarine, as selected by
+// isSubmarineJob. The jobs of the other Submarines follow their own TTLs.
+func (c *Controller) cleanupJobs(submarine *v1alpha1.Submarine, namespace string, cleanup *v1alpha1.SubmarineCleanup, now time.Time) error {
+	if cleanup.SucceededJobTTLSeconds == nil && cleanup.FailedJobTTLSeconds == nil {
+		return nil
+	}
+	alone, err := c.aloneInNamespace(submarine, namespace)
+	if err != nil {
+		return err
+	}
+
+	var finished []completedJob
+	for _, resource := range jobResources() {
+		jobs, err := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(resource)).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			if job.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if !isSubmarineJob(job, submarine, alone) {
+				continue
+			}
+			// Jobs are kept until archiveJobLogs is done with them
+			if _, archived := queuedJobTypes[resource]; archived && submarine.Spec.LogArchive != nil && !logsArchived(job) {
+				continue
+			}
+			phase, since := jobPhase(job)
+			if phase != v1alpha1.ExperimentSucceeded && phase != v1alpha1.ExperimentFailed {
+				continue
+			}
+			if completion := nestedTime(job, "status", "completionTime"); completion != nil {
+				since = completion
+			}
+			finished = append(finished, completedJob{resource: resource, job: job, phase: phase, finished: since.Time})
+		}
+	}
+
+	// The most recently finished jobs are kept
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].finished.After(finished[j].finished)
+	})
+	for i, job := range finished {
+		if i < int(cleanup.KeepJobs) {
+			continue
+		}
+		ttl := cleanup.SucceededJobTTLSeconds
+		if job.phase == v1alpha1.ExperimentFailed {
+			ttl = cleanup.FailedJobTTLSeconds
+		}
+		if ttl == nil || now.Sub(job.finished) < time.Duration(*ttl)*time.Second {
+			continue
+		}
+
+		// The pods are deleted with the job
+		propagation := metav1.DeletePropagationBackground
+		err := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(job.resource)).Namespace(namespace).Delete(context.TODO(), job.job.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		klog.Info("	Delete ", job.job.GetKind(), ": ", job.job.GetName())
+		c.recorder.Eventf(submarine, corev1.EventTypeNormal, JobDeleted, MessageJobDeleted, job.job.GetKind(), job.job.GetName(), job.phase, job.finished.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// cullNotebooks stops the notebooks of the namespace whose last activity, or
+// creation without activity, is older than timeout
+func (c *Controller) cullNotebooks(submarine *v1alpha1.Submarine, namespace string, timeout time.Duration, now time.Time) error {
+	client := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource("notebooks")).Namespace(namespace)
+	notebooks, err := client.List(context.TODO(), metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i := range notebooks.Items {
+		notebook := &notebooks.Items[i]
+		if _, stopped := notebook.GetAnnotations()[notebookStoppedAnnotation]; stopped || notebook.GetDeletionTimestamp() != nil {
+			continue
+		}
+		lastActivity := notebookLastActivity(notebook, nil, now)
+		if now.Sub(lastActivity) < timeout {
+			continue
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{
+					notebookStoppedAnnotation: now.UTC().Format(time.RFC3339),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = client.Patch(context.TODO(), notebook.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		klog.Info("	Stop Notebook: ", notebook.GetName())
+		c.recorder.Eventf(submarine, corev1.EventTypeNormal, NotebookCulled, MessageNotebookCulled, notebook.GetName(), lastActivity.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newCleanupTestJob returns a job that entered phase ago
+func newCleanupTestJob(kind string, name string, phase v1alpha1.ExperimentPhase, ago time.Duration) *unstructured.Unstructured {
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Created", "status": "True"},
+				map[string]interface{}{
+					"type":               string(phase),
+					"status":             "True",
+					"lastTransitionTime": time.Now().Add(-ago).UTC().Format(time.RFC3339),
+				},
+			},
+		},
+	}}
+	job.SetAPIVersion(kubeflowGroupVersion.String())
+	job.SetKind(kind)
+	job.SetName(name)
+	job.SetNamespace("submarine-user-test")
+	return job
+}
+
+func TestCleanupWorkloads(t *testing.T) {
+	submarine := newTestSubmarine()
+	day, hour := int32(24*3600), int32(3600)
+	submarine.Spec.Cleanup = &v1alpha1.SubmarineCleanup{
+		SucceededJobTTLSeconds: &hour,
+		FailedJobTTLSeconds:    &day,
+		KeepJobs:               1,
+	}
+
+	c, _, dynamicClient := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects: []runtime.Object{
+			// Kept as the most recently finished job
+			newCleanupTestJob("TFJob", "newest", v1alpha1.ExperimentSucceeded, 2*time.Hour),
+			// Deleted after the TTL of succeeded jobs
+			newCleanupTestJob("PyTorchJob", "succeeded", v1alpha1.ExperimentSucceeded, 3*time.Hour),
+			// Kept within the TTL of failed jobs
+			newCleanupTestJob("TFJob", "failed", v1alpha1.ExperimentFailed, 4*time.Hour),
+			// Kept while running
+			newCleanupTestJob("TFJob", "running", v1alpha1.ExperimentRunning, 5*time.Hour),
+		},
+	})
+	if err := c.cleanupWorkloads(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+
+	exists := func(resource string, name string) bool {
+		_, err := dynamicClient.Resource(kubeflowGroupVersion.WithResource(resource)).Namespace("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	if exists("pytorchjobs", "succeeded") {
+		t.Error("expected the expired succeeded job to be deleted")
+	}
+	for _, name := range []string{"newest", "failed", "running"} {
+		if !exists("tfjobs", name) {
+			t.Errorf("expected job %s to be kept", name)
+		}
+	}
+
+	// With spec.logArchive, jobs are kept until their logs are archived
+	submarine.Spec.LogArchive = &v1alpha1.SubmarineLogArchive{
+		PersistentVolumeClaim: &v1alpha1.SubmarineVolumeArchive{ClaimName: "logs"},
+	}
+	archived := newCleanupTestJob("TFJob", "archived", v1alpha1.ExperimentSucceeded, 3*time.Hour)
+	archived.SetAnnotations(map[string]string{logArchiveAnnotation: "pvc://logs/archived/"})
+	withoutLogs := newCleanupTestJob("TFJob", "without-logs", v1alpha1.ExperimentSucceeded, 3*time.Hour)
+	withoutLogs.SetAnnotations(map[string]string{logsNotFoundAnnotation: "true"})
+	newest := newCleanupTestJob("TFJob", "newest", v1alpha1.ExperimentSucceeded, 2*time.Hour)
+	newest.SetAnnotations(map[string]string{logArchiveAnnotation: "pvc://logs/newest/"})
+	c, _, dynamicClient = newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects: []runtime.Object{
+			newest,
+			newCleanupTestJob("TFJob", "unarchived", v1alpha1.ExperimentSucceeded, 3*time.Hour),
+			archived,
+			withoutLogs,
+		},
+	})
+	if err := c.cleanupWorkloads(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if !exists("tfjobs", "unarchived") {
+		t.Error("expected the job without archived logs to be kept")
+	}
+	if exists("tfjobs", "archived") {
+		t.Error("expected the expired archived job to be deleted")
+	}
+	if exists("tfjobs", "without-logs") {
+		t.Error("expected the expired job without logs to be deleted")
+	}
+	submarine.Spec.LogArchive = nil
+
+	// With another Submarine in the namespace, only the jobs labelled with
+	// the name of the Submarine are deleted
+	other := newTestSubmarine()
+	other.Name = "other-submarine"
+	labelled := func(name string, submarineName string, ago time.Duration) *unstructured.Unstructured {
+		job := newCleanupTestJob("TFJob", name, v1alpha1.ExperimentSucceeded, ago)
+		job.SetLabels(map[string]string{submarineNameLabel: submarineName})
+		return job
+	}
+	c, _, dynamicClient = newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine, other},
+		objects: []runtime.Object{
+			labelled("newest", submarine.Name, 2*time.Hour),
+			labelled("own", submarine.Name, 3*time.Hour),
+			labelled("other", other.Name, 3*time.Hour),
+			newCleanupTestJob("TFJob", "unlabelled", v1alpha1.ExperimentSucceeded, 3*time.Hour),
+		},
+	})
+	if err := c.cleanupWorkloads(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"own": false, "other": true, "unlabelled": true} {
+		if got := exists("tfjobs", name); got != want {
+			t.Errorf("job %s exists = %t, want %t", name, got, want)
+		}
+	}
+
+	// Without spec.cleanup nothing is listed
+	submarine.Spec.Cleanup = nil
+	c, _, dynamicClient = newTestController(t, testControllerOptions{submarines: []*v1alpha1.Submarine{submarine}})
+	if err := c.cleanupWorkloads(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(dynamicClient.Actions()) != 0 {
+		t.Errorf("expected no actions, got %v", dynamicClient.Actions())
+	}
+}
+
+func newCleanupTestNotebook(name string, annotations map[string]string) *unstructured.Unstructured {
+	notebook := &unstructured.Unstructured{}
+	notebook.SetAPIVersion(kubeflowGroupVersion.String())
+	notebook.SetKind("Notebook")
+	notebook.SetName(name)
+	notebook.SetNamespace("submarine-user-test")
+	notebook.SetCreationTimestamp(metav1.Time{Time: time.Now().Add(-48 * time.Hour)})
+	notebook.SetAnnotations(annotations)
+	return notebook
+}
+
+func TestCullNotebooks(t *testing.T) {
+	submarine := newTestSubmarine()
+	hour := int32(3600)
+	submarine.Spec.Cleanup = &v1alpha1.SubmarineCleanup{NotebookIdleTimeoutSeconds: &hour}
+	stoppedAt := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+
+	c, _, dynamicClient := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects: []runtime.Object{
+			newCleanupTestNotebook("idle", nil),
+			newCleanupTestNotebook("active", map[string]string{
+				notebookLastActivityAnnotation: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+			}),
+			newCleanupTestNotebook("stopped", map[string]string{
+				notebookStoppedAnnotation: stoppedAt,
+			}),
+		},
+	})
+	if err := c.cleanupWorkloads(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := func(name string) string {
+		notebook, err := dynamicClient.Resource(kubeflowGroupVersion.WithResource("notebooks")).Namespace("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return notebook.GetAnnotations()[notebookStoppedAnnotation]
+	}
+	if stopped("idle") == "" {
+		t.Error("expected the idle notebook to be stopped")
+	}
+	if stopped("active") != "" {
+		t.Error("expected the active notebook to keep running")
+	}
+	if got := stopped("stopped"); got != stoppedAt {
+		t.Errorf("expected the stopped notebook to keep its annotation %s, got %s", stoppedAt, got)
+	}
+}
@@ -19,38 +19,62 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	clientset "submarine-cloud-v2/pkg/generated/clientset/versioned"
 	submarinescheme "submarine-cloud-v2/pkg/generated/clientset/versioned/scheme"
 	informers "submarine-cloud-v2/pkg/generated/informers/externalversions/submarine/v1alpha1"
 	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
 	"submarine-cloud-v2/pkg/helm"
+	"submarine-cloud-v2/pkg/logarchive"
 	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
 	"time"
 
+	helmrelease "helm.sh/helm/v3/pkg/release"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
+	autoscalinginformers "k8s.io/client-go/informers/autoscaling/v2beta2"
+	batchinformers "k8s.io/client-go/informers/batch/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	extinformers "k8s.io/client-go/informers/extensions/v1beta1"
+	policyinformers "k8s.io/client-go/informers/policy/v1beta1"
 	rbacinformers "k8s.io/client-go/informers/rbac/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2beta2"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	extlisters "k8s.io/client-go/listers/extensions/v1beta1"
+	policylisters "k8s.io/client-go/listers/policy/v1beta1"
 	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -64,9 +88,69 @@ import (
 
 const controllerAgentName = "submarine-controller"
 
+// The objects of a Submarine are named after the Submarine, so that several
+// Submarines can share a namespace. A Submarine named "submarine" gets the
+// same names as an installation with the Helm chart. A Submarine created
+// before keeps the names it was created with, see reconcileLegacyNames.
+func serverName(submarine *v1alpha1.Submarine) string {
+	return namePrefix(submarine) + "-server"
+}
+
+func databaseName(submarine *v1alpha1.Submarine) string {
+	return namePrefix(submarine) + "-database"
+}
+
+func tensorboardName(submarine *v1alpha1.Submarine) string {
+	return namePrefix(submarine) + "-tensorboard"
+}
+
+func tensorboardServiceName(submarine *v1alpha1.Submarine) string {
+	return tensorboardName(submarine) + "-service"
+}
+
+// serverConfigName is the name of the ConfigMap holding submarine-site.xml
+func serverConfigName(submarine *v1alpha1.Submarine) string {
+	return serverName(submarine) + "-config"
+}
+
+// clusterScopedName returns the name of a cluster-scoped object of a
+// Submarine. Cluster-scoped objects get the namespace as a suffix, so that
+// Submarines of the same name in different namespaces do not collide.
+func clusterScopedName(name string, namespace string) string {
+	return name + "--" + namespace
+}
+
+// submarineLabels returns the recommended labels of the objects of a
+// component, merged with the labels selecting its pods. Selectors are
+// immutable, so they keep using the selector labels only.
+// Reference: https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+func submarineLabels(submarine *v1alpha1.Submarine, component string, selector map[string]string) map[string]string {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "submarine",
+		"app.kubernetes.io/instance":   submarine.Name,
+		"app.kubernetes.io/component":  component,
+		"app.kubernetes.io/part-of":    "submarine",
+		"app.kubernetes.io/managed-by": "submarine-operator",
+	}
+	if submarine.Spec.Version != "" {
+		labels["app.kubernetes.io/version"] = submarine.Spec.Version
+	}
+	for key, value := range selector {
+		labels[key] = value
+	}
+	return labels
+}
+
 const (
-	serverName   = "submarine-server"
-	databaseName = "submarine-database"
+	// serverConfigFile is the key of submarine-site.xml in the ConfigMap
+	serverConfigFile = "submarine-site.xml"
+	// serverConfigDir is where submarine-server reads its configuration from
+	serverConfigDir = "/opt/submarine-current/conf"
+	// databaseRootPassword is the root password of submarine-database
+	databaseRootPassword = "password"
+	// configHashAnnotation records the hash of the configuration a pod
+	// template was rendered with, so that a change rolls the pods
+	configHashAnnotation = "submarine.k8s.io/config-hash"
 )
 
 const (
@@ -82,6 +166,38 @@ const (
 	// MessageResourceSynced is the message used for an Event fired when a
 	// Submarine is synced successfully
 	MessageResourceSynced = "Submarine synced successfully"
+
+	// SuccessAdopted is used as part of the Event 'reason' when a Submarine
+	// takes over an existing resource
+	SuccessAdopted = "Adopted"
+	// MessageResourceAdopted is the message used for an Event fired when a
+	// resource without a controller is adopted by a Submarine
+	MessageResourceAdopted = "Resource %q adopted by Submarine"
+
+	// OperatorFound is used as part of the Event 'reason' when the subchart
+	// of an operator is skipped because the operator already runs
+	OperatorFound = "OperatorFound"
+	// MessageOperatorFound is the message used for an Event fired when the
+	// subchart of an operator is skipped
+	MessageOperatorFound = "Skipped installing %s, the operator %s already runs"
+	// ChartFailed is used as part of the Event 'reason' when the release of
+	// a subchart fails
+	ChartFailed = "ChartFailed"
+	// MessageChartFailed is the message used for an Event fired when the
+	// release of a subchart fails
+	MessageChartFailed = "Release %s failed: %v"
+	// ChartRolledBack is used as part of the Event 'reason' when a failed
+	// release is rolled back
+	ChartRolledBack = "ChartRolledBack"
+	// MessageChartRolledBack is the message used for an Event fired when a
+	// failed release is rolled back
+	MessageChartRolledBack = "Release %s is %s, rolling back to revision %d"
+	// ChartReinstalled is used as part of the Event 'reason' when a release
+	// that was never deployed is reinstalled
+	ChartReinstalled = "ChartReinstalled"
+	// MessageChartReinstalled is the message used for an Event fired when a
+	// release that was never deployed is reinstalled
+	MessageChartReinstalled = "Release %s is %s, reinstalling"
 )
 
 // Controller is the controller implementation for Submarine resources
@@ -91,6 +207,9 @@ type Controller struct {
 	// sampleclientset is a clientset for our own API group
 	submarineclientset clientset.Interface
 	traefikclientset   traefik.Interface
+	// dynamicclientset manages the ServiceMonitors and PodMonitors of the
+	// Prometheus Operator, whose CRDs may not be installed
+	dynamicclientset dynamic.Interface
 
 	submarinesLister listers.SubmarineLister
 	submarinesSynced cache.InformerSynced
@@ -105,6 +224,12 @@ type Controller struct {
 	ingressrouteLister          traefiklisters.IngressRouteLister
 	clusterroleLister           rbaclisters.ClusterRoleLister
 	clusterrolebindingLister    rbaclisters.ClusterRoleBindingLister
+	hpaLister                   autoscalinglisters.HorizontalPodAutoscalerLister
+	pdbLister                   policylisters.PodDisruptionBudgetLister
+	configmapLister             corelisters.ConfigMapLister
+	jobLister                   batchlisters.JobLister
+	daemonsetLister             appslisters.DaemonSetLister
+	podLister                   corelisters.PodLister
 	// workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
 	// means we can ensure we only process a fixed amount of resources at a
@@ -115,10 +240,40 @@ type Controller struct {
 	// Kubernetes API.
 	recorder record.EventRecorder
 
-	// TODO: Need to be modified to implement multi-tenant
-	// Store charts
-	charts    []helm.HelmUninstallInfo
-	incluster bool
+	// Store the charts installed in each namespace. They are shared by the
+	// Submarines of the namespace.
+	charts map[string][]helm.HelmUninstallInfo
+	// chartOperations are the Helm operations on the releases of the
+	// subcharts, by namespace/name. They run in the background, since they
+	// wait for the release to be ready.
+	chartOperations map[string]*chartOperation
+	// chartsLock guards charts and chartOperations
+	chartsLock sync.Mutex
+	// chartSources overrides the embedded subcharts
+	chartSources chartOverrides
+	// releaseOptions are the options of the Helm operations on subcharts
+	releaseOptions helm.ReleaseOptions
+	incluster      bool
+	// environmentImages returns the images of the environments of the
+	// server of a Submarine
+	environmentImages func(submarine *v1alpha1.Submarine) ([]string, error)
+	// environmentImagesEntries are the images of the environments of each
+	// Submarine, by namespace/name
+	environmentImagesEntries map[string]*environmentImagesEntry
+	// environmentImagesLock guards environmentImagesEntries
+	environmentImagesLock sync.Mutex
+	// logStore returns the store of the logs archived in a namespace
+	logStore func(submarine *v1alpha1.Submarine, namespace string) (logarchive.Store, error)
+	// jobInformerFactory watches the jobs of the workload operators, so
+	// that a job finishing archives its logs and admits the queued jobs
+	jobInformerFactory dynamicinformer.DynamicSharedInformerFactory
+	// watchedJobs are the resources of the jobs watched by
+	// jobInformerFactory
+	watchedJobs map[string]bool
+	// watchedJobsLock guards watchedJobs
+	watchedJobsLock sync.Mutex
+	// stopCh stops the informers of jobInformerFactory
+	stopCh <-chan struct{}
 }
 
 const (
@@ -135,9 +290,13 @@ type WorkQueueItem struct {
 // NewController returns a new sample controller
 func NewController(
 	incluster bool,
+	chartSources chartOverrides,
+	helmTimeout time.Duration,
 	kubeclientset kubernetes.Interface,
 	submarineclientset clientset.Interface,
 	traefikclientset traefik.Interface,
+	dynamicclientset dynamic.Interface,
+	restConfig *rest.Config,
 	namespaceInformer coreinformers.NamespaceInformer,
 	deploymentInformer appsinformers.DeploymentInformer,
 	serviceInformer coreinformers.ServiceInformer,
@@ -148,6 +307,12 @@ func NewController(
 	ingressrouteInformer traefikinformers.IngressRouteInformer,
 	clusterroleInformer rbacinformers.ClusterRoleInformer,
 	clusterrolebindingInformer rbacinformers.ClusterRoleBindingInformer,
+	hpaInformer autoscalinginformers.HorizontalPodAutoscalerInformer,
+	pdbInformer policyinformers.PodDisruptionBudgetInformer,
+	configmapInformer coreinformers.ConfigMapInformer,
+	jobInformer batchinformers.JobInformer,
+	daemonsetInformer appsinformers.DaemonSetInformer,
+	podInformer coreinformers.PodInformer,
 	submarineInformer informers.SubmarineInformer) *Controller {
 
 	// Add Submarine types to the default Kubernetes Scheme so Events can be
@@ -164,6 +329,7 @@ func NewController(
 		kubeclientset:               kubeclientset,
 		submarineclientset:          submarineclientset,
 		traefikclientset:            traefikclientset,
+		dynamicclientset:            dynamicclientset,
 		submarinesLister:            submarineInformer.Lister(),
 		submarinesSynced:            submarineInformer.Informer().HasSynced,
 		namespaceLister:             namespaceInformer.Lister(),
@@ -176,10 +342,25 @@ func NewController(
 		ingressrouteLister:          ingressrouteInformer.Lister(),
 		clusterroleLister:           clusterroleInformer.Lister(),
 		clusterrolebindingLister:    clusterrolebindingInformer.Lister(),
+		hpaLister:                   hpaInformer.Lister(),
+		pdbLister:                   pdbInformer.Lister(),
+		configmapLister:             configmapInformer.Lister(),
+		jobLister:                   jobInformer.Lister(),
+		daemonsetLister:             daemonsetInformer.Lister(),
+		podLister:                   podInformer.Lister(),
 		workqueue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Submarines"),
 		recorder:                    recorder,
+		charts:                      map[string][]helm.HelmUninstallInfo{},
+		chartOperations:             map[string]*chartOperation{},
 		incluster:                   incluster,
+		chartSources:                chartSources,
+		releaseOptions:              helm.ReleaseOptions{Wait: true, Atomic: true, Timeout: helmTimeout},
+		environmentImages:           newEnvironmentImagesFunc(kubeclientset),
+		environmentImagesEntries:    map[string]*environmentImagesEntry{},
+		jobInformerFactory:          dynamicinformer.NewDynamicSharedInformerFactory(dynamicclientset, time.Second*30),
+		watchedJobs:                 map[string]bool{},
 	}
+	controller.logStore = controller.newLogStoreFunc(restConfig)
 
 	// Setting up event handler for Submarine
 	klog.Info("Setting up event handlers")
@@ -316,16 +497,114 @@ func NewController(
 		},
 		DeleteFunc: controller.handleObject,
 	})
+	hpaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			newHPA := new.(*autoscalingv2beta2.HorizontalPodAutoscaler)
+			oldHPA := old.(*autoscalingv2beta2.HorizontalPodAutoscaler)
+			if newHPA.ResourceVersion == oldHPA.ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+	pdbInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			newPDB := new.(*policyv1beta1.PodDisruptionBudget)
+			oldPDB := old.(*policyv1beta1.PodDisruptionBudget)
+			if newPDB.ResourceVersion == oldPDB.ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+	configmapInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			newConfigMap := new.(*corev1.ConfigMap)
+			oldConfigMap := old.(*corev1.ConfigMap)
+			if newConfigMap.ResourceVersion == oldConfigMap.ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			newJob := new.(*batchv1.Job)
+			oldJob := old.(*batchv1.Job)
+			if newJob.ResourceVersion == oldJob.ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+	daemonsetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			newDaemonSet := new.(*appsv1.DaemonSet)
+			oldDaemonSet := old.(*appsv1.DaemonSet)
+			if newDaemonSet.ResourceVersion == oldDaemonSet.ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
 
 	return controller
 }
 
+// claimObject checks that obj is controlled by the Submarine. When the
+// adoption policy allows it, an object without a controller, e.g. a leftover
+// of an installation with the Helm chart, is adopted by patching in the
+// controller reference and the labels of the component.
+func (c *Controller) claimObject(submarine *v1alpha1.Submarine, obj metav1.Object, component string, patch func([]byte) error) error {
+	if metav1.IsControlledBy(obj, submarine) {
+		return nil
+	}
+	if metav1.GetControllerOf(obj) != nil || submarine.Spec.AdoptionPolicy != v1alpha1.AdoptionPolicyIfUnowned {
+		msg := fmt.Sprintf(MessageResourceExists, obj.GetName())
+		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
+		return fmt.Errorf(msg)
+	}
+
+	// A merge patch replaces lists, so keep the other owners. The UID makes
+	// the patch fail if the object was replaced in the meantime.
+	ownerReferences := append([]metav1.OwnerReference{}, obj.GetOwnerReferences()...)
+	ownerReferences = append(ownerReferences, *metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")))
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"uid":             obj.GetUID(),
+			"ownerReferences": ownerReferences,
+			"labels":          submarineLabels(submarine, component, nil),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := patch(data); err != nil {
+		return err
+	}
+	klog.Info("	Adopt: ", obj.GetName())
+	c.recorder.Event(submarine, corev1.EventTypeNormal, SuccessAdopted, fmt.Sprintf(MessageResourceAdopted, obj.GetName()))
+	return nil
+}
+
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
 
 	// Start the informer factories to begin populating the informer caches
 	klog.Info("Starting Submarine controller")
+	// The informers of the jobs are started by watchJobs
+	c.stopCh = stopCh
 
 	// Wait for the caches to be synced before starting workers
 	klog.Info("Waiting for informer caches to sync")
@@ -397,126 +676,201 @@ func (c *Controller) processNextWorkItem() bool {
 }
 
 func newSubmarineServerDeployment(submarine *v1alpha1.Submarine) *appsv1.Deployment {
-	serverImage := submarine.Spec.Server.Image
-	serverReplicas := *submarine.Spec.Server.Replicas
-	if serverImage == "" {
-		serverImage = "apache/submarine:server-" + submarine.Spec.Version
+	serverReplicas := serverSpecReplicas(submarine)
+	// Start from the lower bound of the autoscaler, the HPA takes over from there
+	if serverAutoscalingEnabled(submarine) && submarine.Spec.Server.Autoscaling.MinReplicas != nil {
+		serverReplicas = *submarine.Spec.Server.Autoscaling.MinReplicas
+	}
+	serverLabels := map[string]string{
+		"run": serverName(submarine),
+	}
+	topologySpreadConstraints := submarine.Spec.Server.TopologySpreadConstraints
+	if len(topologySpreadConstraints) == 0 && serverMaxReplicas(submarine) > 1 {
+		topologySpreadConstraints = defaultTopologySpreadConstraints(serverLabels)
+	}
+
+	// Mount the operator-owned submarine-site.xml over the one of the image
+	var annotations map[string]string
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if serverConfigEnabled(submarine) {
+		annotations = map[string]string{
+			configHashAnnotation: hashConfig(renderSubmarineSiteXML(submarine.Spec.Server.Config)),
+		}
+		volumes = []corev1.Volume{
+			{
+				Name: "config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: serverConfigName(submarine),
+						},
+					},
+				},
+			},
+		}
+		volumeMounts = []corev1.VolumeMount{
+			{
+				Name:      "config",
+				MountPath: serverConfigDir + "/" + serverConfigFile,
+				SubPath:   serverConfigFile,
+			},
+		}
+	}
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "SUBMARINE_SERVER_PORT",
+			Value: "8080",
+		},
+		{
+			Name:  "SUBMARINE_SERVER_PORT_8080_TCP",
+			Value: "8080",
+		},
+		{
+			Name:  "SUBMARINE_SERVER_DNS_NAME",
+			Value: serverName(submarine) + "." + submarine.Namespace,
+		},
+		{
+			Name:  "K8S_APISERVER_URL",
+			Value: "kubernetes.default.svc",
+		},
+		{
+			Name:  "ENV_NAMESPACE",
+			Value: submarine.Namespace,
+		},
+		// The image points at the service of the Helm chart, which is only
+		// right for a Submarine named "submarine"
+		{
+			Name:  "JDBC_URL",
+			Value: databaseJDBCURL(submarine, "submarine"),
+		},
+		{
+			Name:  "METASTORE_JDBC_URL",
+			Value: databaseJDBCURL(submarine, "metastore"),
+		},
+	}
+	containers := []corev1.Container{
+		{
+			Name:  serverName(submarine),
+			Image: serverImage(submarine),
+			Env:   env,
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: 8080,
+				},
+			},
+			VolumeMounts:    volumeMounts,
+			ImagePullPolicy: "IfNotPresent",
+		},
+	}
+
+	// The JMX exporter sidecar reads the metrics of the server
+	if monitoringEnabled(submarine) {
+		containers[0].Env = append(containers[0].Env, corev1.EnvVar{
+			Name:  "SUBMARINE_SERVER_JAVA_OPTS",
+			Value: serverJavaOpts(),
+		})
+		containers = append(containers, newServerExporterContainer(submarine))
+		volumes = append(volumes, newServerMetricsVolume(submarine))
 	}
 
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: serverName,
+			Name:   serverName(submarine),
+			Labels: submarineLabels(submarine, "server", nil),
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"run": serverName,
-				},
+				MatchLabels: serverLabels,
 			},
 			Replicas: &serverReplicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"run": serverName,
-					},
+					Labels:      submarineLabels(submarine, "server", serverLabels),
+					Annotations: annotations,
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: serverName,
-					Containers: []corev1.Container{
-						{
-							Name:  serverName,
-							Image: serverImage,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "SUBMARINE_SERVER_PORT",
-									Value: "8080",
-								},
-								{
-									Name:  "SUBMARINE_SERVER_PORT_8080_TCP",
-									Value: "8080",
-								},
-								{
-									Name:  "SUBMARINE_SERVER_DNS_NAME",
-									Value: serverName + "." + submarine.Namespace,
-								},
-								{
-									Name:  "K8S_APISERVER_URL",
-									Value: "kubernetes.default.svc",
-								},
-								{
-									Name:  "ENV_NAMESPACE",
-									Value: submarine.Namespace,
-								},
-							},
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: 8080,
-								},
-							},
-							ImagePullPolicy: "IfNotPresent",
-						},
-					},
+					ServiceAccountName:        serverName(submarine),
+					ImagePullSecrets:          submarine.Spec.ImagePullSecrets,
+					TopologySpreadConstraints: withDefaultLabelSelector(topologySpreadConstraints, serverLabels),
+					Volumes:                   volumes,
+					Containers:                containers,
 				},
 			},
 		},
 	}
 }
 
+// databaseJDBCURL returns the URL of a schema of the database of a Submarine
+func databaseJDBCURL(submarine *v1alpha1.Submarine, schema string) string {
+	return "jdbc:mysql://" + databaseName(submarine) + ":3306/" + schema +
+		"?useUnicode=true&characterEncoding=UTF-8&autoReconnect=true&failOverReadOnly=false&zeroDateTimeBehavior=convertToNull&useSSL=false"
+}
+
 func newSubmarineDatabaseDeployment(submarine *v1alpha1.Submarine, pvcName string) *appsv1.Deployment {
-	databaseImage := submarine.Spec.Database.Image
-	if databaseImage == "" {
-		databaseImage = "apache/submarine:database-" + submarine.Spec.Version
+	databaseLabels := map[string]string{
+		"app": databaseName(submarine),
+	}
+	topologySpreadConstraints := submarine.Spec.Database.TopologySpreadConstraints
+	if len(topologySpreadConstraints) == 0 && databaseReplicas(submarine) > 1 {
+		topologySpreadConstraints = defaultTopologySpreadConstraints(databaseLabels)
+	}
+
+	containers := []corev1.Container{
+		{
+			Name:            databaseName(submarine),
+			Image:           databaseImage(submarine),
+			ImagePullPolicy: "IfNotPresent",
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: 3306,
+				},
+			},
+			Env: []corev1.EnvVar{
+				{
+					Name:  "MYSQL_ROOT_PASSWORD",
+					Value: databaseRootPassword,
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					MountPath: "/var/lib/mysql",
+					Name:      "volume",
+					SubPath:   databaseName(submarine),
+				},
+			},
+		},
+	}
+	// The MySQL exporter sidecar reads the metrics of the database
+	if monitoringEnabled(submarine) {
+		containers = append(containers, newDatabaseExporterContainer(submarine))
 	}
 
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: databaseName,
+			Name:   databaseName(submarine),
+			Labels: submarineLabels(submarine, "database", nil),
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": databaseName,
-				},
+				MatchLabels: databaseLabels,
 			},
 			Replicas: submarine.Spec.Database.Replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": databaseName,
-					},
+					Labels: submarineLabels(submarine, "database", databaseLabels),
 				},
 				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:            databaseName,
-							Image:           databaseImage,
-							ImagePullPolicy: "IfNotPresent",
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: 3306,
-								},
-							},
-							Env: []corev1.EnvVar{
-								{
-									Name:  "MYSQL_ROOT_PASSWORD",
-									Value: "password",
-								},
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{
-									MountPath: "/var/lib/mysql",
-									Name:      "volume",
-									SubPath:   databaseName,
-								},
-							},
-						},
-					},
+					TopologySpreadConstraints: withDefaultLabelSelector(topologySpreadConstraints, databaseLabels),
+					ImagePullSecrets:          submarine.Spec.ImagePullSecrets,
+					Containers:                containers,
 					Volumes: []corev1.Volume{
 						{
 							Name: "volume",
@@ -533,25 +887,162 @@ func newSubmarineDatabaseDeployment(submarine *v1alpha1.Submarine, pvcName strin
 	}
 }
 
+// renderSubmarineSiteXML renders the configuration of submarine-server into
+// the content of submarine-site.xml.
+func renderSubmarineSiteXML(config *v1alpha1.SubmarineServerConfig) string {
+	if config.SiteXML != "" {
+		return config.SiteXML
+	}
+
+	// Sort the properties to render the same file for the same spec
+	names := make([]string, 0, len(config.Properties))
+	for name := range config.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString("<configuration>\n")
+	for _, name := range names {
+		b.WriteString("  <property>\n    <name>")
+		xml.EscapeText(&b, []byte(name))
+		b.WriteString("</name>\n    <value>")
+		xml.EscapeText(&b, []byte(config.Properties[name]))
+		b.WriteString("</value>\n  </property>\n")
+	}
+	b.WriteString("</configuration>\n")
+	return b.String()
+}
+
+func hashConfig(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSubmarineServerConfigMap(submarine *v1alpha1.Submarine) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serverConfigName(submarine),
+			Labels: submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Data: map[string]string{
+			serverConfigFile: renderSubmarineSiteXML(submarine.Spec.Server.Config),
+		},
+	}
+}
+
+// deploymentNeedsUpdate reports whether the fields of a deployment that are
+// managed by the operator differ between the desired and the actual state.
+func deploymentNeedsUpdate(desired *appsv1.Deployment, actual *appsv1.Deployment) bool {
+	if desired.Spec.Replicas != nil && *desired.Spec.Replicas != *actual.Spec.Replicas {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(desired.Spec.Template.Spec.TopologySpreadConstraints, actual.Spec.Template.Spec.TopologySpreadConstraints) {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(desired.Spec.Template.Spec.ImagePullSecrets, actual.Spec.Template.Spec.ImagePullSecrets) {
+		return true
+	}
+	if len(desired.Spec.Template.Spec.Containers) != len(actual.Spec.Template.Spec.Containers) {
+		return true
+	}
+	for i := range desired.Spec.Template.Spec.Containers {
+		if i >= len(actual.Spec.Template.Spec.Containers) || desired.Spec.Template.Spec.Containers[i].Image != actual.Spec.Template.Spec.Containers[i].Image {
+			return true
+		}
+	}
+	return desired.Spec.Template.Annotations[configHashAnnotation] != actual.Spec.Template.Annotations[configHashAnnotation]
+}
+
+// defaultTopologySpreadConstraints spreads the pods selected by labels evenly
+// across nodes and zones. The constraints are soft, so that small clusters
+// can still schedule every replica.
+func defaultTopologySpreadConstraints(labels map[string]string) []corev1.TopologySpreadConstraint {
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelHostname,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+		},
+		{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+}
+
+// withDefaultLabelSelector returns a copy of constraints where every
+// constraint without a label selector selects the pods of the component.
+func withDefaultLabelSelector(constraints []corev1.TopologySpreadConstraint, labels map[string]string) []corev1.TopologySpreadConstraint {
+	var result []corev1.TopologySpreadConstraint
+	for _, constraint := range constraints {
+		constraint := *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: labels}
+		}
+		result = append(result, constraint)
+	}
+	return result
+}
+
+func newSubmarineServerServiceAccount(submarine *v1alpha1.Submarine) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serverName(submarine),
+			Labels: submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+	}
+}
+
+func newSubmarineServerService(submarine *v1alpha1.Submarine) *corev1.Service {
+	ports := []corev1.ServicePort{
+		{
+			Port:       8080,
+			TargetPort: intstr.FromInt(8080),
+			Protocol:   "TCP",
+		},
+	}
+	if monitoringEnabled(submarine) {
+		ports = append(ports, newMetricsServicePort(serverMetricsPort))
+	}
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serverName(submarine),
+			Labels: submarineLabels(submarine, "server", map[string]string{"run": serverName(submarine)}),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: ports,
+			Selector: map[string]string{
+				"run": serverName(submarine),
+			},
+		},
+	}
+}
+
 // newSubmarineServer is a function to create submarine-server.
 // Reference: https://github.com/apache/submarine/blob/master/helm-charts/submarine/templates/submarine-server.yaml
 func (c *Controller) newSubmarineServer(submarine *v1alpha1.Submarine, namespace string) (*appsv1.Deployment, error) {
 	klog.Info("[newSubmarineServer]")
 
 	// Step1: Create ServiceAccount
-	serviceaccount, serviceaccount_err := c.serviceaccountLister.ServiceAccounts(namespace).Get(serverName)
+	serviceaccount, serviceaccount_err := c.serviceaccountLister.ServiceAccounts(namespace).Get(serverName(submarine))
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(serviceaccount_err) {
-		serviceaccount, serviceaccount_err = c.kubeclientset.CoreV1().ServiceAccounts(namespace).Create(context.TODO(),
-			&corev1.ServiceAccount{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: serverName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-			},
-			metav1.CreateOptions{})
+		serviceaccount, serviceaccount_err = c.kubeclientset.CoreV1().ServiceAccounts(namespace).Create(context.TODO(), newSubmarineServerServiceAccount(submarine), metav1.CreateOptions{})
 		klog.Info("	Create ServiceAccount: ", serviceaccount.Name)
 	}
 
@@ -562,41 +1053,18 @@ func (c *Controller) newSubmarineServer(submarine *v1alpha1.Submarine, namespace
 		return nil, serviceaccount_err
 	}
 
-	if !metav1.IsControlledBy(serviceaccount, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, serviceaccount.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
+	if err := c.claimObject(submarine, serviceaccount, "server", func(patch []byte) error {
+		serviceaccount, serviceaccount_err = c.kubeclientset.CoreV1().ServiceAccounts(namespace).Patch(context.TODO(), serviceaccount.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return serviceaccount_err
+	}); err != nil {
+		return nil, err
 	}
 
 	// Step2: Create Service
-	service, service_err := c.serviceLister.Services(namespace).Get(serverName)
+	service, service_err := c.serviceLister.Services(namespace).Get(serverName(submarine))
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(service_err) {
-		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(),
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: serverName,
-					Labels: map[string]string{
-						"run": serverName,
-					},
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: corev1.ServiceSpec{
-					Ports: []corev1.ServicePort{
-						{
-							Port:       8080,
-							TargetPort: intstr.FromInt(8080),
-							Protocol:   "TCP",
-						},
-					},
-					Selector: map[string]string{
-						"run": serverName,
-					},
-				},
-			},
-			metav1.CreateOptions{})
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(), newSubmarineServerService(submarine), metav1.CreateOptions{})
 		klog.Info("	Create Service: ", service.Name)
 	}
 
@@ -607,14 +1075,30 @@ func (c *Controller) newSubmarineServer(submarine *v1alpha1.Submarine, namespace
 		return nil, service_err
 	}
 
-	if !metav1.IsControlledBy(service, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, service.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
+	if err := c.claimObject(submarine, service, "server", func(patch []byte) error {
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Patch(context.TODO(), service.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return service_err
+	}); err != nil {
+		return nil, err
 	}
 
-	// Step3: Create Deployment
-	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(serverName)
+	// Update the ports of the Service, e.g. the metrics port
+	if err := c.updateServicePorts(newSubmarineServerService(submarine), service); err != nil {
+		return nil, err
+	}
+
+	// Step3: Create ConfigMaps
+	err := c.newSubmarineServerConfig(submarine, namespace)
+	if err != nil {
+		return nil, err
+	}
+	err = c.newSubmarineServerMetricsConfig(submarine, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step4: Create Deployment
+	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(serverName(submarine))
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(deployment_err) {
 		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Create(context.TODO(), newSubmarineServerDeployment(submarine), metav1.CreateOptions{})
@@ -628,16 +1112,23 @@ func (c *Controller) newSubmarineServer(submarine *v1alpha1.Submarine, namespace
 		return nil, deployment_err
 	}
 
-	if !metav1.IsControlledBy(deployment, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, deployment.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
+	if err := c.claimObject(submarine, deployment, "server", func(patch []byte) error {
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Patch(context.TODO(), deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return deployment_err
+	}); err != nil {
+		return nil, err
 	}
 
-	// Update the replicas of the server deployment if it is not equal to spec
-	if submarine.Spec.Server.Replicas != nil && *submarine.Spec.Server.Replicas != *deployment.Spec.Replicas {
-		klog.V(4).Infof("Submarine %s server spec replicas: %d, actual replicas: %d", submarine.Name, *submarine.Spec.Server.Replicas, *deployment.Spec.Replicas)
-		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(submarine.Namespace).Update(context.TODO(), newSubmarineServerDeployment(submarine), metav1.UpdateOptions{})
+	// Update the server deployment if it is not equal to spec. A new config
+	// hash rolls the server pods. When autoscaling is enabled, the replicas
+	// are owned by the HPA and must not be reset on every reconcile.
+	desired := newSubmarineServerDeployment(submarine)
+	if serverAutoscalingEnabled(submarine) {
+		desired.Spec.Replicas = deployment.Spec.Replicas
+	}
+	if deploymentNeedsUpdate(desired, deployment) {
+		klog.V(4).Infof("Submarine %s server spec replicas: %d, actual replicas: %d", submarine.Name, *desired.Spec.Replicas, *deployment.Spec.Replicas)
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(submarine.Namespace).Update(context.TODO(), desired, metav1.UpdateOptions{})
 	}
 
 	if deployment_err != nil {
@@ -647,47 +1138,348 @@ func (c *Controller) newSubmarineServer(submarine *v1alpha1.Submarine, namespace
 	return deployment, nil
 }
 
-// newIngress is a function to create Ingress.
-// Reference: https://github.com/apache/submarine/blob/master/helm-charts/submarine/templates/submarine-ingress.yaml
-func (c *Controller) newIngress(submarine *v1alpha1.Submarine, namespace string) error {
-	klog.Info("[newIngress]")
-	serverName := "submarine-server"
+// newSubmarineServerConfig is a function to create, update or remove the
+// ConfigMap holding submarine-site.xml according to spec.server.config.
+func (c *Controller) newSubmarineServerConfig(submarine *v1alpha1.Submarine, namespace string) error {
+	configmap, configmap_err := c.configmapLister.ConfigMaps(namespace).Get(serverConfigName(submarine))
 
-	// Step1: Create ServiceAccount
-	ingress, ingress_err := c.ingressLister.Ingresses(namespace).Get(serverName + "-ingress")
-	// If the resource doesn't exist, we'll create it
-	if errors.IsNotFound(ingress_err) {
-		ingress, ingress_err = c.kubeclientset.ExtensionsV1beta1().Ingresses(namespace).Create(context.TODO(),
-			&extensionsv1beta1.Ingress{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      serverName + "-ingress",
-					Namespace: namespace,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: extensionsv1beta1.IngressSpec{
-					Rules: []extensionsv1beta1.IngressRule{
-						{
-							IngressRuleValue: extensionsv1beta1.IngressRuleValue{
-								HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
-									Paths: []extensionsv1beta1.HTTPIngressPath{
-										{
-											Backend: extensionsv1beta1.IngressBackend{
-												ServiceName: serverName,
-												ServicePort: intstr.FromInt(8080),
-											},
-											Path: "/",
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			metav1.CreateOptions{})
-		klog.Info("	Create Ingress: ", ingress.Name)
+	// The server falls back to the configuration of its image
+	if !serverConfigEnabled(submarine) {
+		if errors.IsNotFound(configmap_err) {
+			return nil
+		}
+		if configmap_err != nil {
+			return configmap_err
+		}
+		if !metav1.IsControlledBy(configmap, submarine) {
+			return nil
+		}
+		klog.Info("	Delete ConfigMap: ", configmap.Name)
+		return c.kubeclientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), configmap.Name, metav1.DeleteOptions{})
+	}
+
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(configmap_err) {
+		configmap, configmap_err = c.kubeclientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), newSubmarineServerConfigMap(submarine), metav1.CreateOptions{})
+		if configmap_err != nil {
+			klog.Info(configmap_err)
+		}
+		klog.Info("	Create ConfigMap: ", configmap.Name)
+	}
+
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if configmap_err != nil {
+		return configmap_err
+	}
+
+	if err := c.claimObject(submarine, configmap, "server", func(patch []byte) error {
+		configmap, configmap_err = c.kubeclientset.CoreV1().ConfigMaps(namespace).Patch(context.TODO(), configmap.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return configmap_err
+	}); err != nil {
+		return err
+	}
+
+	// Update the ConfigMap if it is not equal to spec. The server pods are
+	// rolled by the new config hash of the deployment.
+	desired := newSubmarineServerConfigMap(submarine)
+	if !equality.Semantic.DeepEqual(configmap.Data, desired.Data) {
+		configmapCopy := configmap.DeepCopy()
+		configmapCopy.Data = desired.Data
+		_, configmap_err = c.kubeclientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), configmapCopy, metav1.UpdateOptions{})
+	}
+
+	return configmap_err
+}
+
+// serverConfigEnabled returns whether the operator renders the
+// submarine-site.xml of the server into a ConfigMap
+func serverConfigEnabled(submarine *v1alpha1.Submarine) bool {
+	return submarine.Spec.Server.Config != nil
+}
+
+func serverAutoscalingEnabled(submarine *v1alpha1.Submarine) bool {
+	autoscaling := submarine.Spec.Server.Autoscaling
+	return autoscaling != nil && autoscaling.Enabled != nil && *autoscaling.Enabled
+}
+
+// serverSpecReplicas returns spec.server.replicas, which defaults to a
+// single replica as for Deployments
+func serverSpecReplicas(submarine *v1alpha1.Submarine) int32 {
+	if submarine.Spec.Server.Replicas == nil {
+		return 1
+	}
+	return *submarine.Spec.Server.Replicas
+}
+
+// serverMinReplicas returns the number of server replicas that is always
+// running, taking the autoscaler into account.
+func serverMinReplicas(submarine *v1alpha1.Submarine) int32 {
+	if serverAutoscalingEnabled(submarine) {
+		if submarine.Spec.Server.Autoscaling.MinReplicas != nil {
+			return *submarine.Spec.Server.Autoscaling.MinReplicas
+		}
+		return 1
+	}
+	return serverSpecReplicas(submarine)
+}
+
+// serverMaxReplicas returns the number of server replicas that may be
+// running, taking the autoscaler into account.
+func serverMaxReplicas(submarine *v1alpha1.Submarine) int32 {
+	if serverAutoscalingEnabled(submarine) {
+		return submarine.Spec.Server.Autoscaling.MaxReplicas
+	}
+	return serverSpecReplicas(submarine)
+}
+
+func newSubmarineServerHPA(submarine *v1alpha1.Submarine) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	autoscaling := submarine.Spec.Server.Autoscaling
+	minReplicas := int32(1)
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+
+	var metrics []autoscalingv2beta2.MetricSpec
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, newResourceUtilizationMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, newResourceUtilizationMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+	// Spell out the API server default so that the desired and the actual
+	// spec can be compared
+	if len(metrics) == 0 {
+		metrics = append(metrics, newResourceUtilizationMetric(corev1.ResourceCPU, 80))
+	}
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serverName(submarine),
+			Labels: submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       serverName(submarine),
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+func newResourceUtilizationMetric(name corev1.ResourceName, averageUtilization int32) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: &averageUtilization,
+			},
+		},
+	}
+}
+
+// newSubmarineServerAutoscaler is a function to create, update or remove the
+// HorizontalPodAutoscaler of submarine-server according to spec.server.autoscaling.
+func (c *Controller) newSubmarineServerAutoscaler(submarine *v1alpha1.Submarine, namespace string) error {
+	klog.Info("[newSubmarineServerAutoscaler]")
+
+	hpa, hpa_err := c.hpaLister.HorizontalPodAutoscalers(namespace).Get(serverName(submarine))
+
+	// Autoscaling is disabled: remove the HPA if we created one before
+	if !serverAutoscalingEnabled(submarine) {
+		if errors.IsNotFound(hpa_err) {
+			return nil
+		}
+		if hpa_err != nil {
+			return hpa_err
+		}
+		if !metav1.IsControlledBy(hpa, submarine) {
+			return nil
+		}
+		klog.Info("	Delete HorizontalPodAutoscaler: ", hpa.Name)
+		return c.kubeclientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Delete(context.TODO(), hpa.Name, metav1.DeleteOptions{})
+	}
+
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(hpa_err) {
+		hpa, hpa_err = c.kubeclientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Create(context.TODO(), newSubmarineServerHPA(submarine), metav1.CreateOptions{})
+		if hpa_err != nil {
+			klog.Info(hpa_err)
+		}
+		klog.Info("	Create HorizontalPodAutoscaler: ", hpa.Name)
+	}
+
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if hpa_err != nil {
+		return hpa_err
+	}
+
+	if err := c.claimObject(submarine, hpa, "server", func(patch []byte) error {
+		hpa, hpa_err = c.kubeclientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Patch(context.TODO(), hpa.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return hpa_err
+	}); err != nil {
+		return err
+	}
+
+	// Update the HPA if the bounds or the targets are not equal to spec
+	desired := newSubmarineServerHPA(submarine)
+	if !equality.Semantic.DeepEqual(hpa.Spec.MinReplicas, desired.Spec.MinReplicas) ||
+		hpa.Spec.MaxReplicas != desired.Spec.MaxReplicas ||
+		!equality.Semantic.DeepEqual(hpa.Spec.Metrics, desired.Spec.Metrics) {
+		hpaCopy := hpa.DeepCopy()
+		hpaCopy.Spec = desired.Spec
+		_, hpa_err = c.kubeclientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Update(context.TODO(), hpaCopy, metav1.UpdateOptions{})
+	}
+
+	return hpa_err
+}
+
+func newSubmarinePodDisruptionBudget(submarine *v1alpha1.Submarine, component string, name string, labels map[string]string, replicas int32) *policyv1beta1.PodDisruptionBudget {
+	// Allow a single pod of the component to be disrupted at a time
+	minAvailable := intstr.FromInt(int(replicas) - 1)
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: submarineLabels(submarine, component, nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+}
+
+// podDisruptionBudgetEnabled returns whether a component with replicas gets
+// a PodDisruptionBudget. A single replica gets none, otherwise it would block
+// node drains forever.
+func podDisruptionBudgetEnabled(replicas int32) bool {
+	return replicas > 1
+}
+
+// databaseReplicas returns spec.database.replicas, which defaults to a single
+// replica as for Deployments
+func databaseReplicas(submarine *v1alpha1.Submarine) int32 {
+	if submarine.Spec.Database.Replicas == nil {
+		return 1
+	}
+	return *submarine.Spec.Database.Replicas
+}
+
+// newPodDisruptionBudget is a function to create, update or remove the
+// PodDisruptionBudget of a component according to podDisruptionBudgetEnabled.
+func (c *Controller) newPodDisruptionBudget(submarine *v1alpha1.Submarine, namespace string, component string, name string, labels map[string]string, replicas int32) error {
+	klog.Info("[newPodDisruptionBudget] ", name)
+
+	pdb, pdb_err := c.pdbLister.PodDisruptionBudgets(namespace).Get(name)
+
+	if !podDisruptionBudgetEnabled(replicas) {
+		if errors.IsNotFound(pdb_err) {
+			return nil
+		}
+		if pdb_err != nil {
+			return pdb_err
+		}
+		if !metav1.IsControlledBy(pdb, submarine) {
+			return nil
+		}
+		klog.Info("	Delete PodDisruptionBudget: ", pdb.Name)
+		return c.kubeclientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(context.TODO(), pdb.Name, metav1.DeleteOptions{})
+	}
+
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(pdb_err) {
+		pdb, pdb_err = c.kubeclientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Create(context.TODO(), newSubmarinePodDisruptionBudget(submarine, component, name, labels, replicas), metav1.CreateOptions{})
+		if pdb_err != nil {
+			klog.Info(pdb_err)
+		}
+		klog.Info("	Create PodDisruptionBudget: ", pdb.Name)
+	}
+
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if pdb_err != nil {
+		return pdb_err
+	}
+
+	if err := c.claimObject(submarine, pdb, component, func(patch []byte) error {
+		pdb, pdb_err = c.kubeclientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Patch(context.TODO(), pdb.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return pdb_err
+	}); err != nil {
+		return err
+	}
+
+	// Update the PodDisruptionBudget if it does not fit the replicas anymore
+	desired := newSubmarinePodDisruptionBudget(submarine, component, name, labels, replicas)
+	if !equality.Semantic.DeepEqual(pdb.Spec.MinAvailable, desired.Spec.MinAvailable) {
+		pdbCopy := pdb.DeepCopy()
+		pdbCopy.Spec.MinAvailable = desired.Spec.MinAvailable
+		_, pdb_err = c.kubeclientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Update(context.TODO(), pdbCopy, metav1.UpdateOptions{})
+	}
+
+	return pdb_err
+}
+
+func newSubmarineServerIngress(submarine *v1alpha1.Submarine, namespace string) *extensionsv1beta1.Ingress {
+	return &extensionsv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serverName(submarine) + "-ingress",
+			Namespace: namespace,
+			Labels:    submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Rules: []extensionsv1beta1.IngressRule{
+				{
+					IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+						HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+							Paths: []extensionsv1beta1.HTTPIngressPath{
+								{
+									Backend: extensionsv1beta1.IngressBackend{
+										ServiceName: serverName(submarine),
+										ServicePort: intstr.FromInt(8080),
+									},
+									Path: "/",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newIngress is a function to create Ingress.
+// Reference: https://github.com/apache/submarine/blob/master/helm-charts/submarine/templates/submarine-ingress.yaml
+func (c *Controller) newIngress(submarine *v1alpha1.Submarine, namespace string) error {
+	klog.Info("[newIngress]")
+
+	// Step1: Create ServiceAccount
+	ingress, ingress_err := c.ingressLister.Ingresses(namespace).Get(serverName(submarine) + "-ingress")
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(ingress_err) {
+		ingress, ingress_err = c.kubeclientset.ExtensionsV1beta1().Ingresses(namespace).Create(context.TODO(), newSubmarineServerIngress(submarine, namespace), metav1.CreateOptions{})
+		klog.Info("	Create Ingress: ", ingress.Name)
 	}
 
 	// If an error occurs during Get/Create, we'll requeue the item so we can
@@ -697,56 +1489,83 @@ func (c *Controller) newIngress(submarine *v1alpha1.Submarine, namespace string)
 		return ingress_err
 	}
 
-	if !metav1.IsControlledBy(ingress, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, ingress.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, ingress, "server", func(patch []byte) error {
+		ingress, ingress_err = c.kubeclientset.ExtensionsV1beta1().Ingresses(namespace).Patch(context.TODO(), ingress.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return ingress_err
+	}); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+func newSubmarineServerClusterRole(submarine *v1alpha1.Submarine) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterScopedName(serverName(submarine), submarine.Namespace),
+			Labels: submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				Verbs:     []string{"get", "list", "watch", "create", "delete", "deletecollection", "patch", "update"},
+				APIGroups: []string{"kubeflow.org"},
+				Resources: operatorResources(submarine),
+			},
+			{
+				Verbs:     []string{"get", "list", "watch", "create", "delete", "deletecollection", "patch", "update"},
+				APIGroups: []string{"traefik.containo.us"},
+				Resources: []string{"ingressroutes"},
+			},
+			{
+				Verbs:     []string{"*"},
+				APIGroups: []string{""},
+				Resources: []string{"pods", "pods/log", "services", "persistentvolumes", "persistentvolumeclaims"},
+			},
+			{
+				Verbs:     []string{"*"},
+				APIGroups: []string{"apps"},
+				Resources: []string{"deployments", "deployments/status"},
+			},
+		},
+	}
+}
+
+func newSubmarineServerClusterRoleBinding(submarine *v1alpha1.Submarine, serviceaccount_namespace string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterScopedName(serverName(submarine), serviceaccount_namespace),
+			Labels: submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Namespace: serviceaccount_namespace,
+				Name:      serverName(submarine),
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     clusterScopedName(serverName(submarine), serviceaccount_namespace),
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+}
+
 // newSubmarineServerRBAC is a function to create RBAC for submarine-server.
 // Reference: https://github.com/apache/submarine/blob/master/helm-charts/submarine/templates/rbac.yaml
 func (c *Controller) newSubmarineServerRBAC(submarine *v1alpha1.Submarine, serviceaccount_namespace string) error {
 	klog.Info("[newSubmarineServerRBAC]")
-	serverName := "submarine-server"
 	// Step1: Create ClusterRole
-	clusterrole, clusterrole_err := c.clusterroleLister.Get(serverName)
+	clusterrole, clusterrole_err := c.clusterroleLister.Get(clusterScopedName(serverName(submarine), serviceaccount_namespace))
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(clusterrole_err) {
-		clusterrole, clusterrole_err = c.kubeclientset.RbacV1().ClusterRoles().Create(context.TODO(),
-			&rbacv1.ClusterRole{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: serverName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Rules: []rbacv1.PolicyRule{
-					{
-						Verbs:     []string{"get", "list", "watch", "create", "delete", "deletecollection", "patch", "update"},
-						APIGroups: []string{"kubeflow.org"},
-						Resources: []string{"tfjobs", "tfjobs/status", "pytorchjobs", "pytorchjobs/status", "notebooks", "notebooks/status"},
-					},
-					{
-						Verbs:     []string{"get", "list", "watch", "create", "delete", "deletecollection", "patch", "update"},
-						APIGroups: []string{"traefik.containo.us"},
-						Resources: []string{"ingressroutes"},
-					},
-					{
-						Verbs:     []string{"*"},
-						APIGroups: []string{""},
-						Resources: []string{"pods", "pods/log", "services", "persistentvolumes", "persistentvolumeclaims"},
-					},
-					{
-						Verbs:     []string{"*"},
-						APIGroups: []string{"apps"},
-						Resources: []string{"deployments", "deployments/status"},
-					},
-				},
-			},
-			metav1.CreateOptions{})
+		clusterrole, clusterrole_err = c.kubeclientset.RbacV1().ClusterRoles().Create(context.TODO(), newSubmarineServerClusterRole(submarine), metav1.CreateOptions{})
 		klog.Info("	Create ClusterRole: ", clusterrole.Name)
 	}
 
@@ -757,37 +1576,28 @@ func (c *Controller) newSubmarineServerRBAC(submarine *v1alpha1.Submarine, servi
 		return clusterrole_err
 	}
 
-	if !metav1.IsControlledBy(clusterrole, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, clusterrole.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, clusterrole, "server", func(patch []byte) error {
+		clusterrole, clusterrole_err = c.kubeclientset.RbacV1().ClusterRoles().Patch(context.TODO(), clusterrole.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return clusterrole_err
+	}); err != nil {
+		return err
+	}
+
+	// Update the rules when the enabled operators change
+	desired := newSubmarineServerClusterRole(submarine)
+	if !equality.Semantic.DeepEqual(desired.Rules, clusterrole.Rules) {
+		clusterroleCopy := clusterrole.DeepCopy()
+		clusterroleCopy.Rules = desired.Rules
+		_, clusterrole_err = c.kubeclientset.RbacV1().ClusterRoles().Update(context.TODO(), clusterroleCopy, metav1.UpdateOptions{})
+		if clusterrole_err != nil {
+			return clusterrole_err
+		}
 	}
 
-	clusterrolebinding, clusterrolebinding_err := c.clusterrolebindingLister.Get(serverName)
+	clusterrolebinding, clusterrolebinding_err := c.clusterrolebindingLister.Get(clusterScopedName(serverName(submarine), serviceaccount_namespace))
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(clusterrolebinding_err) {
-		clusterrolebinding, clusterrolebinding_err = c.kubeclientset.RbacV1().ClusterRoleBindings().Create(context.TODO(),
-			&rbacv1.ClusterRoleBinding{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: serverName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Subjects: []rbacv1.Subject{
-					{
-						Kind:      "ServiceAccount",
-						Namespace: serviceaccount_namespace,
-						Name:      serverName,
-					},
-				},
-				RoleRef: rbacv1.RoleRef{
-					Kind:     "ClusterRole",
-					Name:     serverName,
-					APIGroup: "rbac.authorization.k8s.io",
-				},
-			},
-			metav1.CreateOptions{})
+		clusterrolebinding, clusterrolebinding_err = c.kubeclientset.RbacV1().ClusterRoleBindings().Create(context.TODO(), newSubmarineServerClusterRoleBinding(submarine, serviceaccount_namespace), metav1.CreateOptions{})
 		klog.Info("	Create ClusterRoleBinding: ", clusterrolebinding.Name)
 	}
 
@@ -798,67 +1608,144 @@ func (c *Controller) newSubmarineServerRBAC(submarine *v1alpha1.Submarine, servi
 		return clusterrolebinding_err
 	}
 
-	if !metav1.IsControlledBy(clusterrolebinding, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, clusterrolebinding.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, clusterrolebinding, "server", func(patch []byte) error {
+		clusterrolebinding, clusterrolebinding_err = c.kubeclientset.RbacV1().ClusterRoleBindings().Patch(context.TODO(), clusterrolebinding.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return clusterrolebinding_err
+	}); err != nil {
+		return err
 	}
 
-	return nil
+	return c.deleteLegacyServerRBAC(submarine)
+}
+
+// storageEnabled returns whether spec.storage has a valid type, without which
+// the database and the tensorboard are not created
+func storageEnabled(submarine *v1alpha1.Submarine) bool {
+	if submarine.Spec.Storage == nil {
+		return false
+	}
+	switch submarine.Spec.Storage.StorageType {
+	case "nfs", "host":
+		return true
+	}
+	return false
+}
+
+// newSubmarinePersistentVolume returns a PersistentVolume backed by the
+// storage of the spec, or nil if the storage type is invalid.
+func newSubmarinePersistentVolume(submarine *v1alpha1.Submarine, component string, pvName string, storageSize string) *corev1.PersistentVolume {
+	var persistentVolumeSource corev1.PersistentVolumeSource
+	switch submarine.Spec.Storage.StorageType {
+	case "nfs":
+		persistentVolumeSource = corev1.PersistentVolumeSource{
+			NFS: &corev1.NFSVolumeSource{
+				Server: submarine.Spec.Storage.NfsIP,
+				Path:   submarine.Spec.Storage.NfsPath,
+			},
+		}
+	case "host":
+		hostPathType := corev1.HostPathDirectoryOrCreate
+		persistentVolumeSource = corev1.PersistentVolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: submarine.Spec.Storage.HostPath,
+				Type: &hostPathType,
+			},
+		}
+	default:
+		return nil
+	}
+
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   pvName,
+			Labels: submarineLabels(submarine, component, nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteMany,
+			},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(storageSize),
+			},
+			PersistentVolumeSource: persistentVolumeSource,
+		},
+	}
+}
+
+func newSubmarinePersistentVolumeClaim(submarine *v1alpha1.Submarine, component string, pvcName string, pvName string, storageSize string) *corev1.PersistentVolumeClaim {
+	storageClassName := ""
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   pvcName,
+			Labels: submarineLabels(submarine, component, nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteMany,
+			},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(storageSize),
+				},
+			},
+			VolumeName:       pvName,
+			StorageClassName: &storageClassName,
+		},
+	}
+}
+
+func newSubmarineDatabaseService(submarine *v1alpha1.Submarine) *corev1.Service {
+	ports := []corev1.ServicePort{
+		{
+			Port:       3306,
+			TargetPort: intstr.FromInt(3306),
+			Name:       databaseName(submarine),
+		},
+	}
+	if monitoringEnabled(submarine) {
+		ports = append(ports, newMetricsServicePort(databaseMetricsPort))
+	}
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   databaseName(submarine),
+			Labels: submarineLabels(submarine, "database", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: ports,
+			Selector: map[string]string{
+				"app": databaseName(submarine),
+			},
+		},
+	}
 }
 
 // newSubmarineDatabase is a function to create submarine-database.
 // Reference: https://github.com/apache/submarine/blob/master/helm-charts/submarine/templates/submarine-database.yaml
 func (c *Controller) newSubmarineDatabase(submarine *v1alpha1.Submarine, namespace string) (*appsv1.Deployment, error) {
 	klog.Info("[newSubmarineDatabase]")
+	if !storageEnabled(submarine) {
+		klog.Warningln("	Invalid storageType found in submarine spec, nothing will be created!")
+		return nil, nil
+	}
 
 	// Step1: Create PersistentVolume
 	// PersistentVolumes are not namespaced resources, so we add the namespace
 	// as a suffix to distinguish them
-	pvName := databaseName + "-pv--" + namespace
+	pvName := clusterScopedName(databaseName(submarine)+"-pv", namespace)
 	pv, pv_err := c.persistentvolumeLister.Get(pvName)
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(pv_err) {
-		var persistentVolumeSource corev1.PersistentVolumeSource
-		switch submarine.Spec.Storage.StorageType {
-		case "nfs":
-			persistentVolumeSource = corev1.PersistentVolumeSource{
-				NFS: &corev1.NFSVolumeSource{
-					Server: submarine.Spec.Storage.NfsIP,
-					Path:   submarine.Spec.Storage.NfsPath,
-				},
-			}
-		case "host":
-			hostPathType := corev1.HostPathDirectoryOrCreate
-			persistentVolumeSource = corev1.PersistentVolumeSource{
-				HostPath: &corev1.HostPathVolumeSource{
-					Path: submarine.Spec.Storage.HostPath,
-					Type: &hostPathType,
-				},
-			}
-		default:
-			klog.Warningln("	Invalid storageType found in submarine spec, nothing will be created!")
-			return nil, nil
-		}
-		pv, pv_err = c.kubeclientset.CoreV1().PersistentVolumes().Create(context.TODO(),
-			&corev1.PersistentVolume{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: pvName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: corev1.PersistentVolumeSpec{
-					AccessModes: []corev1.PersistentVolumeAccessMode{
-						corev1.ReadWriteMany,
-					},
-					Capacity: corev1.ResourceList{
-						corev1.ResourceStorage: resource.MustParse(submarine.Spec.Database.StorageSize),
-					},
-					PersistentVolumeSource: persistentVolumeSource,
-				},
-			},
-			metav1.CreateOptions{})
+		pv, pv_err = c.kubeclientset.CoreV1().PersistentVolumes().Create(context.TODO(), newSubmarinePersistentVolume(submarine, "database", pvName, submarine.Spec.Database.StorageSize), metav1.CreateOptions{})
 		if pv_err != nil {
 			klog.Info(pv_err)
 		}
@@ -871,40 +1758,19 @@ func (c *Controller) newSubmarineDatabase(submarine *v1alpha1.Submarine, namespa
 		return nil, pv_err
 	}
 
-	if !metav1.IsControlledBy(pv, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, pv.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
+	if err := c.claimObject(submarine, pv, "database", func(patch []byte) error {
+		pv, pv_err = c.kubeclientset.CoreV1().PersistentVolumes().Patch(context.TODO(), pv.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return pv_err
+	}); err != nil {
+		return nil, err
 	}
 
 	// Step2: Create PersistentVolumeClaim
-	pvcName := databaseName + "-pvc"
+	pvcName := databaseName(submarine) + "-pvc"
 	pvc, pvc_err := c.persistentvolumeclaimLister.PersistentVolumeClaims(namespace).Get(pvcName)
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(pvc_err) {
-		storageClassName := ""
-		pvc, pvc_err = c.kubeclientset.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(),
-			&corev1.PersistentVolumeClaim{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: pvcName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: corev1.PersistentVolumeClaimSpec{
-					AccessModes: []corev1.PersistentVolumeAccessMode{
-						corev1.ReadWriteMany,
-					},
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceStorage: resource.MustParse(submarine.Spec.Database.StorageSize),
-						},
-					},
-					VolumeName:       pvName,
-					StorageClassName: &storageClassName,
-				},
-			},
-			metav1.CreateOptions{})
+		pvc, pvc_err = c.kubeclientset.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(), newSubmarinePersistentVolumeClaim(submarine, "database", pvcName, pvName, submarine.Spec.Database.StorageSize), metav1.CreateOptions{})
 		if pvc_err != nil {
 			klog.Info(pvc_err)
 		}
@@ -917,202 +1783,429 @@ func (c *Controller) newSubmarineDatabase(submarine *v1alpha1.Submarine, namespa
 		return nil, pvc_err
 	}
 
-	if !metav1.IsControlledBy(pvc, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, pvc.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
+	if err := c.claimObject(submarine, pvc, "database", func(patch []byte) error {
+		pvc, pvc_err = c.kubeclientset.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return pvc_err
+	}); err != nil {
+		return nil, err
+	}
+
+	// Step3: Create Deployment
+	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(databaseName(submarine))
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(deployment_err) {
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Create(context.TODO(), newSubmarineDatabaseDeployment(submarine, pvcName), metav1.CreateOptions{})
+		if deployment_err != nil {
+			klog.Info(deployment_err)
+		}
+		klog.Info("	Create Deployment: ", deployment.Name)
+	}
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if deployment_err != nil {
+		return nil, deployment_err
+	}
+
+	if err := c.claimObject(submarine, deployment, "database", func(patch []byte) error {
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Patch(context.TODO(), deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return deployment_err
+	}); err != nil {
+		return nil, err
+	}
+
+	// Update the database deployment if it is not equal to spec
+	desired := newSubmarineDatabaseDeployment(submarine, pvcName)
+	if deploymentNeedsUpdate(desired, deployment) {
+		klog.V(4).Infof("Submarine %s database spec replicas: %v, actual replicas: %d", submarine.Name, desired.Spec.Replicas, *deployment.Spec.Replicas)
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(submarine.Namespace).Update(context.TODO(), desired, metav1.UpdateOptions{})
+	}
+
+	if deployment_err != nil {
+		return nil, deployment_err
+	}
+
+	// Step4: Create Service
+	service, service_err := c.serviceLister.Services(namespace).Get(databaseName(submarine))
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(service_err) {
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(), newSubmarineDatabaseService(submarine), metav1.CreateOptions{})
+		if service_err != nil {
+			klog.Info(service_err)
+		}
+		klog.Info("	Create Service: ", service.Name)
+	}
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if service_err != nil {
+		return nil, service_err
+	}
+
+	if err := c.claimObject(submarine, service, "database", func(patch []byte) error {
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Patch(context.TODO(), service.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return service_err
+	}); err != nil {
+		return nil, err
+	}
+
+	// Update the ports of the Service, e.g. the metrics port
+	if err := c.updateServicePorts(newSubmarineDatabaseService(submarine), service); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// subcharts: https://github.com/apache/submarine/tree/master/helm-charts/submarine/charts
+// Each subchart is installed from charts/<name> as a release of the same name.
+// The subcharts of a Submarine are traefik and its enabled operators.
+// A release that fails is recorded in the status and retried on the next
+// resync, without blocking the other components. The installs, upgrades and
+// rollbacks run in the background, and the Submarine is synced again once
+// they finish.
+func (c *Controller) newSubCharts(submarine *v1alpha1.Submarine, namespace string) error {
+	var statuses []v1alpha1.SubmarineChartStatus
+	for _, name := range subChartsOf(submarine) {
+		source := subChartSource(name, c.chartSources)
+		// The error of the latest operation is reported once
+		running, operationErr := c.chartOperationResult(namespace, name)
+		var release *helmrelease.Release
+		var err error
+		if running {
+			release, err = helm.GetRelease(name, namespace)
+		} else {
+			release, err = c.reconcileSubChart(submarine, name, namespace, source)
+			running, _ = c.chartOperationResult(namespace, name)
+		}
+		if err == nil {
+			err = operationErr
+		}
+		status := v1alpha1.SubmarineChartStatus{Name: name, Source: source.String()}
+		switch {
+		case release != nil:
+			status = chartStatus(status, release)
+		case running:
+			status.Status = helmrelease.StatusPendingInstall.String()
+		case err == nil:
+			// Skipped operator
+			continue
+		}
+		if err != nil {
+			klog.Info("[Helm] Release ", name, " failed: ", err)
+			c.recorder.Eventf(submarine, corev1.EventTypeWarning, ChartFailed, MessageChartFailed, name, err)
+			if release == nil {
+				status.Status = helmrelease.StatusFailed.String()
+			}
+			status.Message = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	submarine.Status.Charts = statuses
+
+	return nil
+}
+
+// reconcileSubChart installs the release of a subchart, upgrades it to the
+// version of its source or to new values, and rolls a failed release back to its last
+// deployed revision. The operations run in the background. It returns the
+// current release, or nil if the release is not installed yet or the
+// operator of the subchart already runs in another namespace.
+func (c *Controller) reconcileSubChart(submarine *v1alpha1.Submarine, name string, namespace string, source helm.ChartSource) (*helmrelease.Release, error) {
+	release, err := helm.GetRelease(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	args := subChartValues(submarine, name)
+
+	if release == nil || release.Info.Status == helmrelease.StatusUninstalled {
+		// Skip an operator that already runs in another namespace
+		operator, err := c.findOperator(name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if operator != "" {
+			klog.Info("[Helm] Skip ", name, ", found ", operator)
+			c.recorder.Eventf(submarine, corev1.EventTypeNormal, OperatorFound, MessageOperatorFound, name, operator)
+			return nil, nil
+		}
+		return c.installSubChart(submarine, name, namespace, source, args)
+	}
+
+	switch {
+	case release.Info.Status == helmrelease.StatusDeployed:
+		chart, err := source.Load()
+		if err != nil {
+			return release, err
+		}
+		valuesChanged, err := helm.ValuesChanged(release, args)
+		if err != nil {
+			return release, err
+		}
+		if chart.Metadata.Version == release.Chart.Metadata.Version && !valuesChanged {
+			return release, nil
+		}
+		klog.Info("[Helm] Upgrade ", name, " from ", release.Chart.Metadata.Version, " to ", chart.Metadata.Version)
+		c.startChartOperation(submarine, namespace, name, func() error {
+			if _, err := helm.HelmUpgradeChart(source, name, namespace, args, c.releaseOptions); err != nil {
+				return fmt.Errorf("error upgrading chart %s from %s: %v", name, source, err)
+			}
+			return nil
+		})
+		return release, nil
+	case release.Info.Status.IsPending() && !helm.IsStuck(release, c.releaseOptions.Timeout):
+		// Another operation is still in progress
+		return release, nil
+	}
+
+	// The release failed, or is stuck in a pending status
+	revision, err := helm.LastDeployedRevision(name, namespace)
+	if err != nil {
+		return release, err
+	}
+	if revision == 0 {
+		klog.Info("[Helm] Reinstall ", name, ", release is ", release.Info.Status)
+		c.recorder.Eventf(submarine, corev1.EventTypeNormal, ChartReinstalled, MessageChartReinstalled, name, release.Info.Status)
+		if err := helm.HelmUninstallRelease(name, namespace); err != nil {
+			return release, fmt.Errorf("error uninstalling chart %s: %v", name, err)
+		}
+		return c.installSubChart(submarine, name, namespace, source, args)
+	}
+	klog.Info("[Helm] Roll back ", name, " to revision ", revision, ", release is ", release.Info.Status)
+	c.recorder.Eventf(submarine, corev1.EventTypeNormal, ChartRolledBack, MessageChartRolledBack, name, release.Info.Status, revision)
+	c.startChartOperation(submarine, namespace, name, func() error {
+		if err := helm.HelmRollback(name, namespace, revision, c.releaseOptions); err != nil {
+			return fmt.Errorf("error rolling back chart %s to revision %d: %v", name, revision, err)
+		}
+		return nil
+	})
+	return release, nil
+}
+
+// installSubChart starts the install of the release of a subchart, which is
+// kept to be uninstalled with the last Submarine of the namespace. It
+// returns the release before the install, if any.
+func (c *Controller) installSubChart(submarine *v1alpha1.Submarine, name string, namespace string, source helm.ChartSource, args map[string]string) (*helmrelease.Release, error) {
+	klog.Info("[Helm] Install ", name, " from ", source)
+	c.startChartOperation(submarine, namespace, name, func() error {
+		info, _, err := helm.HelmInstallChart(source, name, namespace, args, c.releaseOptions)
+		if err != nil {
+			return fmt.Errorf("error installing chart %s from %s: %v", name, source, err)
+		}
+		c.chartsLock.Lock()
+		defer c.chartsLock.Unlock()
+		for i, chart := range c.charts[namespace] {
+			if chart.ReleaseName() == name {
+				c.charts[namespace][i] = info
+				return nil
+			}
+		}
+		c.charts[namespace] = append(c.charts[namespace], info)
+		return nil
+	})
+	return nil, nil
+}
+
+// chartOperation is a Helm operation on the release of a subchart
+type chartOperation struct {
+	running bool
+	// err is the error of the operation once it finished
+	err error
+}
+
+// startChartOperation runs a Helm operation on the release of a subchart in
+// the background, unless one is already running, and syncs the Submarine
+// once it finishes
+func (c *Controller) startChartOperation(submarine *v1alpha1.Submarine, namespace string, name string, operation func() error) {
+	key := namespace + "/" + name
+	c.chartsLock.Lock()
+	if current := c.chartOperations[key]; current != nil && current.running {
+		c.chartsLock.Unlock()
+		return
 	}
+	c.chartOperations[key] = &chartOperation{running: true}
+	c.chartsLock.Unlock()
 
-	// Step3: Create Deployment
-	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(databaseName)
-	// If the resource doesn't exist, we'll create it
-	if errors.IsNotFound(deployment_err) {
-		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Create(context.TODO(), newSubmarineDatabaseDeployment(submarine, pvcName), metav1.CreateOptions{})
-		if deployment_err != nil {
-			klog.Info(deployment_err)
+	go func() {
+		err := operation()
+		if err != nil {
+			klog.Info("[Helm] Release ", name, " failed: ", err)
 		}
-		klog.Info("	Create Deployment: ", deployment.Name)
-	}
-	// If an error occurs during Get/Create, we'll requeue the item so we can
-	// attempt processing again later. This could have been caused by a
-	// temporary network failure, or any other transient reason.
-	if deployment_err != nil {
-		return nil, deployment_err
-	}
+		c.chartsLock.Lock()
+		c.chartOperations[key] = &chartOperation{err: err}
+		c.chartsLock.Unlock()
+		c.enqueueSubmarine(submarine, UPDATE)
+	}()
+}
 
-	if !metav1.IsControlledBy(deployment, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, deployment.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
-	}
+// chartOperationResult tells whether a Helm operation on the release of a
+// subchart is running, or returns the error of the finished one. A finished
+// operation is forgotten.
+func (c *Controller) chartOperationResult(namespace string, name string) (bool, error) {
+	key := namespace + "/" + name
+	c.chartsLock.Lock()
+	defer c.chartsLock.Unlock()
+	operation := c.chartOperations[key]
+	if operation == nil {
+		return false, nil
+	}
+	if operation.running {
+		return true, nil
+	}
+	delete(c.chartOperations, key)
+	return false, operation.err
+}
 
-	// Update the replicas of the database deployment if it is not equal to spec
-	if submarine.Spec.Database.Replicas != nil && *submarine.Spec.Database.Replicas != *deployment.Spec.Replicas {
-		klog.V(4).Infof("Submarine %s database spec replicas: %d, actual replicas: %d", submarine.Name, *submarine.Spec.Database.Replicas, *deployment.Spec.Replicas)
-		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(submarine.Namespace).Update(context.TODO(), newSubmarineDatabaseDeployment(submarine, pvcName), metav1.UpdateOptions{})
+// chartStatus returns the status of the release of a subchart
+func chartStatus(status v1alpha1.SubmarineChartStatus, release *helmrelease.Release) v1alpha1.SubmarineChartStatus {
+	status.Revision = release.Version
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		status.Version = release.Chart.Metadata.Version
 	}
+	if release.Info != nil {
+		status.Status = release.Info.Status.String()
+		status.Message = release.Info.Description
+	}
+	return status
+}
 
-	if deployment_err != nil {
-		return nil, deployment_err
+func newSubmarineTensorboardDeployment(submarine *v1alpha1.Submarine, pvcName string) *appsv1.Deployment {
+	tensorboardLabels := map[string]string{
+		"app": tensorboardName(submarine) + "-pod",
 	}
 
-	// Step4: Create Service
-	service, service_err := c.serviceLister.Services(namespace).Get(databaseName)
-	// If the resource doesn't exist, we'll create it
-	if errors.IsNotFound(service_err) {
-		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(),
-			&corev1.Service{
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   tensorboardName(submarine),
+			Labels: submarineLabels(submarine, "tensorboard", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: tensorboardLabels,
+			},
+			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: databaseName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
+					Labels: submarineLabels(submarine, "tensorboard", tensorboardLabels),
 				},
-				Spec: corev1.ServiceSpec{
-					Ports: []corev1.ServicePort{
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: submarine.Spec.ImagePullSecrets,
+					Containers: []corev1.Container{
 						{
-							Port:       3306,
-							TargetPort: intstr.FromInt(3306),
-							Name:       databaseName,
+							Name:  tensorboardName(submarine) + "-container",
+							Image: tensorboardImage(submarine),
+							Command: []string{
+								"tensorboard",
+								"--logdir=/logs",
+								"--path_prefix=/tensorboard",
+							},
+							ImagePullPolicy: "IfNotPresent",
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: 6006,
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									MountPath: "/logs",
+									Name:      "volume",
+									SubPath:   tensorboardName(submarine),
+								},
+							},
 						},
 					},
-					Selector: map[string]string{
-						"app": databaseName,
+					Volumes: []corev1.Volume{
+						{
+							Name: "volume",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: pvcName,
+								},
+							},
+						},
 					},
 				},
 			},
-			metav1.CreateOptions{})
-		if service_err != nil {
-			klog.Info(service_err)
-		}
-		klog.Info("	Create Service: ", service.Name)
-	}
-	// If an error occurs during Get/Create, we'll requeue the item so we can
-	// attempt processing again later. This could have been caused by a
-	// temporary network failure, or any other transient reason.
-	if service_err != nil {
-		return nil, service_err
+		},
 	}
+}
 
-	if !metav1.IsControlledBy(service, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, service.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return nil, fmt.Errorf(msg)
+func newSubmarineTensorboardService(submarine *v1alpha1.Submarine) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   tensorboardServiceName(submarine),
+			Labels: submarineLabels(submarine, "tensorboard", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app": tensorboardName(submarine) + "-pod",
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Protocol:   "TCP",
+					Port:       8080,
+					TargetPort: intstr.FromInt(6006),
+				},
+			},
+		},
 	}
-
-	return deployment, nil
 }
 
-// subcharts: https://github.com/apache/submarine/tree/master/helm-charts/submarine/charts
-
-func (c *Controller) newSubCharts(namespace string) error {
-	// Install traefik
-	// Reference: https://github.com/apache/submarine/tree/master/helm-charts/submarine/charts/traefik
-
-	if !helm.CheckRelease("traefik", namespace) {
-		klog.Info("[Helm] Install Traefik")
-		c.charts = append(c.charts, helm.HelmInstallLocalChart(
-			"traefik",
-			"charts/traefik",
-			"traefik",
-			namespace,
-			map[string]string{},
-		))
-	}
-
-	if !helm.CheckRelease("notebook-controller", namespace) {
-		klog.Info("[Helm] Install Notebook-Controller")
-		c.charts = append(c.charts, helm.HelmInstallLocalChart(
-			"notebook-controller",
-			"charts/notebook-controller",
-			"notebook-controller",
-			namespace,
-			map[string]string{},
-		))
-	}
-
-	if !helm.CheckRelease("tfjob", namespace) {
-		klog.Info("[Helm] Install TFjob")
-		c.charts = append(c.charts, helm.HelmInstallLocalChart(
-			"tfjob",
-			"charts/tfjob",
-			"tfjob",
-			namespace,
-			map[string]string{},
-		))
-	}
-
-	if !helm.CheckRelease("pytorchjob", namespace) {
-		klog.Info("[Helm] Install pytorchjob")
-		c.charts = append(c.charts, helm.HelmInstallLocalChart(
-			"pytorchjob",
-			"charts/pytorchjob",
-			"pytorchjob",
-			namespace,
-			map[string]string{},
-		))
-	}
-
-	// TODO: maintain "error"
-	// TODO: (sample-controller) controller.go:287 ~ 293
-
-	return nil
+func newSubmarineTensorboardIngressRoute(submarine *v1alpha1.Submarine) *traefikv1alpha1.IngressRoute {
+	return &traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   tensorboardName(submarine) + "-ingressroute",
+			Labels: submarineLabels(submarine, "tensorboard", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			EntryPoints: []string{
+				"web",
+			},
+			Routes: []traefikv1alpha1.Route{
+				{
+					Kind:  "Rule",
+					Match: "PathPrefix(`/tensorboard`)",
+					Services: []traefikv1alpha1.Service{
+						{
+							LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+								Kind: "Service",
+								Name: tensorboardServiceName(submarine),
+								Port: 8080,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
 }
 
 // newSubmarineTensorboard is a function to create submarine-tensorboard.
 // Reference: https://github.com/apache/submarine/blob/master/helm-charts/submarine/templates/submarine-tensorboard.yaml
 func (c *Controller) newSubmarineTensorboard(submarine *v1alpha1.Submarine, namespace string, spec *v1alpha1.SubmarineSpec) error {
 	klog.Info("[newSubmarineTensorboard]")
-	tensorboardName := "submarine-tensorboard"
+	if !storageEnabled(submarine) {
+		klog.Warningln("	Invalid storageType found in submarine spec, nothing will be created!")
+		return nil
+	}
 
 	// Step 1: Create PersistentVolume
 	// PersistentVolumes are not namespaced resources, so we add the namespace
 	// as a suffix to distinguish them
-	pvName := tensorboardName + "-pv--" + namespace
+	pvName := clusterScopedName(tensorboardName(submarine)+"-pv", namespace)
 	pv, pv_err := c.persistentvolumeLister.Get(pvName)
 
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(pv_err) {
-		var persistentVolumeSource corev1.PersistentVolumeSource
-		switch spec.Storage.StorageType {
-		case "nfs":
-			persistentVolumeSource = corev1.PersistentVolumeSource{
-				NFS: &corev1.NFSVolumeSource{
-					Server: spec.Storage.NfsIP,
-					Path:   spec.Storage.NfsPath,
-				},
-			}
-		case "host":
-			hostPathType := corev1.HostPathDirectoryOrCreate
-			persistentVolumeSource = corev1.PersistentVolumeSource{
-				HostPath: &corev1.HostPathVolumeSource{
-					Path: spec.Storage.HostPath,
-					Type: &hostPathType,
-				},
-			}
-		default:
-			klog.Warningln("	Invalid storageType found in submarine spec, nothing will be created!")
-			return nil
-		}
-		pv, pv_err = c.kubeclientset.CoreV1().PersistentVolumes().Create(context.TODO(),
-			&corev1.PersistentVolume{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: pvName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: corev1.PersistentVolumeSpec{
-					AccessModes: []corev1.PersistentVolumeAccessMode{
-						corev1.ReadWriteMany,
-					},
-					Capacity: corev1.ResourceList{
-						corev1.ResourceStorage: resource.MustParse(spec.Tensorboard.StorageSize),
-					},
-					PersistentVolumeSource: persistentVolumeSource,
-				},
-			},
-			metav1.CreateOptions{})
+		pv, pv_err = c.kubeclientset.CoreV1().PersistentVolumes().Create(context.TODO(), newSubmarinePersistentVolume(submarine, "tensorboard", pvName, spec.Tensorboard.StorageSize), metav1.CreateOptions{})
 		if pv_err != nil {
 			klog.Info(pv_err)
 		}
@@ -1126,40 +2219,19 @@ func (c *Controller) newSubmarineTensorboard(submarine *v1alpha1.Submarine, name
 		return pv_err
 	}
 
-	if !metav1.IsControlledBy(pv, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, pv.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, pv, "tensorboard", func(patch []byte) error {
+		pv, pv_err = c.kubeclientset.CoreV1().PersistentVolumes().Patch(context.TODO(), pv.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return pv_err
+	}); err != nil {
+		return err
 	}
 
 	// Step 2: Create PersistentVolumeClaim
-	pvcName := tensorboardName + "-pvc"
+	pvcName := tensorboardName(submarine) + "-pvc"
 	pvc, pvc_err := c.persistentvolumeclaimLister.PersistentVolumeClaims(namespace).Get(pvcName)
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(pvc_err) {
-		storageClassName := ""
-		pvc, pvc_err = c.kubeclientset.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(),
-			&corev1.PersistentVolumeClaim{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: pvcName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: corev1.PersistentVolumeClaimSpec{
-					AccessModes: []corev1.PersistentVolumeAccessMode{
-						corev1.ReadWriteMany,
-					},
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceStorage: resource.MustParse(spec.Tensorboard.StorageSize),
-						},
-					},
-					VolumeName:       pvName,
-					StorageClassName: &storageClassName,
-				},
-			},
-			metav1.CreateOptions{})
+		pvc, pvc_err = c.kubeclientset.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(), newSubmarinePersistentVolumeClaim(submarine, "tensorboard", pvcName, pvName, spec.Tensorboard.StorageSize), metav1.CreateOptions{})
 		if pvc_err != nil {
 			klog.Info(pvc_err)
 		}
@@ -1172,75 +2244,17 @@ func (c *Controller) newSubmarineTensorboard(submarine *v1alpha1.Submarine, name
 		return pvc_err
 	}
 
-	if !metav1.IsControlledBy(pvc, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, pvc.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, pvc, "tensorboard", func(patch []byte) error {
+		pvc, pvc_err = c.kubeclientset.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return pvc_err
+	}); err != nil {
+		return err
 	}
 
 	// Step 3: Create Deployment
-	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(tensorboardName)
+	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(tensorboardName(submarine))
 	if errors.IsNotFound(deployment_err) {
-		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Create(context.TODO(),
-			&appsv1.Deployment{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: tensorboardName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: appsv1.DeploymentSpec{
-					Selector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"app": tensorboardName + "-pod",
-						},
-					},
-					Template: corev1.PodTemplateSpec{
-						ObjectMeta: metav1.ObjectMeta{
-							Labels: map[string]string{
-								"app": tensorboardName + "-pod",
-							},
-						},
-						Spec: corev1.PodSpec{
-							Containers: []corev1.Container{
-								{
-									Name:  tensorboardName + "-container",
-									Image: "tensorflow/tensorflow:1.11.0",
-									Command: []string{
-										"tensorboard",
-										"--logdir=/logs",
-										"--path_prefix=/tensorboard",
-									},
-									ImagePullPolicy: "IfNotPresent",
-									Ports: []corev1.ContainerPort{
-										{
-											ContainerPort: 6006,
-										},
-									},
-									VolumeMounts: []corev1.VolumeMount{
-										{
-											MountPath: "/logs",
-											Name:      "volume",
-											SubPath:   tensorboardName,
-										},
-									},
-								},
-							},
-							Volumes: []corev1.Volume{
-								{
-									Name: "volume",
-									VolumeSource: corev1.VolumeSource{
-										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-											ClaimName: pvcName,
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			metav1.CreateOptions{})
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Create(context.TODO(), newSubmarineTensorboardDeployment(submarine, pvcName), metav1.CreateOptions{})
 		if deployment_err != nil {
 			klog.Info(deployment_err)
 		}
@@ -1253,39 +2267,18 @@ func (c *Controller) newSubmarineTensorboard(submarine *v1alpha1.Submarine, name
 		return deployment_err
 	}
 
-	if !metav1.IsControlledBy(deployment, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, deployment.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, deployment, "tensorboard", func(patch []byte) error {
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Patch(context.TODO(), deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return deployment_err
+	}); err != nil {
+		return err
 	}
 
 	// Step 4: Create Service
-	serviceName := tensorboardName + "-service"
-	service, service_err := c.serviceLister.Services(namespace).Get(serviceName)
+	service, service_err := c.serviceLister.Services(namespace).Get(tensorboardServiceName(submarine))
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(service_err) {
-		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(),
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: serviceName,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: corev1.ServiceSpec{
-					Selector: map[string]string{
-						"app": tensorboardName + "-pod",
-					},
-					Ports: []corev1.ServicePort{
-						{
-							Protocol:   "TCP",
-							Port:       8080,
-							TargetPort: intstr.FromInt(6006),
-						},
-					},
-				},
-			},
-			metav1.CreateOptions{})
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(), newSubmarineTensorboardService(submarine), metav1.CreateOptions{})
 		if service_err != nil {
 			klog.Info(service_err)
 		}
@@ -1298,46 +2291,18 @@ func (c *Controller) newSubmarineTensorboard(submarine *v1alpha1.Submarine, name
 		return service_err
 	}
 
-	if !metav1.IsControlledBy(service, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, service.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, service, "tensorboard", func(patch []byte) error {
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Patch(context.TODO(), service.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return service_err
+	}); err != nil {
+		return err
 	}
 
 	// Step 5: Create IngressRoute
-	ingressroute, ingressroute_err := c.ingressrouteLister.IngressRoutes(namespace).Get(tensorboardName + "-ingressroute")
+	ingressroute, ingressroute_err := c.ingressrouteLister.IngressRoutes(namespace).Get(tensorboardName(submarine) + "-ingressroute")
 	// If the resource doesn't exist, we'll create it
 	if errors.IsNotFound(ingressroute_err) {
-		ingressroute, ingressroute_err = c.traefikclientset.TraefikV1alpha1().IngressRoutes(namespace).Create(context.TODO(),
-			&traefikv1alpha1.IngressRoute{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: tensorboardName + "-ingressroute",
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
-					},
-				},
-				Spec: traefikv1alpha1.IngressRouteSpec{
-					EntryPoints: []string{
-						"web",
-					},
-					Routes: []traefikv1alpha1.Route{
-						{
-							Kind:  "Rule",
-							Match: "PathPrefix(`/tensorboard`)",
-							Services: []traefikv1alpha1.Service{
-								{
-									LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
-										Kind: "Service",
-										Name: serviceName,
-										Port: 8080,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			metav1.CreateOptions{})
+		ingressroute, ingressroute_err = c.traefikclientset.TraefikV1alpha1().IngressRoutes(namespace).Create(context.TODO(), newSubmarineTensorboardIngressRoute(submarine), metav1.CreateOptions{})
 		if ingressroute_err != nil {
 			klog.Info(ingressroute_err)
 		}
@@ -1350,10 +2315,11 @@ func (c *Controller) newSubmarineTensorboard(submarine *v1alpha1.Submarine, name
 		return ingressroute_err
 	}
 
-	if !metav1.IsControlledBy(ingressroute, submarine) {
-		msg := fmt.Sprintf(MessageResourceExists, ingressroute.Name)
-		c.recorder.Event(submarine, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf(msg)
+	if err := c.claimObject(submarine, ingressroute, "tensorboard", func(patch []byte) error {
+		ingressroute, ingressroute_err = c.traefikclientset.TraefikV1alpha1().IngressRoutes(namespace).Patch(context.TODO(), ingressroute.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return ingressroute_err
+	}); err != nil {
+		return err
 	}
 
 	return nil
@@ -1395,23 +2361,75 @@ func (c *Controller) syncHandler(workqueueItem WorkQueueItem) error {
 		var serverDeployment *appsv1.Deployment
 		var databaseDeployment *appsv1.Deployment
 
+		// Sequence a change of spec.version. The server and the database are
+		// created from deployed, which has the version they should run.
+		submarine = submarine.DeepCopy()
+
+		// Keep the names of the objects of a Submarine created by an earlier
+		// operator
+		if err := c.reconcileLegacyNames(submarine); err != nil {
+			return err
+		}
+
+		// A suspended Submarine only scales its deployments to zero and
+		// reports its status
+		paused, err := c.reconcileSuspension(submarine)
+		if err != nil {
+			return err
+		}
+		if paused {
+			serverDeployment, err = c.deploymentLister.Deployments(namespace).Get(serverName(submarine))
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			databaseDeployment, err = c.deploymentLister.Deployments(namespace).Get(databaseName(submarine))
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			return c.updateSubmarineStatus(submarine, serverDeployment, databaseDeployment)
+		}
+
+		deployed, err := c.reconcileUpgrade(submarine)
+		if err != nil {
+			return err
+		}
+
 		// Install subcharts
-		err = c.newSubCharts(namespace)
+		err = c.newSubCharts(submarine, namespace)
 		if err != nil {
 			return err
 		}
 
 		// Create submarine-server
-		serverDeployment, err = c.newSubmarineServer(submarine, namespace)
+		serverDeployment, err = c.newSubmarineServer(deployed, namespace)
+		if err != nil {
+			return err
+		}
+		c.completeUpgrade(submarine, serverDeployment)
+
+		// Create or remove the autoscaler of submarine-server
+		err = c.newSubmarineServerAutoscaler(submarine, namespace)
 		if err != nil {
 			return err
 		}
 
 		// Create Submarine Database
-		databaseDeployment, err = c.newSubmarineDatabase(submarine, namespace)
+		databaseDeployment, err = c.newSubmarineDatabase(deployed, namespace)
+		if err != nil {
+			return err
+		}
+
+		// Create PodDisruptionBudgets
+		err = c.newPodDisruptionBudget(submarine, namespace, "server", serverName(submarine)+"-pdb", map[string]string{"run": serverName(submarine)}, serverMinReplicas(submarine))
 		if err != nil {
 			return err
 		}
+		if storageEnabled(submarine) {
+			err = c.newPodDisruptionBudget(submarine, namespace, "database", databaseName(submarine)+"-pdb", map[string]string{"app": databaseName(submarine)}, databaseReplicas(submarine))
+			if err != nil {
+				return err
+			}
+		}
 
 		// Create ingress
 		err = c.newIngress(submarine, namespace)
@@ -1431,6 +2449,36 @@ func (c *Controller) syncHandler(workqueueItem WorkQueueItem) error {
 			return err
 		}
 
+		// Create ServiceMonitors and PodMonitor
+		err = c.newSubmarineMonitors(submarine, namespace)
+		if err != nil {
+			return err
+		}
+
+		// Archive the logs of the finished jobs before they are deleted
+		err = c.archiveJobLogs(submarine, namespace)
+		if err != nil {
+			return err
+		}
+
+		// Delete finished jobs and stop idle notebooks
+		err = c.cleanupWorkloads(submarine, namespace)
+		if err != nil {
+			return err
+		}
+
+		// Admit the queued jobs that fit in the capacity
+		err = c.reconcileQueue(submarine, namespace)
+		if err != nil {
+			return err
+		}
+
+		// Pre-pull the images of the experiments on the nodes
+		err = c.reconcilePrePull(submarine, namespace)
+		if err != nil {
+			return err
+		}
+
 		err = c.updateSubmarineStatus(submarine, serverDeployment, databaseDeployment)
 		if err != nil {
 			return err
@@ -1439,11 +2487,23 @@ func (c *Controller) syncHandler(workqueueItem WorkQueueItem) error {
 		c.recorder.Event(submarine, corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
 
 	} else { // Case: DELETE
-		// Uninstall Helm charts
-		for _, chart := range c.charts {
+		// Uninstall Helm charts once the last Submarine of the namespace
+		// is gone
+		submarines, err := c.submarinesLister.Submarines(namespace).List(labels.Everything())
+		if err != nil {
+			return err
+		}
+		if len(submarines) > 0 {
+			klog.Info("Keep Helm charts of namespace ", namespace, " for ", len(submarines), " other Submarine(s)")
+			return nil
+		}
+		c.chartsLock.Lock()
+		charts := c.charts[namespace]
+		delete(c.charts, namespace)
+		c.chartsLock.Unlock()
+		for _, chart := range charts {
 			helm.HelmUninstall(chart)
 		}
-		c.charts = nil
 	}
 
 	return nil
@@ -1451,12 +2511,74 @@ func (c *Controller) syncHandler(workqueueItem WorkQueueItem) error {
 
 func (c *Controller) updateSubmarineStatus(submarine *v1alpha1.Submarine, serverDeployment *appsv1.Deployment, databaseDeployment *appsv1.Deployment) error {
 	submarineCopy := submarine.DeepCopy()
-	submarineCopy.Status.AvailableServerReplicas = serverDeployment.Status.AvailableReplicas
-	submarineCopy.Status.AvailableDatabaseReplicas = databaseDeployment.Status.AvailableReplicas
+	if serverDeployment != nil {
+		submarineCopy.Status.AvailableServerReplicas = serverDeployment.Status.AvailableReplicas
+		if selector, err := metav1.LabelSelectorAsSelector(serverDeployment.Spec.Selector); err == nil {
+			submarineCopy.Status.ServerSelector = selector.String()
+		}
+	}
+	if databaseDeployment != nil {
+		submarineCopy.Status.AvailableDatabaseReplicas = databaseDeployment.Status.AvailableReplicas
+	}
+
+	serverAvailable := setDeploymentAvailableCondition(submarineCopy, v1alpha1.SubmarineServerAvailable, serverDeployment)
+	databaseAvailable := setDeploymentAvailableCondition(submarineCopy, v1alpha1.SubmarineDatabaseAvailable, databaseDeployment)
+	ready := metav1.Condition{
+		Type:               v1alpha1.SubmarineReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: submarine.Generation,
+		Reason:             "ComponentsAvailable",
+		Message:            "All components are available",
+	}
+	if !serverAvailable || !databaseAvailable {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ComponentsUnavailable"
+		ready.Message = "Some components are not available"
+	}
+	for _, chart := range submarineCopy.Status.Charts {
+		if chart.Status != helmrelease.StatusDeployed.String() {
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = "ChartsNotDeployed"
+			ready.Message = fmt.Sprintf("Release %s is %s", chart.Name, chart.Status)
+			break
+		}
+	}
+	if submarine.Spec.Suspend {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = SubmarineSuspendedReason
+		ready.Message = "The Submarine is suspended"
+	}
+	meta.SetStatusCondition(&submarineCopy.Status.Conditions, ready)
+
 	_, err := c.submarineclientset.SubmarineV1alpha1().Submarines(submarine.Namespace).Update(context.TODO(), submarineCopy, metav1.UpdateOptions{})
 	return err
 }
 
+// setDeploymentAvailableCondition sets the condition conditionType of the
+// Submarine from the Available condition of the deployment, and returns
+// whether the deployment is available.
+func setDeploymentAvailableCondition(submarine *v1alpha1.Submarine, conditionType string, deployment *appsv1.Deployment) bool {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: submarine.Generation,
+		Reason:             "DeploymentNotFound",
+		Message:            "The deployment has not been created",
+	}
+	if deployment != nil {
+		condition.Reason = "DeploymentUnavailable"
+		condition.Message = fmt.Sprintf("Deployment %s has %d/%d available replicas", deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.Replicas)
+		for _, c := range deployment.Status.Conditions {
+			if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
+				condition.Status = metav1.ConditionTrue
+				condition.Reason = "DeploymentAvailable"
+			}
+		}
+	}
+	meta.SetStatusCondition(&submarine.Status.Conditions, condition)
+	return condition.Status == metav1.ConditionTrue
+}
+
 // enqueueSubmarine takes a Submarine resource and converts it into a namespace/name
 // string which is then put onto the work queue. This method should *not* be
 // passed resources of any type other than Submarine.
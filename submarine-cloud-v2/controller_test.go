@@ -0,0 +1,301 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestServerAutoscaler(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.Server = &v1alpha1.SubmarineServer{
+		Replicas: int32Ptr(1),
+		Autoscaling: &v1alpha1.SubmarineServerAutoscaling{
+			Enabled:     boolPtr(true),
+			MinReplicas: int32Ptr(2),
+			MaxReplicas: 5,
+		},
+	}
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	if err := c.newSubmarineServerAutoscaler(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	hpas := kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("submarine-user-test")
+	hpa, err := hpas.Get(context.TODO(), serverName(submarine), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 5 || hpa.Spec.ScaleTargetRef.Name != serverName(submarine) {
+		t.Errorf("unexpected spec %+v", hpa.Spec)
+	}
+	if metric := hpa.Spec.Metrics; len(metric) != 1 || *metric[0].Resource.Target.AverageUtilization != 80 {
+		t.Errorf("expected the default CPU target, got %+v", metric)
+	}
+	// The Deployment starts from the lower bound of the autoscaler
+	if replicas := *newSubmarineServerDeployment(submarine).Spec.Replicas; replicas != 2 {
+		t.Errorf("replicas = %d, want 2", replicas)
+	}
+
+	// The bounds and the targets follow the spec
+	submarine.Spec.Server.Autoscaling.MaxReplicas = 10
+	submarine.Spec.Server.Autoscaling.TargetMemoryUtilizationPercentage = int32Ptr(60)
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{hpa}})
+	if err := c.newSubmarineServerAutoscaler(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	hpas = kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("submarine-user-test")
+	hpa, err = hpas.Get(context.TODO(), serverName(submarine), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hpa.Spec.MaxReplicas != 10 || len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Resource.Name != "memory" {
+		t.Errorf("unexpected spec %+v", hpa.Spec)
+	}
+
+	// Disabled: deleted
+	submarine.Spec.Server.Autoscaling.Enabled = boolPtr(false)
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{hpa}})
+	if err := c.newSubmarineServerAutoscaler(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("submarine-user-test").Get(context.TODO(), serverName(submarine), metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the HorizontalPodAutoscaler to be deleted, got %v", err)
+	}
+	if replicas := *newSubmarineServerDeployment(submarine).Spec.Replicas; replicas != 1 {
+		t.Errorf("replicas = %d, want spec.server.replicas", replicas)
+	}
+}
+
+func TestServerReplicasDefault(t *testing.T) {
+	// spec.server.replicas may be left out
+	submarine := newTestSubmarine()
+	submarine.Spec.Server = &v1alpha1.SubmarineServer{}
+	if replicas := *newSubmarineServerDeployment(submarine).Spec.Replicas; replicas != 1 {
+		t.Errorf("replicas = %d, want 1", replicas)
+	}
+	if min, max := serverMinReplicas(submarine), serverMaxReplicas(submarine); min != 1 || max != 1 {
+		t.Errorf("replicas = [%d, %d], want [1, 1]", min, max)
+	}
+}
+
+func TestPodDisruptionBudget(t *testing.T) {
+	submarine := newTestSubmarine()
+	name := serverName(submarine) + "-pdb"
+	labels := map[string]string{"run": serverName(submarine)}
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	if err := c.newPodDisruptionBudget(submarine, "submarine-user-test", "server", name, labels, 3); err != nil {
+		t.Fatal(err)
+	}
+	pdb, err := kubeClient.PolicyV1beta1().PodDisruptionBudgets("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pdb.Spec.MinAvailable.IntValue() != 2 || pdb.Spec.Selector.MatchLabels["run"] != serverName(submarine) {
+		t.Errorf("unexpected spec %+v", pdb.Spec)
+	}
+
+	// A single pod may be disrupted at a time whatever the replicas
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{pdb}})
+	if err := c.newPodDisruptionBudget(submarine, "submarine-user-test", "server", name, labels, 5); err != nil {
+		t.Fatal(err)
+	}
+	pdb, err = kubeClient.PolicyV1beta1().PodDisruptionBudgets("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pdb.Spec.MinAvailable.IntValue() != 4 {
+		t.Errorf("min available = %s, want 4", pdb.Spec.MinAvailable.String())
+	}
+
+	// A single replica would block node drains: deleted
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{pdb}})
+	if err := c.newPodDisruptionBudget(submarine, "submarine-user-test", "server", name, labels, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.PolicyV1beta1().PodDisruptionBudgets("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the PodDisruptionBudget to be deleted, got %v", err)
+	}
+}
+
+func TestTopologySpreadConstraints(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.Server = &v1alpha1.SubmarineServer{Replicas: int32Ptr(1)}
+	single := newSubmarineServerDeployment(submarine)
+	if constraints := single.Spec.Template.Spec.TopologySpreadConstraints; len(constraints) != 0 {
+		t.Errorf("expected no constraints for a single replica, got %v", constraints)
+	}
+
+	// Several replicas are spread across nodes and zones
+	submarine.Spec.Server.Replicas = int32Ptr(3)
+	spread := newSubmarineServerDeployment(submarine)
+	constraints := spread.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 2 || constraints[0].TopologyKey != corev1.LabelHostname || constraints[1].TopologyKey != corev1.LabelTopologyZone {
+		t.Fatalf("unexpected constraints %v", constraints)
+	}
+	if constraints[0].LabelSelector.MatchLabels["run"] != serverName(submarine) {
+		t.Errorf("unexpected selector %v", constraints[0].LabelSelector)
+	}
+	if !deploymentNeedsUpdate(spread, single) {
+		t.Error("expected the new constraints to update the Deployment")
+	}
+
+	// The constraints of the spec select the server pods by default
+	submarine.Spec.Server.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+		{MaxSkew: 2, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.DoNotSchedule},
+	}
+	custom := newSubmarineServerDeployment(submarine)
+	constraints = custom.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 1 || constraints[0].MaxSkew != 2 || constraints[0].LabelSelector.MatchLabels["run"] != serverName(submarine) {
+		t.Errorf("unexpected constraints %v", constraints)
+	}
+	if submarine.Spec.Server.TopologySpreadConstraints[0].LabelSelector != nil {
+		t.Error("the constraints of the spec were modified")
+	}
+	if !deploymentNeedsUpdate(custom, spread) {
+		t.Error("expected the constraints of the spec to update the Deployment")
+	}
+}
+
+// serverTestObjects returns the objects of the server of a Submarine held by
+// the API server, for the listers of the next sync
+func serverTestObjects(t *testing.T, kubeClient *kubefake.Clientset, submarine *v1alpha1.Submarine) []runtime.Object {
+	t.Helper()
+	var objects []runtime.Object
+	if serviceaccount, err := kubeClient.CoreV1().ServiceAccounts("submarine-user-test").Get(context.TODO(), serverName(submarine), metav1.GetOptions{}); err == nil {
+		objects = append(objects, serviceaccount)
+	}
+	if service, err := kubeClient.CoreV1().Services("submarine-user-test").Get(context.TODO(), serverName(submarine), metav1.GetOptions{}); err == nil {
+		objects = append(objects, service)
+	}
+	if configmap, err := kubeClient.CoreV1().ConfigMaps("submarine-user-test").Get(context.TODO(), serverConfigName(submarine), metav1.GetOptions{}); err == nil {
+		objects = append(objects, configmap)
+	}
+	if deployment, err := kubeClient.AppsV1().Deployments("submarine-user-test").Get(context.TODO(), serverName(submarine), metav1.GetOptions{}); err == nil {
+		objects = append(objects, deployment)
+	}
+	return objects
+}
+
+func TestServerConfig(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.Server = &v1alpha1.SubmarineServer{
+		Replicas: int32Ptr(1),
+		Config: &v1alpha1.SubmarineServerConfig{
+			Properties: map[string]string{"submarine.server.rpc.enabled": "true"},
+		},
+	}
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	deployment, err := c.newSubmarineServer(submarine, "submarine-user-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configmaps := kubeClient.CoreV1().ConfigMaps("submarine-user-test")
+	configmap, err := configmaps.Get(context.TODO(), serverConfigName(submarine), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xml := configmap.Data[serverConfigFile]; !strings.Contains(xml, "<name>submarine.server.rpc.enabled</name>") {
+		t.Errorf("unexpected %s:\n%s", serverConfigFile, xml)
+	}
+	hash := deployment.Spec.Template.Annotations[configHashAnnotation]
+	if hash != hashConfig(configmap.Data[serverConfigFile]) {
+		t.Errorf("config hash = %q, want the hash of the ConfigMap", hash)
+	}
+	if volumes := deployment.Spec.Template.Spec.Volumes; len(volumes) != 1 || volumes[0].ConfigMap.Name != serverConfigName(submarine) {
+		t.Errorf("unexpected volumes %v", volumes)
+	}
+
+	// A change of the config updates the ConfigMap and rolls the server
+	submarine.Spec.Server.Config.Properties["submarine.server.rpc.enabled"] = "false"
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: serverTestObjects(t, kubeClient, submarine)})
+	deployment, err = c.newSubmarineServer(submarine, "submarine-user-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configmaps = kubeClient.CoreV1().ConfigMaps("submarine-user-test")
+	configmap, err = configmaps.Get(context.TODO(), serverConfigName(submarine), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xml := configmap.Data[serverConfigFile]; !strings.Contains(xml, "<value>false</value>") {
+		t.Errorf("unexpected %s:\n%s", serverConfigFile, xml)
+	}
+	rolled := deployment.Spec.Template.Annotations[configHashAnnotation]
+	if rolled == hash || rolled != hashConfig(configmap.Data[serverConfigFile]) {
+		t.Errorf("config hash = %q, want the hash of the new ConfigMap", rolled)
+	}
+
+	// Without config: deleted, and the server falls back to the one of its
+	// image
+	submarine.Spec.Server.Config = nil
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: serverTestObjects(t, kubeClient, submarine)})
+	deployment, err = c.newSubmarineServer(submarine, "submarine-user-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("submarine-user-test").Get(context.TODO(), serverConfigName(submarine), metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the ConfigMap to be deleted, got %v", err)
+	}
+	if hash, ok := deployment.Spec.Template.Annotations[configHashAnnotation]; ok {
+		t.Errorf("unexpected config hash %q", hash)
+	}
+	if volumes := deployment.Spec.Template.Spec.Volumes; len(volumes) != 0 {
+		t.Errorf("unexpected volumes %v", volumes)
+	}
+}
+
+func TestInvalidStorage(t *testing.T) {
+	// Neither syncHandler nor render create the database and the
+	// tensorboard without valid storage
+	submarine := newTestSubmarine()
+	submarine.Spec.Server = &v1alpha1.SubmarineServer{Replicas: int32Ptr(1)}
+	submarine.Spec.Database.Replicas = int32Ptr(2)
+	submarine.Spec.Tensorboard = &v1alpha1.SubmarineSharedTensorboard{}
+	submarine.Spec.Storage = &v1alpha1.SubmarineStorage{StorageType: "invalid"}
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	if deployment, err := c.newSubmarineDatabase(submarine, "submarine-user-test"); err != nil || deployment != nil {
+		t.Errorf("newSubmarineDatabase = %v, %v, expected no database", deployment, err)
+	}
+	if err := c.newSubmarineTensorboard(submarine, "submarine-user-test", &submarine.Spec); err != nil {
+		t.Fatal(err)
+	}
+	if pvs, _ := kubeClient.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{}); len(pvs.Items) != 0 {
+		t.Errorf("unexpected PersistentVolumes %v", pvs.Items)
+	}
+	for _, obj := range newSubmarineObjects(submarine) {
+		switch obj.(type) {
+		case *corev1.PersistentVolume, *corev1.PersistentVolumeClaim, *policyv1beta1.PodDisruptionBudget:
+			t.Errorf("unexpected rendered %T", obj)
+		}
+	}
+}
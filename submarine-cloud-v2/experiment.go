@@ -0,0 +1,557 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	clientset "submarine-cloud-v2/pkg/generated/clientset/versioned"
+	submarinescheme "submarine-cloud-v2/pkg/generated/clientset/versioned/scheme"
+	informers "submarine-cloud-v2/pkg/generated/informers/externalversions/submarine/v1alpha1"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const experimentControllerAgentName = "submarine-experiment-controller"
+
+// submarineNameLabel holds the name of the Submarine of the jobs of the
+// SubmarineExperiments
+const submarineNameLabel = "submarine.k8s.io/submarine"
+
+// kubeflowGroupVersion is the API of the jobs of the workload operators
+var kubeflowGroupVersion = schema.GroupVersion{Group: "kubeflow.org", Version: "v1"}
+
+// experimentJob describes the job of a framework
+type experimentJob struct {
+	kind     string
+	resource string
+	// replicaSpecs is the field of the spec holding the replica specs
+	replicaSpecs string
+	// container is the name of the container the operator looks for
+	container string
+	operator  v1alpha1.SubmarineOperator
+}
+
+var experimentJobs = map[v1alpha1.ExperimentFramework]experimentJob{
+	v1alpha1.ExperimentFrameworkTensorFlow: {
+		kind:         "TFJob",
+		resource:     "tfjobs",
+		replicaSpecs: "tfReplicaSpecs",
+		container:    "tensorflow",
+		operator:     v1alpha1.OperatorTFJob,
+	},
+	v1alpha1.ExperimentFrameworkPyTorch: {
+		kind:         "PyTorchJob",
+		resource:     "pytorchjobs",
+		replicaSpecs: "pytorchReplicaSpecs",
+		container:    "pytorch",
+		operator:     v1alpha1.OperatorPyTorchJob,
+	},
+}
+
+// replicaTypeLabels are the labels of the pods of a job holding the replica
+// type, depending on the version of the operator
+var replicaTypeLabels = []string{
+	"training.kubeflow.org/replica-type",
+	"replica-type",
+	"tf-replica-type",
+	"pytorch-replica-type",
+}
+
+// jobNameLabels are the labels of the pods of a job holding the name of the
+// job, depending on the version of the operator
+var jobNameLabels = []string{
+	"training.kubeflow.org/job-name",
+	"job-name",
+	"tf-job-name",
+	"pytorch-job-name",
+}
+
+// Reasons of the events and of the Submitted condition of a
+// SubmarineExperiment
+const (
+	JobCreatedReason         = "JobCreated"
+	InvalidSpecReason        = "InvalidSpec"
+	OperatorNotEnabledReason = "OperatorNotEnabled"
+	// MessageJobCreated is the message used for an Event fired when the job
+	// of a SubmarineExperiment is created
+	MessageJobCreated = "Created %s %s"
+)
+
+// ExperimentController reconciles SubmarineExperiments into the TFJobs and
+// PyTorchJobs of the workload operators
+type ExperimentController struct {
+	kubeclientset      kubernetes.Interface
+	submarineclientset clientset.Interface
+	dynamicclientset   dynamic.Interface
+
+	experimentsLister listers.SubmarineExperimentLister
+	experimentsSynced cache.InformerSynced
+	submarinesLister  listers.SubmarineLister
+	podLister         corelisters.PodLister
+	podsSynced        cache.InformerSynced
+	// workqueue holds the keys of the SubmarineExperiments to reconcile
+	workqueue workqueue.RateLimitingInterface
+	recorder  record.EventRecorder
+}
+
+// NewExperimentController returns a new SubmarineExperiment controller
+func NewExperimentController(
+	kubeclientset kubernetes.Interface,
+	submarineclientset clientset.Interface,
+	dynamicclientset dynamic.Interface,
+	podInformer coreinformers.PodInformer,
+	submarineInformer informers.SubmarineInformer,
+	experimentInformer informers.SubmarineExperimentInformer) *ExperimentController {
+
+	utilruntime.Must(submarinescheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: experimentControllerAgentName})
+
+	controller := &ExperimentController{
+		kubeclientset:      kubeclientset,
+		submarineclientset: submarineclientset,
+		dynamicclientset:   dynamicclientset,
+		experimentsLister:  experimentInformer.Lister(),
+		experimentsSynced:  experimentInformer.Informer().HasSynced,
+		submarinesLister:   submarineInformer.Lister(),
+		podLister:          podInformer.Lister(),
+		podsSynced:         podInformer.Informer().HasSynced,
+		workqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineExperiments"),
+		recorder:           recorder,
+	}
+
+	klog.Info("Setting up SubmarineExperiment event handlers")
+	experimentInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueExperiment,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueExperiment(new)
+		},
+	})
+	// The job has no informer since its CRD may not be installed yet. Its
+	// status changes with the pods, and is refreshed on every resync.
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handlePod,
+		UpdateFunc: func(old, new interface{}) {
+			if new.(*corev1.Pod).ResourceVersion == old.(*corev1.Pod).ResourceVersion {
+				return
+			}
+			controller.handlePod(new)
+		},
+		DeleteFunc: controller.handlePod,
+	})
+
+	return controller
+}
+
+// Run waits for the caches to sync and processes SubmarineExperiments until
+// stopCh is closed
+func (c *ExperimentController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Info("Starting SubmarineExperiment controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.experimentsSynced, c.podsSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down SubmarineExperiment workers")
+
+	return nil
+}
+
+func (c *ExperimentController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *ExperimentController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(key); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(key)
+		utilruntime.HandleError(fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.Infof("Successfully synced '%s'", key)
+	return true
+}
+
+func (c *ExperimentController) enqueueExperiment(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// handlePod enqueues the SubmarineExperiment of the job that controls the
+// pod. Jobs are named after their experiment.
+func (c *ExperimentController) handlePod(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+	ownerRef := metav1.GetControllerOf(object)
+	if ownerRef == nil || ownerRef.APIVersion != kubeflowGroupVersion.String() {
+		return
+	}
+	experiment, err := c.experimentsLister.SubmarineExperiments(object.GetNamespace()).Get(ownerRef.Name)
+	if err != nil {
+		return
+	}
+	c.enqueueExperiment(experiment)
+}
+
+// syncHandler reconciles the SubmarineExperiment of key
+func (c *ExperimentController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	experiment, err := c.experimentsLister.SubmarineExperiments(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	experiment = experiment.DeepCopy()
+	klog.Info("[syncExperiment] ", key)
+
+	job, err := c.reconcileExperimentJob(experiment)
+	if err != nil {
+		return err
+	}
+	if job != nil {
+		if err := c.mirrorJobStatus(experiment, job); err != nil {
+			return err
+		}
+	}
+	return c.updateExperimentStatus(experiment)
+}
+
+// newExperimentJob returns the TFJob or PyTorchJob of a SubmarineExperiment
+func newExperimentJob(experiment *v1alpha1.SubmarineExperiment) (*unstructured.Unstructured, error) {
+	jobType, ok := experimentJobs[experiment.Spec.Framework]
+	if !ok {
+		return nil, fmt.Errorf("unknown framework %q", experiment.Spec.Framework)
+	}
+	if len(experiment.Spec.Replicas) == 0 {
+		return nil, fmt.Errorf("no replicas")
+	}
+
+	replicaSpecs := map[string]interface{}{}
+	for replicaType, spec := range experiment.Spec.Replicas {
+		container := corev1.Container{
+			Name:      jobType.container,
+			Image:     experiment.Spec.Image,
+			Command:   experiment.Spec.Command,
+			Env:       append(append([]corev1.EnvVar{}, experiment.Spec.Env...), spec.Env...),
+			Resources: spec.Resources,
+			// Empty keeps the default of Kubernetes
+			TerminationMessagePath: experiment.Spec.TerminationMessagePath,
+		}
+		if spec.Image != "" {
+			container.Image = spec.Image
+		}
+		if len(spec.Command) != 0 {
+			container.Command = spec.Command
+		}
+		if container.Image == "" {
+			return nil, fmt.Errorf("no image for replica type %s", replicaType)
+		}
+		replicas := int32(1)
+		if spec.Replicas != nil {
+			replicas = *spec.Replicas
+		}
+
+		template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"submarine.k8s.io/experiment": experiment.Name,
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{container},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		replicaSpecs[replicaType] = map[string]interface{}{
+			"replicas":      int64(replicas),
+			"restartPolicy": "OnFailure",
+			"template":      template,
+		}
+	}
+
+	job := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				jobType.replicaSpecs: replicaSpecs,
+			},
+		},
+	}
+	job.SetAPIVersion(kubeflowGroupVersion.String())
+	job.SetKind(jobType.kind)
+	job.SetName(experiment.Name)
+	job.SetLabels(map[string]string{
+		"app.kubernetes.io/part-of":    "submarine",
+		"app.kubernetes.io/managed-by": "submarine-operator",
+		submarineNameLabel:             experiment.Spec.Submarine,
+		"submarine.k8s.io/experiment":  experiment.Name,
+	})
+	job.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(experiment, v1alpha1.SchemeGroupVersion.WithKind("SubmarineExperiment")),
+	})
+	return job, nil
+}
+
+// reconcileExperimentJob creates the job of a SubmarineExperiment and
+// returns it. It returns nil and sets the Submitted condition when the spec
+// is invalid, when the job is missing and the operator of the framework is
+// not enabled, or when the job finished and was deleted since. The job is not
+// updated once created, since the operators do not support it.
+func (c *ExperimentController) reconcileExperimentJob(experiment *v1alpha1.SubmarineExperiment) (*unstructured.Unstructured, error) {
+	namespace := experiment.Namespace
+	desired, err := newExperimentJob(experiment)
+	if err != nil {
+		c.setExperimentSubmitted(experiment, metav1.ConditionFalse, InvalidSpecReason, err.Error())
+		return nil, nil
+	}
+	jobType := experimentJobs[experiment.Spec.Framework]
+	experiment.Status.JobKind = jobType.kind
+	experiment.Status.JobName = desired.GetName()
+
+	client := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(jobType.resource)).Namespace(namespace)
+	job, job_err := client.Get(context.TODO(), desired.GetName(), metav1.GetOptions{})
+	// A finished job deleted by the cleanup of the Submarine is not run again
+	if errors.IsNotFound(job_err) && experimentFinished(experiment) {
+		return nil, nil
+	}
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(job_err) {
+		submarine, err := c.submarinesLister.Submarines(namespace).Get(experiment.Spec.Submarine)
+		if errors.IsNotFound(err) {
+			c.setExperimentSubmitted(experiment, metav1.ConditionFalse, SubmarineNotFoundReason, fmt.Sprintf("Submarine %q does not exist", experiment.Spec.Submarine))
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		enabled := false
+		for _, operator := range enabledOperators(submarine) {
+			enabled = enabled || operator.name == jobType.operator
+		}
+		if !enabled {
+			c.setExperimentSubmitted(experiment, metav1.ConditionFalse, OperatorNotEnabledReason, fmt.Sprintf("Submarine %q does not enable the %s operator", submarine.Name, jobType.operator))
+			return nil, nil
+		}
+
+		job, job_err = client.Create(context.TODO(), desired, metav1.CreateOptions{})
+		if job_err != nil {
+			klog.Info(job_err)
+		} else {
+			c.recorder.Eventf(experiment, corev1.EventTypeNormal, JobCreatedReason, MessageJobCreated, jobType.kind, desired.GetName())
+		}
+		klog.Info("	Create ", jobType.kind, ": ", desired.GetName())
+	}
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later, e.g. once the operator installed its
+	// CRD
+	if job_err != nil {
+		return nil, job_err
+	}
+	if !metav1.IsControlledBy(job, experiment) {
+		msg := fmt.Sprintf(MessageResourceExists, job.GetName())
+		c.recorder.Event(experiment, corev1.EventTypeWarning, ErrResourceExists, msg)
+		return nil, fmt.Errorf(msg)
+	}
+
+	c.setExperimentSubmitted(experiment, metav1.ConditionTrue, JobCreatedReason, fmt.Sprintf("%s %s is created", jobType.kind, job.GetName()))
+	return job, nil
+}
+
+// jobPhase returns the phase of a TFJob, PyTorchJob, MPIJob or XGBoostJob,
+// i.e. the type of its latest true condition, and when it was entered
+func jobPhase(job *unstructured.Unstructured) (v1alpha1.ExperimentPhase, *metav1.Time) {
+	phase, since := v1alpha1.ExperimentCreated, job.GetCreationTimestamp()
+	conditions, _, _ := unstructured.NestedSlice(job.Object, "status", "conditions")
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if !ok || condition["status"] != string(corev1.ConditionTrue) {
+			continue
+		}
+		if conditionType, ok := condition["type"].(string); ok {
+			phase = v1alpha1.ExperimentPhase(conditionType)
+			since = job.GetCreationTimestamp()
+			if t := nestedTime(&unstructured.Unstructured{Object: condition}, "lastTransitionTime"); t != nil {
+				since = *t
+			}
+		}
+	}
+	return phase, &since
+}
+
+// mirrorJobStatus copies the phase, the start and completion times and the
+// pods of a job into the status of its SubmarineExperiment
+func (c *ExperimentController) mirrorJobStatus(experiment *v1alpha1.SubmarineExperiment, job *unstructured.Unstructured) error {
+	experiment.Status.Phase, _ = jobPhase(job)
+	experiment.Status.StartTime = nestedTime(job, "status", "startTime")
+	experiment.Status.CompletionTime = nestedTime(job, "status", "completionTime")
+
+	pods, err := c.podLister.Pods(experiment.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	experiment.Status.Pods = nil
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(pod); owner == nil || owner.UID != job.GetUID() {
+			continue
+		}
+		experiment.Status.Pods = append(experiment.Status.Pods, experimentPodStatus(pod))
+	}
+	sort.Slice(experiment.Status.Pods, func(i, j int) bool {
+		return experiment.Status.Pods[i].Name < experiment.Status.Pods[j].Name
+	})
+	return nil
+}
+
+// experimentFinished tells whether the job of an experiment succeeded or
+// failed
+func experimentFinished(experiment *v1alpha1.SubmarineExperiment) bool {
+	return experiment.Status.Phase == v1alpha1.ExperimentSucceeded || experiment.Status.Phase == v1alpha1.ExperimentFailed
+}
+
+// nestedTime returns the RFC 3339 time at fields of obj, or nil
+func nestedTime(obj *unstructured.Unstructured, fields ...string) *metav1.Time {
+	value, found, err := unstructured.NestedString(obj.Object, fields...)
+	if !found || err != nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+// experimentPodStatus returns the state of a pod of a job
+func experimentPodStatus(pod *corev1.Pod) v1alpha1.SubmarineExperimentPodStatus {
+	status := v1alpha1.SubmarineExperimentPodStatus{
+		Name:  pod.Name,
+		Phase: pod.Status.Phase,
+	}
+	for _, label := range replicaTypeLabels {
+		if replicaType, ok := pod.Labels[label]; ok {
+			status.ReplicaType = replicaType
+			break
+		}
+	}
+	for _, container := range pod.Status.ContainerStatuses {
+		status.Restarts += container.RestartCount
+		if status.Reason != "" {
+			continue
+		}
+		if container.State.Waiting != nil {
+			status.Reason = container.State.Waiting.Reason
+		} else if container.State.Terminated != nil {
+			status.Reason = container.State.Terminated.Reason
+		}
+	}
+	return status
+}
+
+func (c *ExperimentController) setExperimentSubmitted(experiment *v1alpha1.SubmarineExperiment, status metav1.ConditionStatus, reason string, message string) {
+	if status == metav1.ConditionFalse {
+		experiment.Status.Phase = v1alpha1.ExperimentPending
+	}
+	meta.SetStatusCondition(&experiment.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SubmarineExperimentSubmitted,
+		Status:             status,
+		ObservedGeneration: experiment.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateExperimentStatus writes the status of a SubmarineExperiment if it
+// changed
+func (c *ExperimentController) updateExperimentStatus(experiment *v1alpha1.SubmarineExperiment) error {
+	current, err := c.experimentsLister.SubmarineExperiments(experiment.Namespace).Get(experiment.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(current.Status, experiment.Status) {
+		return nil
+	}
+	_, err = c.submarineclientset.SubmarineV1alpha1().SubmarineExperiments(experiment.Namespace).Update(context.TODO(), experiment, metav1.UpdateOptions{})
+	return err
+}
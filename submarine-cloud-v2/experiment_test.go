@@ -0,0 +1,204 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	submarinefake "submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// newExperimentTestController returns a controller whose listers hold objs
+func newExperimentTestController(objs ...metav1.Object) (*ExperimentController, *dynamicfake.FakeDynamicClient) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		indexer.Add(obj)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	return &ExperimentController{
+		kubeclientset:      kubefake.NewSimpleClientset(),
+		submarineclientset: submarinefake.NewSimpleClientset(),
+		dynamicclientset:   dynamicClient,
+		experimentsLister:  listers.NewSubmarineExperimentLister(indexer),
+		submarinesLister:   listers.NewSubmarineLister(indexer),
+		podLister:          corelisters.NewPodLister(indexer),
+		recorder:           record.NewFakeRecorder(100),
+	}, dynamicClient
+}
+
+func newTestExperiment() *v1alpha1.SubmarineExperiment {
+	workers := int32(2)
+	return &v1alpha1.SubmarineExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf-mnist", Namespace: "submarine-user-test", UID: "experiment-uid"},
+		Spec: v1alpha1.SubmarineExperimentSpec{
+			Submarine: "example-submarine",
+			Framework: v1alpha1.ExperimentFrameworkTensorFlow,
+			Image:     "apache/submarine:tf-mnist-with-summaries-1.0",
+			Command:   []string{"python", "/var/tf_mnist/mnist_with_summaries.py"},
+			Env:       []corev1.EnvVar{{Name: "ENV_1", Value: "ENV1"}},
+			Replicas: map[string]v1alpha1.SubmarineExperimentReplicaSpec{
+				"Ps": {Image: "apache/submarine:ps"},
+				"Worker": {
+					Replicas: &workers,
+					Env:      []corev1.EnvVar{{Name: "ENV_2", Value: "ENV2"}},
+				},
+			},
+		},
+	}
+}
+
+func TestNewExperimentJob(t *testing.T) {
+	job, err := newExperimentJob(newTestExperiment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.GetKind() != "TFJob" || job.GetName() != "tf-mnist" {
+		t.Errorf("expected TFJob tf-mnist, got %s %s", job.GetKind(), job.GetName())
+	}
+
+	replicas, _, _ := unstructured.NestedInt64(job.Object, "spec", "tfReplicaSpecs", "Worker", "replicas")
+	if replicas != 2 {
+		t.Errorf("Worker replicas = %d, want 2", replicas)
+	}
+	containers, _, _ := unstructured.NestedSlice(job.Object, "spec", "tfReplicaSpecs", "Worker", "template", "spec", "containers")
+	worker := containers[0].(map[string]interface{})
+	if worker["name"] != "tensorflow" || worker["image"] != "apache/submarine:tf-mnist-with-summaries-1.0" {
+		t.Errorf("unexpected Worker container %v", worker)
+	}
+	if env := worker["env"].([]interface{}); len(env) != 2 {
+		t.Errorf("expected the environments of the experiment and of the replica, got %v", env)
+	}
+	containers, _, _ = unstructured.NestedSlice(job.Object, "spec", "tfReplicaSpecs", "Ps", "template", "spec", "containers")
+	if ps := containers[0].(map[string]interface{}); ps["image"] != "apache/submarine:ps" {
+		t.Errorf("expected the image of the replica, got %v", ps["image"])
+	}
+
+	// Invalid specs
+	experiment := newTestExperiment()
+	experiment.Spec.Framework = "MXNet"
+	if _, err := newExperimentJob(experiment); err == nil {
+		t.Error("expected an error for an unknown framework")
+	}
+	experiment = newTestExperiment()
+	experiment.Spec.Image = ""
+	if _, err := newExperimentJob(experiment); err == nil {
+		t.Error("expected an error for a replica without image")
+	}
+}
+
+func TestReconcileExperimentJob(t *testing.T) {
+	// The TFJob operator is not enabled: the job is not created
+	submarine := newTestSubmarine()
+	submarine.Spec.Operators = []v1alpha1.SubmarineOperator{v1alpha1.OperatorPyTorchJob}
+	experiment := newTestExperiment()
+	c, dynamicClient := newExperimentTestController(submarine, experiment)
+	job, err := c.reconcileExperimentJob(experiment)
+	if err != nil || job != nil {
+		t.Fatalf("expected no job, got %v, %v", job, err)
+	}
+	condition := meta.FindStatusCondition(experiment.Status.Conditions, v1alpha1.SubmarineExperimentSubmitted)
+	if condition == nil || condition.Reason != OperatorNotEnabledReason {
+		t.Errorf("expected the Submitted condition with reason %s, got %+v", OperatorNotEnabledReason, condition)
+	}
+	if experiment.Status.Phase != v1alpha1.ExperimentPending {
+		t.Errorf("phase = %s, want Pending", experiment.Status.Phase)
+	}
+
+	// With the default operators: the job is created
+	submarine = newTestSubmarine()
+	experiment = newTestExperiment()
+	c, dynamicClient = newExperimentTestController(submarine, experiment)
+	job, err = c.reconcileExperimentJob(experiment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test").Get(context.TODO(), "tf-mnist", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the TFJob to be created, got %v", err)
+	}
+	if !meta.IsStatusConditionTrue(experiment.Status.Conditions, v1alpha1.SubmarineExperimentSubmitted) {
+		t.Errorf("expected the Submitted condition to be true, got %+v", experiment.Status.Conditions)
+	}
+
+	// A finished job deleted by the cleanup is not created again
+	finished := newTestExperiment()
+	finished.Status.Phase = v1alpha1.ExperimentSucceeded
+	c, dynamicClient = newExperimentTestController(submarine, finished)
+	if job, err := c.reconcileExperimentJob(finished); err != nil || job != nil {
+		t.Fatalf("expected no job, got %v, %v", job, err)
+	}
+	if len(dynamicClient.Actions()) != 1 {
+		t.Errorf("expected only a get, got %v", dynamicClient.Actions())
+	}
+
+	// The status of the job and of its pods are mirrored
+	job.SetUID("job-uid")
+	unstructured.SetNestedSlice(job.Object, []interface{}{
+		map[string]interface{}{"type": "Created", "status": "True"},
+		map[string]interface{}{"type": "Running", "status": "True"},
+	}, "status", "conditions")
+	unstructured.SetNestedField(job.Object, "2021-05-01T12:00:00Z", "status", "startTime")
+	controller := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tf-mnist-worker-0",
+			Namespace: "submarine-user-test",
+			Labels:    map[string]string{"replica-type": "worker"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "kubeflow.org/v1", Kind: "TFJob", Name: "tf-mnist", UID: types.UID("job-uid"), Controller: &controller},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			},
+		},
+	}
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "submarine-user-test"}}
+	c, _ = newExperimentTestController(pod, other)
+	if err := c.mirrorJobStatus(experiment, job); err != nil {
+		t.Fatal(err)
+	}
+	if experiment.Status.Phase != v1alpha1.ExperimentRunning {
+		t.Errorf("phase = %s, want Running", experiment.Status.Phase)
+	}
+	if experiment.Status.StartTime == nil || experiment.Status.CompletionTime != nil {
+		t.Errorf("unexpected start and completion times %v, %v", experiment.Status.StartTime, experiment.Status.CompletionTime)
+	}
+	want := []v1alpha1.SubmarineExperimentPodStatus{
+		{Name: "tf-mnist-worker-0", ReplicaType: "worker", Phase: corev1.PodPending, Reason: "ImagePullBackOff"},
+	}
+	if len(experiment.Status.Pods) != 1 || experiment.Status.Pods[0] != want[0] {
+		t.Errorf("pods = %+v, want %+v", experiment.Status.Pods, want)
+	}
+}
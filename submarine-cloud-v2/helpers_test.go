@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	submarinefake "submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	submarineinformers "submarine-cloud-v2/pkg/generated/informers/externalversions"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// testControllerOptions are the objects held by the API server and the
+// listers of a test controller
+type testControllerOptions struct {
+	// submarines are held by the Submarine clientset and lister
+	submarines []*v1alpha1.Submarine
+	// objects are held by the clientset and the lister of their kind.
+	// Unstructured objects, e.g. the jobs of the training operators, are
+	// held by the dynamic clientset.
+	objects []runtime.Object
+	// apiResources are the resources the discovery of the API server
+	// reports
+	apiResources []*metav1.APIResourceList
+}
+
+// testListKinds are the kinds of the lists of the resources the dynamic
+// clientset is used for
+var testListKinds = map[schema.GroupVersionResource]string{
+	kubeflowGroupVersion.WithResource("tfjobs"):      "TFJobList",
+	kubeflowGroupVersion.WithResource("pytorchjobs"): "PyTorchJobList",
+	kubeflowGroupVersion.WithResource("mpijobs"):     "MPIJobList",
+	kubeflowGroupVersion.WithResource("xgboostjobs"): "XGBoostJobList",
+	kubeflowGroupVersion.WithResource("notebooks"):   "NotebookList",
+	serviceMonitorResource:                           "ServiceMonitorList",
+	podMonitorResource:                               "PodMonitorList",
+}
+
+// newTestController returns a controller whose clientsets and listers hold
+// the objects of opts. The listers are not synced with the clientsets: they
+// keep the initial objects, so that the tests decide what the controller
+// sees.
+func newTestController(t *testing.T, opts testControllerOptions) (*Controller, *kubefake.Clientset, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+
+	var objects, unstructuredObjects []runtime.Object
+	for _, obj := range opts.objects {
+		if _, ok := obj.(*unstructured.Unstructured); ok {
+			unstructuredObjects = append(unstructuredObjects, obj)
+		} else {
+			objects = append(objects, obj)
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(objects...)
+	kubeClient.Resources = opts.apiResources
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), testListKinds, unstructuredObjects...)
+	submarineClient := submarinefake.NewSimpleClientset()
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	for _, obj := range objects {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resource, _ := meta.UnsafeGuessKindToResource(gvks[0])
+		informer, err := kubeInformerFactory.ForResource(resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+		informer.Informer().GetIndexer().Add(obj)
+	}
+	submarineInformerFactory := submarineinformers.NewSharedInformerFactory(submarineClient, 0)
+	submarineInformer := submarineInformerFactory.Submarine().V1alpha1().Submarines()
+	for _, submarine := range opts.submarines {
+		submarineClient.Tracker().Add(submarine)
+		submarineInformer.Informer().GetIndexer().Add(submarine)
+	}
+
+	return &Controller{
+		kubeclientset:               kubeClient,
+		submarineclientset:          submarineClient,
+		dynamicclientset:            dynamicClient,
+		submarinesLister:            submarineInformer.Lister(),
+		namespaceLister:             kubeInformerFactory.Core().V1().Namespaces().Lister(),
+		deploymentLister:            kubeInformerFactory.Apps().V1().Deployments().Lister(),
+		serviceaccountLister:        kubeInformerFactory.Core().V1().ServiceAccounts().Lister(),
+		serviceLister:               kubeInformerFactory.Core().V1().Services().Lister(),
+		persistentvolumeLister:      kubeInformerFactory.Core().V1().PersistentVolumes().Lister(),
+		persistentvolumeclaimLister: kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister(),
+		ingressLister:               kubeInformerFactory.Extensions().V1beta1().Ingresses().Lister(),
+		clusterroleLister:           kubeInformerFactory.Rbac().V1().ClusterRoles().Lister(),
+		clusterrolebindingLister:    kubeInformerFactory.Rbac().V1().ClusterRoleBindings().Lister(),
+		hpaLister:                   kubeInformerFactory.Autoscaling().V2beta2().HorizontalPodAutoscalers().Lister(),
+		pdbLister:                   kubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets().Lister(),
+		configmapLister:             kubeInformerFactory.Core().V1().ConfigMaps().Lister(),
+		jobLister:                   kubeInformerFactory.Batch().V1().Jobs().Lister(),
+		daemonsetLister:             kubeInformerFactory.Apps().V1().DaemonSets().Lister(),
+		podLister:                   kubeInformerFactory.Core().V1().Pods().Lister(),
+		workqueue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Submarines"),
+		recorder:                    record.NewFakeRecorder(100),
+		chartOperations:             map[string]*chartOperation{},
+		environmentImagesEntries:    map[string]*environmentImagesEntry{},
+	}, kubeClient, dynamicClient
+}
+
+// newTestSubmarine returns a Submarine running version 0.6.0
+func newTestSubmarine() *v1alpha1.Submarine {
+	return &v1alpha1.Submarine{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-submarine", Namespace: "submarine-user-test", UID: "uid"},
+		Spec: v1alpha1.SubmarineSpec{
+			Version:  "0.6.0",
+			Database: &v1alpha1.SubmarineDatabase{},
+		},
+		Status: v1alpha1.SubmarineStatus{Version: "0.6.0"},
+	}
+}
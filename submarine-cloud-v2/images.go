@@ -0,0 +1,274 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Default images of the components. The images of the subcharts must match
+// the values.yaml of the charts.
+const (
+	defaultTensorboardImage        = "tensorflow/tensorflow:1.11.0"
+	defaultTraefikImage            = "traefik:2.2.8"
+	defaultNotebookControllerImage = "apache/submarine:notebook-controller-v1.1.0-g253890cb"
+	defaultTFJobImage              = "apache/submarine:tf_operator-v1.1.0-g92389064"
+	defaultPyTorchJobImage         = "apache/submarine:pytorch-operator-v1.1.0-gd596e904"
+	defaultMPIJobImage             = "mpioperator/mpi-operator:0.3.0"
+	defaultKubectlDeliveryImage    = "mpioperator/kubectl-delivery:0.3.0"
+	defaultXGBoostJobImage         = "kubeflow/xgboost-operator:v0.2.0"
+	// Exporter sidecars of spec.monitoring
+	defaultJMXExporterImage   = "bitnami/jmx-exporter:0.15.0"
+	defaultMySQLExporterImage = "prom/mysqld-exporter:v0.12.1"
+	// Idle container of the image pre-puller of spec.prePull
+	defaultPauseImage = "k8s.gcr.io/pause:3.2"
+	// Static busybox providing the no-op command of the image pre-puller
+	defaultPrePullNoopImage = "busybox:1.33"
+	// Receiver of the logs archived into a volume by spec.logArchive
+	defaultLogArchiverImage = "busybox:1.33"
+)
+
+// withRegistry replaces the registry of an image with registry. Images of
+// Docker Hub, e.g. "traefik:2.2.8", get the registry as a prefix.
+func withRegistry(registry string, image string) string {
+	if registry == "" {
+		return image
+	}
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		image = parts[1]
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+// resolveImage returns the override if it is set, otherwise the default
+// image in the registry of the Submarine
+func resolveImage(submarine *v1alpha1.Submarine, override string, defaultImage string) string {
+	if override != "" {
+		return override
+	}
+	return withRegistry(submarine.Spec.ImageRegistry, defaultImage)
+}
+
+func serverImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, submarine.Spec.Server.Image, "apache/submarine:server-"+submarine.Spec.Version)
+}
+
+func databaseImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, submarine.Spec.Database.Image, "apache/submarine:database-"+submarine.Spec.Version)
+}
+
+func tensorboardImage(submarine *v1alpha1.Submarine) string {
+	override := ""
+	if submarine.Spec.Tensorboard != nil {
+		override = submarine.Spec.Tensorboard.Image
+	}
+	return resolveImage(submarine, override, defaultTensorboardImage)
+}
+
+func prePullPauseImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, "", defaultPauseImage)
+}
+
+func prePullNoopImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, "", defaultPrePullNoopImage)
+}
+
+func logArchiverImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, "", defaultLogArchiverImage)
+}
+
+func serverExporterImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, submarine.Spec.Monitoring.ServerExporterImage, defaultJMXExporterImage)
+}
+
+func databaseExporterImage(submarine *v1alpha1.Submarine) string {
+	return resolveImage(submarine, submarine.Spec.Monitoring.DatabaseExporterImage, defaultMySQLExporterImage)
+}
+
+// subChartImage returns the image of a subchart
+func subChartImage(submarine *v1alpha1.Submarine, name string) string {
+	images := submarine.Spec.Images
+	if images == nil {
+		images = &v1alpha1.SubmarineImages{}
+	}
+	switch name {
+	case "traefik":
+		return resolveImage(submarine, images.Traefik, defaultTraefikImage)
+	case "notebook-controller":
+		return resolveImage(submarine, images.NotebookController, defaultNotebookControllerImage)
+	case "tfjob":
+		return resolveImage(submarine, images.TFJob, defaultTFJobImage)
+	case "pytorchjob":
+		return resolveImage(submarine, images.PyTorchJob, defaultPyTorchJobImage)
+	case "mpijob":
+		return resolveImage(submarine, images.MPIJob, defaultMPIJobImage)
+	case "xgboostjob":
+		return resolveImage(submarine, images.XGBoostJob, defaultXGBoostJobImage)
+	}
+	return ""
+}
+
+// splitImageTag splits an image into its name and tag
+func splitImageTag(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, "latest"
+	}
+	return image[:i], image[i+1:]
+}
+
+// subChartValues returns the arguments of the Helm install of a subchart,
+// which pass the image and the pull secrets of the Submarine as --set values
+func subChartValues(submarine *v1alpha1.Submarine, name string) map[string]string {
+	var values []string
+	secretsKey := "imagePullSecrets"
+	image := subChartImage(submarine, name)
+	switch name {
+	case "traefik":
+		// The traefik chart takes the name and the tag separately
+		imageName, imageTag := splitImageTag(image)
+		values = append(values, "image.name="+imageName, "image.tag="+imageTag)
+		secretsKey = "deployment.imagePullSecrets"
+		if monitoringEnabled(submarine) {
+			// A metrics entrypoint, which the service does not expose
+			values = append(values,
+				fmt.Sprintf("ports.metrics.port=%d", traefikMetricsPort),
+				"ports.metrics.expose=false",
+				"additionalArguments={--metrics.prometheus=true,--metrics.prometheus.entryPoint=metrics}")
+		}
+	case "mpijob":
+		// The launcher pods of the MPIJobs pull the kubectl delivery image
+		values = append(values, "image="+image, "kubectlDeliveryImage="+withRegistry(submarine.Spec.ImageRegistry, defaultKubectlDeliveryImage))
+	default:
+		values = append(values, "image="+image)
+	}
+	for i, secret := range submarine.Spec.ImagePullSecrets {
+		values = append(values, fmt.Sprintf("%s[%d].name=%s", secretsKey, i, secret.Name))
+	}
+	return map[string]string{"set": strings.Join(values, ",")}
+}
+
+// runImages implements "submarine-operator images". It prints every image a
+// Submarine would pull, so that they can be mirrored ahead of time.
+func runImages(args []string) error {
+	fs := flag.NewFlagSet("images", flag.ExitOnError)
+	filename := fs.String("f", "", "Path to the Submarine resource, or - for stdin")
+	namespace := fs.String("n", "default", "Namespace of the Submarine if the resource does not specify one")
+	chartsDir := fs.String("charts", "", "Directory containing the subcharts, instead of the charts embedded in the binary")
+	overrides := chartOverrides{}
+	fs.Var(overrides, "chart", "Source of a subchart as NAME=SOURCE, i.e. a directory or REPO_URL/CHART[@VERSION], can be repeated")
+	fs.Parse(args)
+
+	if *filename == "" {
+		fs.Usage()
+		return fmt.Errorf("missing -f")
+	}
+	sources := localChartOverrides(*chartsDir)
+	for name, source := range overrides {
+		sources[name] = source
+	}
+
+	submarine, err := readSubmarine(*filename)
+	if err != nil {
+		return err
+	}
+	if submarine.Namespace == "" {
+		submarine.Namespace = *namespace
+	}
+
+	images, err := listImages(submarine, sources)
+	if err != nil {
+		return err
+	}
+	return printImages(images, os.Stdout)
+}
+
+// listImages renders the manifests of a Submarine and returns the sorted
+// images of every container and init container in them
+func listImages(submarine *v1alpha1.Submarine, sources chartOverrides) ([]string, error) {
+	var manifests bytes.Buffer
+	if err := renderSubmarine(submarine, sources, &manifests); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, document := range strings.Split(manifests.String(), "\n---") {
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, err
+		}
+		collectImages(object, seen)
+	}
+	// The kubectl delivery image is an argument of the MPI operator
+	for _, operator := range enabledOperators(submarine) {
+		if operator.name == v1alpha1.OperatorMPIJob {
+			seen[withRegistry(submarine.Spec.ImageRegistry, defaultKubectlDeliveryImage)] = true
+		}
+	}
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// collectImages adds the images of the containers found anywhere in an
+// object, so that every kind of workload is covered
+func collectImages(value interface{}, images map[string]bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if containers, ok := field.([]interface{}); ok && (key == "containers" || key == "initContainers") {
+				for _, container := range containers {
+					if container, ok := container.(map[string]interface{}); ok {
+						if image, ok := container["image"].(string); ok && image != "" {
+							images[image] = true
+						}
+					}
+				}
+			}
+			collectImages(field, images)
+		}
+	case []interface{}:
+		for _, item := range value {
+			collectImages(item, images)
+		}
+	}
+}
+
+func printImages(images []string, out io.Writer) error {
+	for _, image := range images {
+		if _, err := fmt.Fprintln(out, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
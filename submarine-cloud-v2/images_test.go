@@ -0,0 +1,63 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithRegistry(t *testing.T) {
+	for _, test := range []struct {
+		registry, image, expected string
+	}{
+		{"", "traefik:2.2.8", "traefik:2.2.8"},
+		{"mirror.local", "traefik:2.2.8", "mirror.local/traefik:2.2.8"},
+		{"mirror.local/", "apache/submarine:server-0.6.0", "mirror.local/apache/submarine:server-0.6.0"},
+		{"mirror.local:5000/team", "quay.io/org/image:1.0", "mirror.local:5000/team/org/image:1.0"},
+		{"mirror.local", "localhost/image:1.0", "mirror.local/image:1.0"},
+	} {
+		if actual := withRegistry(test.registry, test.image); actual != test.expected {
+			t.Errorf("withRegistry(%q, %q) = %q, expected %q", test.registry, test.image, actual, test.expected)
+		}
+	}
+}
+
+func TestListImages(t *testing.T) {
+	submarine, err := readSubmarine("testdata/render/airgap.input.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, err := listImages(submarine, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"registry.example.com:5000/kubeflow/tf-operator:v1.1.0",
+		"registry.example.com:5000/mirror/apache/submarine:notebook-controller-v1.1.0-g253890cb",
+		"registry.example.com:5000/mirror/apache/submarine:pytorch-operator-v1.1.0-gd596e904",
+		"registry.example.com:5000/mirror/apache/submarine:server-0.6.0-SNAPSHOT",
+		"registry.example.com:5000/mirror/tensorflow/tensorflow:1.11.0",
+		"registry.example.com:5000/mirror/traefik:2.2.8",
+		"registry.example.com:5000/submarine/database:0.6.0-patched",
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("listImages() = %q, expected %q", images, expected)
+	}
+}
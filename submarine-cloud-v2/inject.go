@@ -0,0 +1,218 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// injectStoresLabel opts the pods of a job or notebook in to the
+	// injection of the tensorboard and mlflow stores
+	injectStoresLabel = "submarine.k8s.io/inject-stores"
+	// injectSubmarineAnnotation names the Submarine whose stores are
+	// injected, when the namespace has several
+	injectSubmarineAnnotation = "submarine.k8s.io/submarine"
+	// injectLogDirAnnotation overrides the directory of the containers where
+	// the tensorboard logs are mounted
+	injectLogDirAnnotation = "submarine.k8s.io/tensorboard-log-dir"
+	// notebookNameLabel is set by the notebook controller on notebook pods
+	notebookNameLabel = "notebook-name"
+)
+
+const (
+	// tensorboardLogsVolume is the volume of the tensorboard logs in
+	// injected pods
+	tensorboardLogsVolume = "submarine-tensorboard-logs"
+	// defaultTensorboardLogDir is the directory of the tensorboard logs in
+	// injected containers
+	defaultTensorboardLogDir = "/logs"
+)
+
+// mlflowServiceName is the Service of mlflow created by the Helm chart. The
+// operator does not create mlflow.
+const mlflowServiceName = "submarine-mlflow-service"
+
+// mlflowEnabled tells whether the Submarine has mlflow
+func mlflowEnabled(submarine *v1alpha1.Submarine) bool {
+	return submarine.Spec.Mlflow == nil || submarine.Spec.Mlflow.Enabled == nil || *submarine.Spec.Mlflow.Enabled
+}
+
+// injectedWorkload returns the kind and the name of the TFJob, PyTorchJob or
+// Notebook running a pod, or "" for other pods
+func injectedWorkload(pod *corev1.Pod) (string, string) {
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		for _, jobType := range queuedJobTypes {
+			if owner.Kind == jobType.kind {
+				return jobType.kind, owner.Name
+			}
+		}
+	}
+	if name := pod.Labels[notebookNameLabel]; name != "" {
+		return "Notebook", name
+	}
+	return "", ""
+}
+
+// injectedSubmarine returns the Submarine whose stores are injected into a
+// pod of the namespace, or nil when there is none or it is ambiguous
+func (c *Controller) injectedSubmarine(pod *corev1.Pod, namespace string) (*v1alpha1.Submarine, error) {
+	if name := pod.Annotations[injectSubmarineAnnotation]; name != "" {
+		submarine, err := c.submarinesLister.Submarines(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return submarine, nil
+	}
+	submarines, err := c.submarinesLister.Submarines(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	if len(submarines) != 1 {
+		return nil, fmt.Errorf("%d Submarines in namespace %s, set the %s annotation", len(submarines), namespace, injectSubmarineAnnotation)
+	}
+	return submarines[0], nil
+}
+
+// injectStores mounts the tensorboard logs of the Submarine of the namespace
+// into the containers of the opted-in pods of TFJobs, PyTorchJobs and
+// Notebooks, at a subdirectory named after the workload, and points them at
+// the server and, when its Service exists, mlflow. Pods are never denied: they are created unchanged
+// when the stores cannot be injected.
+func (c *Controller) injectStores(request *admissionv1.AdmissionRequest) ([]jsonPatchOperation, error) {
+	if request.Resource.Resource != "pods" || request.Operation != admissionv1.Create {
+		return nil, nil
+	}
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(request.Object.Raw, pod); err != nil {
+		return nil, err
+	}
+	if inject, _ := strconv.ParseBool(pod.Labels[injectStoresLabel]); !inject {
+		return nil, nil
+	}
+	kind, name := injectedWorkload(pod)
+	if kind == "" {
+		return nil, nil
+	}
+	submarine, err := c.injectedSubmarine(pod, request.Namespace)
+	if err != nil {
+		klog.Info("Skip injecting the stores into a pod of ", kind, " ", request.Namespace, "/", name, ": ", err)
+		return nil, nil
+	}
+
+	logDir := defaultTensorboardLogDir
+	if dir := pod.Annotations[injectLogDirAnnotation]; path.IsAbs(dir) {
+		logDir = path.Clean(dir)
+	}
+	env := []corev1.EnvVar{
+		{Name: "SUBMARINE_SERVER", Value: fmt.Sprintf("http://%s.%s:8080", serverName(submarine), submarine.Namespace)},
+		{Name: "TENSORBOARD_LOG_DIR", Value: logDir},
+	}
+	// mlflow is only pointed at when its Service exists in the namespace
+	if _, err := c.serviceLister.Services(submarine.Namespace).Get(mlflowServiceName); err == nil && mlflowEnabled(submarine) {
+		env = append(env, corev1.EnvVar{
+			Name:  "MLFLOW_TRACKING_URI",
+			Value: fmt.Sprintf("http://%s.%s:5000", mlflowServiceName, submarine.Namespace),
+		})
+	}
+	// The logs of the workload are viewed with a SubmarineTensorboard whose
+	// logPath is the name of the workload
+	mount := corev1.VolumeMount{
+		Name:      tensorboardLogsVolume,
+		MountPath: logDir,
+		SubPath:   path.Join(tensorboardName(submarine), name),
+	}
+	volume := corev1.Volume{
+		Name: tensorboardLogsVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: tensorboardName(submarine) + "-pvc",
+			},
+		},
+	}
+
+	var patch []jsonPatchOperation
+	patch = appendPatch(patch, "/spec/volumes", len(pod.Spec.Volumes) == 0, []interface{}{volume}, hasVolume(pod, tensorboardLogsVolume))
+	for i, container := range pod.Spec.Containers {
+		prefix := fmt.Sprintf("/spec/containers/%d", i)
+		var missing []interface{}
+		for _, envVar := range env {
+			if !hasEnv(container, envVar.Name) {
+				missing = append(missing, envVar)
+			}
+		}
+		patch = appendPatch(patch, prefix+"/env", len(container.Env) == 0, missing, false)
+		patch = appendPatch(patch, prefix+"/volumeMounts", len(container.VolumeMounts) == 0, []interface{}{mount}, hasMountPath(container, logDir))
+	}
+
+	klog.Info("	Inject stores into a pod of ", kind, ": ", request.Namespace, "/", name)
+	return patch, nil
+}
+
+// appendPatch appends the operations adding values to the list at listPath,
+// creating the list when empty, unless skip
+func appendPatch(patch []jsonPatchOperation, listPath string, empty bool, values []interface{}, skip bool) []jsonPatchOperation {
+	if skip || len(values) == 0 {
+		return patch
+	}
+	if empty {
+		return append(patch, jsonPatchOperation{Op: "add", Path: listPath, Value: values})
+	}
+	for _, value := range values {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: listPath + "/-", Value: value})
+	}
+	return patch
+}
+
+func hasVolume(pod *corev1.Pod, name string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnv(container corev1.Container, name string) bool {
+	for _, envVar := range container.Env {
+		if envVar.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMountPath(container corev1.Container, mountPath string) bool {
+	for _, mount := range container.VolumeMounts {
+		if path.Clean(mount.MountPath) == mountPath {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newInjectTestRequest(t *testing.T, pod *corev1.Pod) *admissionv1.AdmissionRequest {
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &admissionv1.AdmissionRequest{
+		Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+		Namespace: "submarine-user-test",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func newInjectTestPod(labels map[string]string, owner *metav1.OwnerReference) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "tensorflow",
+					Env:  []corev1.EnvVar{{Name: "SUBMARINE_SERVER", Value: "http://custom:8080"}},
+				},
+			},
+		},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func TestInjectStores(t *testing.T) {
+	controller := true
+	owner := &metav1.OwnerReference{APIVersion: "kubeflow.org/v1", Kind: "TFJob", Name: "mnist", UID: "mnist", Controller: &controller}
+	mlflowService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: mlflowServiceName, Namespace: "submarine-user-test"}}
+	c, _, _ := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{newTestSubmarine()},
+		objects:    []runtime.Object{mlflowService},
+	})
+
+	// Not opted in, or not a workload: not patched
+	for _, pod := range []*corev1.Pod{
+		newInjectTestPod(nil, owner),
+		newInjectTestPod(map[string]string{injectStoresLabel: "true"}, nil),
+	} {
+		if patch, err := c.injectStores(newInjectTestRequest(t, pod)); err != nil || patch != nil {
+			t.Fatalf("expected no patch, got %v, %v", patch, err)
+		}
+	}
+
+	pod := newInjectTestPod(map[string]string{injectStoresLabel: "true"}, owner)
+	patch, err := c.injectStores(newInjectTestRequest(t, pod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SUBMARINE_SERVER is kept
+	want := `[` +
+		`{"op":"add","path":"/spec/volumes","value":[{"name":"submarine-tensorboard-logs","persistentVolumeClaim":{"claimName":"example-submarine-tensorboard-pvc"}}]},` +
+		`{"op":"add","path":"/spec/containers/0/env/-","value":{"name":"TENSORBOARD_LOG_DIR","value":"/logs"}},` +
+		`{"op":"add","path":"/spec/containers/0/env/-","value":{"name":"MLFLOW_TRACKING_URI","value":"http://submarine-mlflow-service.submarine-user-test:5000"}},` +
+		`{"op":"add","path":"/spec/containers/0/volumeMounts","value":[{"name":"submarine-tensorboard-logs","mountPath":"/logs","subPath":"example-submarine-tensorboard/mnist"}]}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("patch =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without the Service of mlflow: not pointed at
+	c, _, _ = newTestController(t, testControllerOptions{submarines: []*v1alpha1.Submarine{newTestSubmarine()}})
+	patch, err = c.injectStores(newInjectTestRequest(t, pod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(patch); strings.Contains(string(got), "MLFLOW_TRACKING_URI") {
+		t.Errorf("expected no MLFLOW_TRACKING_URI without the Service, got %s", got)
+	}
+
+	// Notebook, with another log directory and without mlflow
+	disabled := false
+	submarine := newTestSubmarine()
+	submarine.Spec.Mlflow = &v1alpha1.SubmarineMlflow{Enabled: &disabled}
+	c, _, _ = newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects:    []runtime.Object{mlflowService},
+	})
+	pod = newInjectTestPod(map[string]string{injectStoresLabel: "true", notebookNameLabel: "notebook"}, nil)
+	pod.Annotations = map[string]string{injectLogDirAnnotation: "/home/jovyan/logs/"}
+	pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "workspace", MountPath: "/home/jovyan"}}
+	patch, err = c.injectStores(newInjectTestRequest(t, pod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = json.Marshal(patch)
+	want = `[` +
+		`{"op":"add","path":"/spec/volumes","value":[{"name":"submarine-tensorboard-logs","persistentVolumeClaim":{"claimName":"example-submarine-tensorboard-pvc"}}]},` +
+		`{"op":"add","path":"/spec/containers/0/env/-","value":{"name":"TENSORBOARD_LOG_DIR","value":"/home/jovyan/logs"}},` +
+		`{"op":"add","path":"/spec/containers/0/volumeMounts/-","value":{"name":"submarine-tensorboard-logs","mountPath":"/home/jovyan/logs","subPath":"example-submarine-tensorboard/notebook"}}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("patch =\n%s\nwant\n%s", got, want)
+	}
+
+	// Several Submarines without the annotation: not patched
+	other := newTestSubmarine()
+	other.Name = "other"
+	c, _, _ = newTestController(t, testControllerOptions{submarines: []*v1alpha1.Submarine{submarine, other}})
+	if patch, err := c.injectStores(newInjectTestRequest(t, pod)); err != nil || patch != nil {
+		t.Fatalf("expected no patch with several Submarines, got %v, %v", patch, err)
+	}
+	pod.Annotations[injectSubmarineAnnotation] = "other"
+	if patch, err := c.injectStores(newInjectTestRequest(t, pod)); err != nil || len(patch) == 0 {
+		t.Fatalf("expected a patch for the annotated Submarine, got %v, %v", patch, err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// watchJobs starts an informer on the jobs of resource, unless one is
+// already running. The CRDs of the jobs may be installed after the
+// controller started, so the informer is started by the first sync that
+// lists them.
+func (c *Controller) watchJobs(resource string) {
+	if c.jobInformerFactory == nil || c.stopCh == nil {
+		return
+	}
+	c.watchedJobsLock.Lock()
+	defer c.watchedJobsLock.Unlock()
+	if c.watchedJobs[resource] {
+		return
+	}
+	c.watchedJobs[resource] = true
+
+	klog.Info("Watch ", resource)
+	informer := c.jobInformerFactory.ForResource(kubeflowGroupVersion.WithResource(resource)).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleJob,
+		UpdateFunc: func(old, new interface{}) {
+			oldJob, ok := old.(*unstructured.Unstructured)
+			newJob, ok2 := new.(*unstructured.Unstructured)
+			if ok && ok2 {
+				oldPhase, _ := jobPhase(oldJob)
+				newPhase, _ := jobPhase(newJob)
+				// Only a change of phase frees or needs capacity, or
+				// finishes the job
+				if oldPhase == newPhase {
+					return
+				}
+			}
+			c.handleJob(new)
+		},
+		DeleteFunc: c.handleJob,
+	})
+	c.jobInformerFactory.Start(c.stopCh)
+}
+
+// handleJob enqueues the Submarines a job may belong to, as selected by
+// isSubmarineJob: the Submarine of its label, or every Submarine of the
+// namespace for an unlabelled job
+func (c *Controller) handleJob(obj interface{}) {
+	job, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return
+		}
+		job, ok = tombstone.Obj.(*unstructured.Unstructured)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return
+		}
+	}
+	submarines, err := c.submarinesLister.Submarines(job.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, submarine := range submarines {
+		if isSubmarineJob(job, submarine, true) {
+			c.enqueueSubmarine(submarine, UPDATE)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+)
+
+func TestWatchJobs(t *testing.T) {
+	submarine := newTestSubmarine()
+	running := newArchiveTestJob("running", "", v1alpha1.ExperimentRunning)
+	c, _, dynamicClient := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects:    []runtime.Object{running},
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c.jobInformerFactory = dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	c.watchedJobs = map[string]bool{}
+	c.stopCh = stopCh
+
+	c.watchJobs("tfjobs")
+	c.watchJobs("tfjobs")
+	if len(c.watchedJobs) != 1 {
+		t.Errorf("watchedJobs = %v", c.watchedJobs)
+	}
+	// enqueued waits for the Submarine to be enqueued, and dequeues it
+	enqueued := func() bool {
+		err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+			return c.workqueue.Len() > 0, nil
+		})
+		if err != nil {
+			return false
+		}
+		item, _ := c.workqueue.Get()
+		c.workqueue.Done(item)
+		if key := item.(WorkQueueItem).key; key != "submarine-user-test/example-submarine" {
+			t.Errorf("enqueued %s", key)
+		}
+		return true
+	}
+	client := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test")
+	update := func(job *unstructured.Unstructured) {
+		if _, err := client.Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The existing job is added by the informer
+	if !enqueued() {
+		t.Fatal("expected the Submarine to be enqueued for the existing job")
+	}
+
+	// A change that keeps the phase is ignored
+	running.SetAnnotations(map[string]string{"touched": "true"})
+	update(running)
+	if enqueued() {
+		t.Error("expected no sync for a job keeping its phase")
+	}
+
+	// A finished job syncs the Submarine
+	succeeded := newArchiveTestJob("running", "", v1alpha1.ExperimentSucceeded)
+	update(succeeded)
+	if !enqueued() {
+		t.Error("expected the Submarine to be enqueued for the finished job")
+	}
+
+	// The jobs of other Submarines are left to them
+	other := newArchiveTestJob("other", "", v1alpha1.ExperimentRunning)
+	other.SetLabels(map[string]string{submarineNameLabel: "other"})
+	if _, err := client.Create(context.TODO(), other, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if enqueued() {
+		t.Error("expected no sync for the job of another Submarine")
+	}
+}
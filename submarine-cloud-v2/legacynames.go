@@ -0,0 +1,146 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// legacyNamesAnnotation makes the objects of a Submarine keep the fixed
+	// names given by earlier operators, e.g. submarine-server, whatever the
+	// name of the Submarine
+	legacyNamesAnnotation = "submarine.k8s.io/legacy-names"
+	// legacyNamePrefix is the prefix of the legacy names
+	legacyNamePrefix = "submarine"
+)
+
+const (
+	// LegacyNamesKept is used as part of the Event 'reason' when a Submarine
+	// keeps the legacy names of its objects
+	LegacyNamesKept = "LegacyNamesKept"
+	// MessageLegacyNamesKept is the message used for an Event fired when a
+	// Submarine keeps the legacy names of its objects
+	MessageLegacyNamesKept = "Keeping the legacy names %s, %s and %s of the existing objects"
+)
+
+// namePrefix returns the prefix of the names of the objects of a Submarine
+func namePrefix(submarine *v1alpha1.Submarine) string {
+	if submarine.Annotations[legacyNamesAnnotation] == "true" {
+		return legacyNamePrefix
+	}
+	return submarine.Name
+}
+
+// reconcileLegacyNames sets legacyNamesAnnotation on a Submarine that
+// controls objects with the legacy names, i.e. that was created before the
+// names were derived from the Submarine. Renaming its objects would create a
+// second copy of each of them, and orphan the volume of the database.
+// submarine must be a copy, its annotations are updated.
+func (c *Controller) reconcileLegacyNames(submarine *v1alpha1.Submarine) error {
+	if submarine.Name == legacyNamePrefix || submarine.Annotations[legacyNamesAnnotation] == "true" {
+		return nil
+	}
+
+	legacy := submarine.DeepCopy()
+	legacy.Annotations = map[string]string{legacyNamesAnnotation: "true"}
+	owned, err := c.ownsLegacyObjects(submarine, legacy)
+	if err != nil || !owned {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{legacyNamesAnnotation: "true"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	updated, err := c.submarineclientset.SubmarineV1alpha1().Submarines(submarine.Namespace).Patch(context.TODO(), submarine.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	submarine.Annotations = updated.Annotations
+	submarine.ResourceVersion = updated.ResourceVersion
+
+	klog.Info("	Keep legacy names: ", submarine.Namespace, "/", submarine.Name)
+	c.recorder.Event(submarine, corev1.EventTypeNormal, LegacyNamesKept, fmt.Sprintf(MessageLegacyNamesKept, serverName(submarine), databaseName(submarine), tensorboardName(submarine)))
+	return nil
+}
+
+// ownsLegacyObjects returns whether submarine controls one of the
+// deployments or the database volume claim named after legacy
+func (c *Controller) ownsLegacyObjects(submarine *v1alpha1.Submarine, legacy *v1alpha1.Submarine) (bool, error) {
+	for _, name := range []string{serverName(legacy), databaseName(legacy), tensorboardName(legacy)} {
+		deployment, err := c.deploymentLister.Deployments(submarine.Namespace).Get(name)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if metav1.IsControlledBy(deployment, submarine) {
+			return true, nil
+		}
+	}
+
+	pvc, err := c.persistentvolumeclaimLister.PersistentVolumeClaims(submarine.Namespace).Get(databaseName(legacy) + "-pvc")
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return metav1.IsControlledBy(pvc, submarine), nil
+}
+
+// deleteLegacyServerRBAC deletes the ClusterRoleBinding and the ClusterRole
+// of the server that earlier operators named after the server alone, e.g.
+// submarine-server, and that submarine controls. They are replaced by the
+// ones named by clusterScopedName, which must exist, so that the server
+// keeps its permissions.
+func (c *Controller) deleteLegacyServerRBAC(submarine *v1alpha1.Submarine) error {
+	name := serverName(submarine)
+	clusterrolebinding, err := c.clusterrolebindingLister.Get(name)
+	if err == nil && metav1.IsControlledBy(clusterrolebinding, submarine) {
+		klog.Info("	Delete ClusterRoleBinding: ", name)
+		err = c.kubeclientset.RbacV1().ClusterRoleBindings().Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	clusterrole, err := c.clusterroleLister.Get(name)
+	if err == nil && metav1.IsControlledBy(clusterrole, submarine) {
+		klog.Info("	Delete ClusterRole: ", name)
+		err = c.kubeclientset.RbacV1().ClusterRoles().Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
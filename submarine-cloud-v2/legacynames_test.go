@@ -0,0 +1,136 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func ownedBy(obj metav1.Object, submarine *v1alpha1.Submarine) {
+	obj.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine"))})
+}
+
+func TestReconcileLegacyNames(t *testing.T) {
+	submarine := newTestSubmarine()
+	if name := serverName(submarine); name != "example-submarine-server" {
+		t.Errorf("server name = %q, expected the name of the Submarine as prefix", name)
+	}
+
+	// The database of an earlier operator keeps its name and volume
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "submarine-database-pvc", Namespace: submarine.Namespace}}
+	ownedBy(pvc, submarine)
+	database := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "submarine-database", Namespace: submarine.Namespace}}
+	ownedBy(database, submarine)
+	c, _, _ := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects:    []runtime.Object{pvc, database},
+	})
+	submarine = submarine.DeepCopy()
+	if err := c.reconcileLegacyNames(submarine); err != nil {
+		t.Fatal(err)
+	}
+	for got, expected := range map[string]string{
+		serverName(submarine):      "submarine-server",
+		databaseName(submarine):    "submarine-database",
+		tensorboardName(submarine): "submarine-tensorboard",
+	} {
+		if got != expected {
+			t.Errorf("name = %q, expected the legacy name %q", got, expected)
+		}
+	}
+	stored, err := c.submarineclientset.SubmarineV1alpha1().Submarines(submarine.Namespace).Get(context.TODO(), submarine.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Annotations[legacyNamesAnnotation] != "true" {
+		t.Errorf("expected the Submarine to be annotated, got %v", stored.Annotations)
+	}
+
+	// Objects of the same name owned by another Submarine are not legacy
+	// objects of this one
+	submarine = newTestSubmarine()
+	other := newTestSubmarine()
+	other.Name, other.UID = "other", "other-uid"
+	server := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "submarine-server", Namespace: submarine.Namespace}}
+	ownedBy(server, other)
+	c, _, _ = newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine, other},
+		objects:    []runtime.Object{server},
+	})
+	if err := c.reconcileLegacyNames(submarine); err != nil {
+		t.Fatal(err)
+	}
+	if name := serverName(submarine); name != "example-submarine-server" {
+		t.Errorf("server name = %q, expected the name of the Submarine as prefix", name)
+	}
+}
+
+func TestDeleteLegacyServerRBAC(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Annotations = map[string]string{legacyNamesAnnotation: "true"}
+	clusterrole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "submarine-server"}}
+	ownedBy(clusterrole, submarine)
+	clusterrolebinding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "submarine-server"}}
+	ownedBy(clusterrolebinding, submarine)
+	c, kubeClient, _ := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects:    []runtime.Object{clusterrole, clusterrolebinding},
+	})
+	if err := c.newSubmarineServerRBAC(submarine, submarine.Namespace); err != nil {
+		t.Fatal(err)
+	}
+	name := clusterScopedName("submarine-server", submarine.Namespace)
+	if _, err := kubeClient.RbacV1().ClusterRoleBindings().Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding %s, got %v", name, err)
+	}
+	// The objects named by earlier operators are replaced, not orphaned
+	if _, err := kubeClient.RbacV1().ClusterRoleBindings().Get(context.TODO(), "submarine-server", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the legacy ClusterRoleBinding to be deleted, got %v", err)
+	}
+	if _, err := kubeClient.RbacV1().ClusterRoles().Get(context.TODO(), "submarine-server", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the legacy ClusterRole to be deleted, got %v", err)
+	}
+
+	// The objects of the legacy names of another Submarine are kept
+	other := newTestSubmarine()
+	other.Namespace, other.UID = "other", "other-uid"
+	other.Annotations = map[string]string{legacyNamesAnnotation: "true"}
+	c, kubeClient, _ = newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine, other},
+		objects:    []runtime.Object{clusterrole, clusterrolebinding},
+	})
+	if err := c.newSubmarineServerRBAC(other, other.Namespace); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.RbacV1().ClusterRoles().Get(context.TODO(), "submarine-server", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the ClusterRole of another Submarine to be kept, got %v", err)
+	}
+	if _, err := kubeClient.RbacV1().ClusterRoleBindings().Get(context.TODO(), "submarine-server", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding of another Submarine to be kept, got %v", err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"submarine-cloud-v2/pkg/signals"
 	"time"
 
+	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -39,6 +40,13 @@ var (
 	masterURL  string
 	kubeconfig string
 	incluster  bool
+	// chartSources overrides the embedded subcharts
+	chartSources = chartOverrides{}
+	// helmTimeout bounds the Helm operations on subcharts
+	helmTimeout time.Duration
+	// webhookPort and webhookCertDir configure the admission webhook
+	webhookPort    int
+	webhookCertDir string
 )
 
 func initKubeConfig() (*rest.Config, error) {
@@ -49,6 +57,22 @@ func initKubeConfig() (*rest.Config, error) {
 }
 
 func main() {
+	// Subcommands that don't run the controller
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "render":
+			if err := runRender(os.Args[2:]); err != nil {
+				klog.Fatalf("Error rendering manifests: %s", err.Error())
+			}
+			return
+		case "images":
+			if err := runImages(os.Args[2:]); err != nil {
+				klog.Fatalf("Error listing images: %s", err.Error())
+			}
+			return
+		}
+	}
+
 	klog.InitFlags(nil)
 	flag.Parse()
 
@@ -76,6 +100,11 @@ func main() {
 		klog.Fatalf("Error building traefik clientset: %s", err.Error())
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Error building dynamic client: %s", err.Error())
+	}
+
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
 	submarineInformerFactory := informers.NewSharedInformerFactory(submarineClient, time.Second*30)
 	traefikInformerFactory := traefikinformers.NewSharedInformerFactory(traefikClient, time.Second*30)
@@ -84,7 +113,7 @@ func main() {
 	//       ex: namespace informer
 
 	// Create a Submarine operator
-	controller := NewController(incluster, kubeClient, submarineClient, traefikClient,
+	controller := NewController(incluster, chartSources, helmTimeout, kubeClient, submarineClient, traefikClient, dynamicClient, cfg,
 		kubeInformerFactory.Core().V1().Namespaces(),
 		kubeInformerFactory.Apps().V1().Deployments(),
 		kubeInformerFactory.Core().V1().Services(),
@@ -95,15 +124,98 @@ func main() {
 		traefikInformerFactory.Traefik().V1alpha1().IngressRoutes(),
 		kubeInformerFactory.Rbac().V1().ClusterRoles(),
 		kubeInformerFactory.Rbac().V1().ClusterRoleBindings(),
+		kubeInformerFactory.Autoscaling().V2beta2().HorizontalPodAutoscalers(),
+		kubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets(),
+		kubeInformerFactory.Core().V1().ConfigMaps(),
+		kubeInformerFactory.Batch().V1().Jobs(),
+		kubeInformerFactory.Apps().V1().DaemonSets(),
+		kubeInformerFactory.Core().V1().Pods(),
 		submarineInformerFactory.Submarine().V1alpha1().Submarines())
 
+	// Create the controller of the per-experiment Tensorboards
+	tensorboardController := NewTensorboardController(kubeClient, submarineClient, traefikClient,
+		kubeInformerFactory.Apps().V1().Deployments(),
+		kubeInformerFactory.Core().V1().Services(),
+		kubeInformerFactory.Core().V1().PersistentVolumeClaims(),
+		traefikInformerFactory.Traefik().V1alpha1().IngressRoutes(),
+		submarineInformerFactory.Submarine().V1alpha1().Submarines(),
+		submarineInformerFactory.Submarine().V1alpha1().SubmarineTensorboards())
+
+	// Create the controller of the experiments
+	experimentController := NewExperimentController(kubeClient, submarineClient, dynamicClient,
+		kubeInformerFactory.Core().V1().Pods(),
+		submarineInformerFactory.Submarine().V1alpha1().Submarines(),
+		submarineInformerFactory.Submarine().V1alpha1().SubmarineExperiments())
+
+	// Create the controller of the hyperparameter sweeps
+	sweepController := NewSweepController(kubeClient, submarineClient,
+		kubeInformerFactory.Core().V1().Pods(),
+		submarineInformerFactory.Submarine().V1alpha1().SubmarineExperiments(),
+		submarineInformerFactory.Submarine().V1alpha1().SubmarineSweeps())
+
+	// Create the controller of the tenants
+	tenantController := NewTenantController(kubeClient, submarineClient,
+		kubeInformerFactory.Core().V1().Namespaces(),
+		kubeInformerFactory.Core().V1().ResourceQuotas(),
+		kubeInformerFactory.Core().V1().LimitRanges(),
+		kubeInformerFactory.Rbac().V1().Roles(),
+		kubeInformerFactory.Rbac().V1().RoleBindings(),
+		submarineInformerFactory.Submarine().V1alpha1().Submarines(),
+		submarineInformerFactory.Submarine().V1alpha1().SubmarineTenants())
+
+	// Create the controller of the notebook profiles
+	notebookProfileController := NewNotebookProfileController(kubeClient, submarineClient, dynamicClient,
+		kubeInformerFactory.Core().V1().Namespaces(),
+		submarineInformerFactory.Submarine().V1alpha1().SubmarineNotebookProfiles())
+
 	// notice that there is no need to run Start methods in a separate goroutine. (i.e. go kubeInformerFactory.Start(stopCh)
 	// Start method is non-blocking and runs all registered informers in a dedicated goroutine.
 	kubeInformerFactory.Start(stopCh)
 	submarineInformerFactory.Start(stopCh)
 	traefikInformerFactory.Start(stopCh)
 
-	// Run controller
+	// Run controllers
+	go func() {
+		if err := tensorboardController.Run(1, stopCh); err != nil {
+			klog.Fatalf("Error running tensorboard controller: %s", err.Error())
+		}
+	}()
+	go func() {
+		if err := experimentController.Run(1, stopCh); err != nil {
+			klog.Fatalf("Error running experiment controller: %s", err.Error())
+		}
+	}()
+	go func() {
+		if err := sweepController.Run(1, stopCh); err != nil {
+			klog.Fatalf("Error running sweep controller: %s", err.Error())
+		}
+	}()
+	go func() {
+		if err := tenantController.Run(1, stopCh); err != nil {
+			klog.Fatalf("Error running tenant controller: %s", err.Error())
+		}
+	}()
+	go func() {
+		if err := notebookProfileController.Run(1, stopCh); err != nil {
+			klog.Fatalf("Error running notebook profile controller: %s", err.Error())
+		}
+	}()
+	// Serve the admission webhook that queues training jobs, injects the
+	// stores into their pods and applies the notebook profiles
+	if webhookPort > 0 {
+		go func() {
+			handlers := map[string]admitFunc{
+				"/mutate-jobs":      controller.admitJob,
+				"/mutate-pods":      controller.injectStores,
+				"/mutate-notebooks": notebookProfileController.applyProfiles,
+			}
+			// The operator keeps reconciling without the webhook, e.g. while
+			// its certificate is not issued yet
+			if err := runWebhookServer(webhookPort, webhookCertDir, handlers, stopCh); err != nil {
+				klog.Errorf("Error running webhook server, jobs are not queued and pods are not mutated: %s", err.Error())
+			}
+		}()
+	}
 	if err = controller.Run(1, stopCh); err != nil {
 		klog.Fatalf("Error running controller: %s", err.Error())
 	}
@@ -112,5 +224,9 @@ func main() {
 func init() {
 	flag.BoolVar(&incluster, "incluster", false, "Run submarine-operator in-cluster")
 	flag.StringVar(&kubeconfig, "kubeconfig", os.Getenv("HOME")+"/.kube/config", "Path to a kubeconfig. Only required if out-of-cluster.")
+	flag.Var(chartSources, "chart", "Source of a subchart instead of the embedded one, as NAME=SOURCE where SOURCE is a directory or REPO_URL/CHART[@VERSION]. Can be repeated.")
+	flag.DurationVar(&helmTimeout, "helm-timeout", 5*time.Minute, "How long to wait for a Helm install, upgrade or rollback of a subchart to become ready. A release pending for longer is recovered.")
+	flag.IntVar(&webhookPort, "webhook-port", 0, "Port of the admission webhook that queues training jobs, injects the tensorboard and mlflow stores into pods and applies the notebook profiles. 0 disables the webhook.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Directory with the tls.crt and tls.key of the admission webhook.")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 }
@@ -0,0 +1,449 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+)
+
+// Ports of the metrics of the components. Each is exposed as the port named
+// "metrics" of the pods, and of the services of the server and the database.
+const (
+	metricsPortName     = "metrics"
+	serverMetricsPort   = 9404
+	databaseMetricsPort = 9104
+	traefikMetricsPort  = 9100
+	// serverJMXPort is the JMX port of the server, only reachable by the
+	// exporter sidecar through localhost
+	serverJMXPort = 9010
+)
+
+// jmxExporterConfigFile is the configuration of the JMX exporter of the
+// server, which exports every MBean
+const (
+	jmxExporterConfigFile = "jmx-exporter.yaml"
+	jmxExporterConfigDir  = "/etc/jmx-exporter"
+)
+
+var jmxExporterConfig = fmt.Sprintf(`hostPort: 127.0.0.1:%d
+lowercaseOutputName: true
+rules:
+  - pattern: ".*"
+`, serverJMXPort)
+
+// monitoringGroupVersion is the API of the Prometheus Operator
+var monitoringGroupVersion = schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+
+var (
+	serviceMonitorResource = monitoringGroupVersion.WithResource("servicemonitors")
+	podMonitorResource     = monitoringGroupVersion.WithResource("podmonitors")
+)
+
+// Reasons of the Monitored condition
+const (
+	MonitorsCreatedReason            = "MonitorsCreated"
+	PrometheusOperatorNotFoundReason = "PrometheusOperatorNotFound"
+)
+
+func monitoringEnabled(submarine *v1alpha1.Submarine) bool {
+	return submarine.Spec.Monitoring != nil && submarine.Spec.Monitoring.Enabled
+}
+
+func serverMetricsConfigName(submarine *v1alpha1.Submarine) string {
+	return serverName(submarine) + "-metrics"
+}
+
+func traefikPodMonitorName(submarine *v1alpha1.Submarine) string {
+	return submarine.Name + "-traefik"
+}
+
+// serverJavaOpts enables the JMX port of the server for the exporter
+func serverJavaOpts() string {
+	return fmt.Sprintf("-Dcom.sun.management.jmxremote.port=%d -Dcom.sun.management.jmxremote.rmi.port=%d "+
+		"-Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false "+
+		"-Djava.rmi.server.hostname=127.0.0.1", serverJMXPort, serverJMXPort)
+}
+
+// newServerExporterContainer returns the JMX exporter sidecar of the server
+func newServerExporterContainer(submarine *v1alpha1.Submarine) corev1.Container {
+	return corev1.Container{
+		Name:            "jmx-exporter",
+		Image:           serverExporterImage(submarine),
+		ImagePullPolicy: "IfNotPresent",
+		Args:            []string{fmt.Sprint(serverMetricsPort), jmxExporterConfigDir + "/" + jmxExporterConfigFile},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          metricsPortName,
+				ContainerPort: serverMetricsPort,
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "metrics-config",
+				MountPath: jmxExporterConfigDir,
+			},
+		},
+	}
+}
+
+func newServerMetricsVolume(submarine *v1alpha1.Submarine) corev1.Volume {
+	return corev1.Volume{
+		Name: "metrics-config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: serverMetricsConfigName(submarine),
+				},
+			},
+		},
+	}
+}
+
+// newDatabaseExporterContainer returns the MySQL exporter sidecar of the
+// database
+func newDatabaseExporterContainer(submarine *v1alpha1.Submarine) corev1.Container {
+	return corev1.Container{
+		Name:            "mysqld-exporter",
+		Image:           databaseExporterImage(submarine),
+		ImagePullPolicy: "IfNotPresent",
+		Env: []corev1.EnvVar{
+			{
+				Name:  "DATA_SOURCE_NAME",
+				Value: "root:" + databaseRootPassword + "@(127.0.0.1:3306)/",
+			},
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          metricsPortName,
+				ContainerPort: databaseMetricsPort,
+			},
+		},
+	}
+}
+
+func newMetricsServicePort(port int) corev1.ServicePort {
+	return corev1.ServicePort{
+		Name:       metricsPortName,
+		Port:       int32(port),
+		TargetPort: intstr.FromString(metricsPortName),
+		Protocol:   "TCP",
+	}
+}
+
+// servicePortsNeedUpdate reports whether the ports of a service differ
+// between the desired and the actual state, ignoring the fields defaulted
+// by the API server
+func servicePortsNeedUpdate(desired *corev1.Service, actual *corev1.Service) bool {
+	if len(desired.Spec.Ports) != len(actual.Spec.Ports) {
+		return true
+	}
+	for i, port := range desired.Spec.Ports {
+		actualPort := actual.Spec.Ports[i]
+		if port.Name != actualPort.Name || port.Port != actualPort.Port || port.TargetPort != actualPort.TargetPort {
+			return true
+		}
+	}
+	return false
+}
+
+// updateServicePorts updates the ports of a service if they are not equal to
+// desired
+func (c *Controller) updateServicePorts(desired *corev1.Service, service *corev1.Service) error {
+	if !servicePortsNeedUpdate(desired, service) {
+		return nil
+	}
+	serviceCopy := service.DeepCopy()
+	serviceCopy.Spec.Ports = desired.Spec.Ports
+	_, err := c.kubeclientset.CoreV1().Services(service.Namespace).Update(context.TODO(), serviceCopy, metav1.UpdateOptions{})
+	return err
+}
+
+func newSubmarineServerMetricsConfigMap(submarine *v1alpha1.Submarine) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serverMetricsConfigName(submarine),
+			Labels: submarineLabels(submarine, "server", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Data: map[string]string{
+			jmxExporterConfigFile: jmxExporterConfig,
+		},
+	}
+}
+
+// newSubmarineServerMetricsConfig is a function to create or remove the
+// ConfigMap of the JMX exporter of the server according to
+// spec.monitoring.enabled.
+func (c *Controller) newSubmarineServerMetricsConfig(submarine *v1alpha1.Submarine, namespace string) error {
+	configmap, configmap_err := c.configmapLister.ConfigMaps(namespace).Get(serverMetricsConfigName(submarine))
+
+	if !monitoringEnabled(submarine) {
+		if errors.IsNotFound(configmap_err) {
+			return nil
+		}
+		if configmap_err != nil {
+			return configmap_err
+		}
+		if !metav1.IsControlledBy(configmap, submarine) {
+			return nil
+		}
+		klog.Info("	Delete ConfigMap: ", configmap.Name)
+		return c.kubeclientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), configmap.Name, metav1.DeleteOptions{})
+	}
+
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(configmap_err) {
+		configmap, configmap_err = c.kubeclientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), newSubmarineServerMetricsConfigMap(submarine), metav1.CreateOptions{})
+		if configmap_err != nil {
+			klog.Info(configmap_err)
+		}
+		klog.Info("	Create ConfigMap: ", configmap.Name)
+	}
+
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if configmap_err != nil {
+		return configmap_err
+	}
+
+	return c.claimObject(submarine, configmap, "server", func(patch []byte) error {
+		_, configmap_err = c.kubeclientset.CoreV1().ConfigMaps(namespace).Patch(context.TODO(), configmap.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return configmap_err
+	})
+}
+
+// newMonitor returns a ServiceMonitor or a PodMonitor of kind, scraping the
+// port "metrics" of the services or pods selected by selector
+func newMonitor(submarine *v1alpha1.Submarine, kind string, name string, component string, selector map[string]string) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port": metricsPortName,
+	}
+	if submarine.Spec.Monitoring.Interval != "" {
+		endpoint["interval"] = submarine.Spec.Monitoring.Interval
+	}
+	matchLabels := map[string]interface{}{}
+	for key, value := range selector {
+		matchLabels[key] = value
+	}
+	endpointsField := "endpoints"
+	if kind == "PodMonitor" {
+		endpointsField = "podMetricsEndpoints"
+	}
+
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": matchLabels,
+			},
+			"namespaceSelector": map[string]interface{}{
+				"matchNames": []interface{}{submarine.Namespace},
+			},
+			endpointsField: []interface{}{endpoint},
+		},
+	}}
+	monitor.SetAPIVersion(monitoringGroupVersion.String())
+	monitor.SetKind(kind)
+	monitor.SetName(name)
+	monitor.SetNamespace(submarine.Namespace)
+	labels := submarineLabels(submarine, component, nil)
+	for key, value := range submarine.Spec.Monitoring.Labels {
+		labels[key] = value
+	}
+	monitor.SetLabels(labels)
+	monitor.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+	})
+	return monitor
+}
+
+// componentSelector selects the objects of a component of the Submarine
+func componentSelector(submarine *v1alpha1.Submarine, component string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance":  submarine.Name,
+		"app.kubernetes.io/component": component,
+	}
+}
+
+// newMonitors returns the ServiceMonitors of the server and the database
+// and the PodMonitor of traefik, whose metrics port is not exposed by its
+// service
+func newMonitors(submarine *v1alpha1.Submarine) map[schema.GroupVersionResource][]*unstructured.Unstructured {
+	return map[schema.GroupVersionResource][]*unstructured.Unstructured{
+		serviceMonitorResource: {
+			newMonitor(submarine, "ServiceMonitor", serverName(submarine), "server", componentSelector(submarine, "server")),
+			newMonitor(submarine, "ServiceMonitor", databaseName(submarine), "database", componentSelector(submarine, "database")),
+		},
+		podMonitorResource: {
+			newMonitor(submarine, "PodMonitor", traefikPodMonitorName(submarine), "traefik", map[string]string{
+				"app.kubernetes.io/name":     "traefik",
+				"app.kubernetes.io/instance": "traefik",
+			}),
+		},
+	}
+}
+
+// monitorNames returns the names of the monitors of newMonitors
+func monitorNames(submarine *v1alpha1.Submarine) map[schema.GroupVersionResource][]string {
+	return map[schema.GroupVersionResource][]string{
+		serviceMonitorResource: {serverName(submarine), databaseName(submarine)},
+		podMonitorResource:     {traefikPodMonitorName(submarine)},
+	}
+}
+
+// monitoringAvailable reports whether the CRDs of the Prometheus Operator
+// are installed. It is checked on every reconcile, so that monitors are
+// created once the Prometheus Operator is installed.
+func (c *Controller) monitoringAvailable() (bool, error) {
+	groups, err := c.kubeclientset.Discovery().ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups.Groups {
+		if group.Name != monitoringGroupVersion.Group {
+			continue
+		}
+		for _, version := range group.Versions {
+			if version.Version == monitoringGroupVersion.Version {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// newSubmarineMonitors is a function to create, update or remove the
+// ServiceMonitors and the PodMonitor of a Submarine according to
+// spec.monitoring.enabled. Clusters without the Prometheus Operator are
+// skipped.
+func (c *Controller) newSubmarineMonitors(submarine *v1alpha1.Submarine, namespace string) error {
+	klog.Info("[newSubmarineMonitors]")
+	available, err := c.monitoringAvailable()
+	if err != nil {
+		return err
+	}
+
+	if !monitoringEnabled(submarine) {
+		meta.RemoveStatusCondition(&submarine.Status.Conditions, v1alpha1.SubmarineMonitored)
+		if !available {
+			return nil
+		}
+		for resource, names := range monitorNames(submarine) {
+			for _, name := range names {
+				if err := c.deleteMonitor(submarine, namespace, resource, name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.SubmarineMonitored,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: submarine.Generation,
+		Reason:             PrometheusOperatorNotFoundReason,
+		Message:            "The metrics are exposed, but the monitoring.coreos.com CRDs are not installed",
+	}
+	if available {
+		for resource, monitors := range newMonitors(submarine) {
+			for _, monitor := range monitors {
+				if err := c.reconcileMonitor(submarine, namespace, resource, monitor); err != nil {
+					return err
+				}
+			}
+		}
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = MonitorsCreatedReason
+		condition.Message = "The metrics are scraped by the Prometheus Operator"
+	}
+	meta.SetStatusCondition(&submarine.Status.Conditions, condition)
+	return nil
+}
+
+// reconcileMonitor creates a monitor, or updates it if it is not equal to
+// desired
+func (c *Controller) reconcileMonitor(submarine *v1alpha1.Submarine, namespace string, resource schema.GroupVersionResource, desired *unstructured.Unstructured) error {
+	client := c.dynamicclientset.Resource(resource).Namespace(namespace)
+	monitor, monitor_err := client.Get(context.TODO(), desired.GetName(), metav1.GetOptions{})
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(monitor_err) {
+		monitor, monitor_err = client.Create(context.TODO(), desired, metav1.CreateOptions{})
+		if monitor_err != nil {
+			klog.Info(monitor_err)
+		}
+		klog.Info("	Create ", desired.GetKind(), ": ", desired.GetName())
+	}
+
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if monitor_err != nil {
+		return monitor_err
+	}
+
+	if err := c.claimObject(submarine, monitor, desired.GetLabels()["app.kubernetes.io/component"], func(patch []byte) error {
+		monitor, monitor_err = client.Patch(context.TODO(), monitor.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		return monitor_err
+	}); err != nil {
+		return err
+	}
+
+	// Update the monitor if it is not equal to spec
+	if !equality.Semantic.DeepEqual(monitor.Object["spec"], desired.Object["spec"]) || !equality.Semantic.DeepEqual(monitor.GetLabels(), desired.GetLabels()) {
+		monitorCopy := monitor.DeepCopy()
+		monitorCopy.Object["spec"] = desired.Object["spec"]
+		monitorCopy.SetLabels(desired.GetLabels())
+		_, monitor_err = client.Update(context.TODO(), monitorCopy, metav1.UpdateOptions{})
+	}
+
+	return monitor_err
+}
+
+// deleteMonitor deletes a monitor controlled by the Submarine
+func (c *Controller) deleteMonitor(submarine *v1alpha1.Submarine, namespace string, resource schema.GroupVersionResource, name string) error {
+	client := c.dynamicclientset.Resource(resource).Namespace(namespace)
+	monitor, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(monitor, submarine) {
+		return nil
+	}
+	klog.Info("	Delete ", monitor.GetKind(), ": ", name)
+	return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// prometheusOperatorResources are the resources of an API server serving the
+// monitoring.coreos.com API
+var prometheusOperatorResources = []*metav1.APIResourceList{
+	{
+		GroupVersion: monitoringGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: "servicemonitors"}, {Name: "podmonitors"}},
+	},
+}
+
+func TestNewSubmarineMonitors(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.Monitoring = &v1alpha1.SubmarineMonitoring{Enabled: true, Labels: map[string]string{"release": "prometheus"}}
+
+	// Without the Prometheus Operator the metrics are only exposed
+	c, _, dynamicClient := newTestController(t, testControllerOptions{})
+	if err := c.newSubmarineMonitors(submarine, submarine.Namespace); err != nil {
+		t.Fatal(err)
+	}
+	condition := meta.FindStatusCondition(submarine.Status.Conditions, v1alpha1.SubmarineMonitored)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != PrometheusOperatorNotFoundReason {
+		t.Errorf("expected the Monitored condition to be false, got %+v", condition)
+	}
+	if len(dynamicClient.Actions()) != 0 {
+		t.Errorf("expected no monitors, got %v", dynamicClient.Actions())
+	}
+
+	// With the Prometheus Operator the monitors are created
+	c, _, dynamicClient = newTestController(t, testControllerOptions{apiResources: prometheusOperatorResources})
+	if err := c.newSubmarineMonitors(submarine, submarine.Namespace); err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(submarine.Status.Conditions, v1alpha1.SubmarineMonitored) {
+		t.Errorf("expected the Monitored condition to be true")
+	}
+	monitor, err := dynamicClient.Resource(serviceMonitorResource).Namespace(submarine.Namespace).Get(context.TODO(), "example-submarine-server", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitor.GetLabels()["release"] != "prometheus" || !metav1.IsControlledBy(monitor, submarine) {
+		t.Errorf("expected the ServiceMonitor to be labeled and owned by the Submarine, got %v", monitor.Object["metadata"])
+	}
+	if _, err := dynamicClient.Resource(podMonitorResource).Namespace(submarine.Namespace).Get(context.TODO(), "example-submarine-traefik", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the PodMonitor of traefik: %v", err)
+	}
+
+	// Disabling monitoring deletes the monitors
+	submarine.Spec.Monitoring.Enabled = false
+	if err := c.newSubmarineMonitors(submarine, submarine.Namespace); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynamicClient.Resource(serviceMonitorResource).Namespace(submarine.Namespace).Get(context.TODO(), "example-submarine-server", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the ServiceMonitor to be deleted")
+	}
+	if meta.FindStatusCondition(submarine.Status.Conditions, v1alpha1.SubmarineMonitored) != nil {
+		t.Errorf("expected no Monitored condition")
+	}
+}
+
+func TestServicePortsNeedUpdate(t *testing.T) {
+	submarine := newTestSubmarine()
+	actual := newSubmarineDatabaseService(submarine)
+	// The API server defaults the protocol
+	actual.Spec.Ports[0].Protocol = "TCP"
+	if servicePortsNeedUpdate(newSubmarineDatabaseService(submarine), actual) {
+		t.Errorf("defaulted fields should not update the service")
+	}
+
+	submarine.Spec.Monitoring = &v1alpha1.SubmarineMonitoring{Enabled: true}
+	if !servicePortsNeedUpdate(newSubmarineDatabaseService(submarine), actual) {
+		t.Errorf("the metrics port should update the service")
+	}
+}
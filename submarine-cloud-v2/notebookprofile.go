@@ -0,0 +1,495 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	clientset "submarine-cloud-v2/pkg/generated/clientset/versioned"
+	submarinescheme "submarine-cloud-v2/pkg/generated/clientset/versioned/scheme"
+	informers "submarine-cloud-v2/pkg/generated/informers/externalversions/submarine/v1alpha1"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const notebookProfileControllerAgentName = "submarine-notebookprofile-controller"
+
+const (
+	// notebookStoppedAnnotation makes the notebook controller scale a
+	// notebook to zero. Removing it starts the notebook again.
+	notebookStoppedAnnotation = "kubeflow-resource-stopped"
+	// notebookLastActivityAnnotation holds the last activity of the kernels
+	// of a notebook
+	notebookLastActivityAnnotation = "notebooks.kubeflow.org/last-activity"
+)
+
+const (
+	// NotebookCulled is used as part of the Event 'reason' when an idle
+	// notebook is stopped
+	NotebookCulled = "NotebookCulled"
+	// MessageNotebookCulled is the message used for an Event fired when an
+	// idle notebook is stopped
+	MessageNotebookCulled = "Stopped notebook %s, idle since %s"
+)
+
+// notebookProfilesAnnotation lists the SubmarineNotebookProfiles applied to
+// a Notebook
+const notebookProfilesAnnotation = "submarine.k8s.io/notebook-profiles"
+
+// notebookProfileResyncPeriod is how often the kernels of the Notebooks of
+// a SubmarineNotebookProfile are checked
+const notebookProfileResyncPeriod = time.Minute
+
+// notebookKernel is a kernel of the Jupyter server of a Notebook, as
+// returned by its /api/kernels
+type notebookKernel struct {
+	ExecutionState string    `json:"execution_state"`
+	LastActivity   time.Time `json:"last_activity"`
+}
+
+// NotebookProfileController applies the defaults of
+// SubmarineNotebookProfiles to new Notebooks through the admission webhook,
+// and stops the Notebooks whose kernels are idle
+type NotebookProfileController struct {
+	kubeclientset      kubernetes.Interface
+	submarineclientset clientset.Interface
+	dynamicclientset   dynamic.Interface
+
+	profilesLister  listers.SubmarineNotebookProfileLister
+	profilesSynced  cache.InformerSynced
+	namespaceLister corelisters.NamespaceLister
+	namespaceSynced cache.InformerSynced
+	// workqueue holds the names of the SubmarineNotebookProfiles to
+	// reconcile
+	workqueue workqueue.RateLimitingInterface
+	recorder  record.EventRecorder
+	now       func() time.Time
+	// kernels returns the kernels of a running Notebook
+	kernels func(notebook *unstructured.Unstructured) ([]notebookKernel, error)
+}
+
+// NewNotebookProfileController returns a new SubmarineNotebookProfile
+// controller
+func NewNotebookProfileController(
+	kubeclientset kubernetes.Interface,
+	submarineclientset clientset.Interface,
+	dynamicclientset dynamic.Interface,
+	namespaceInformer coreinformers.NamespaceInformer,
+	profileInformer informers.SubmarineNotebookProfileInformer) *NotebookProfileController {
+
+	utilruntime.Must(submarinescheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: notebookProfileControllerAgentName})
+
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	controller := &NotebookProfileController{
+		kubeclientset:      kubeclientset,
+		submarineclientset: submarineclientset,
+		dynamicclientset:   dynamicclientset,
+		profilesLister:     profileInformer.Lister(),
+		profilesSynced:     profileInformer.Informer().HasSynced,
+		namespaceLister:    namespaceInformer.Lister(),
+		namespaceSynced:    namespaceInformer.Informer().HasSynced,
+		workqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineNotebookProfiles"),
+		recorder:           recorder,
+		now:                time.Now,
+		kernels: func(notebook *unstructured.Unstructured) ([]notebookKernel, error) {
+			return notebookKernels(httpClient, notebook)
+		},
+	}
+
+	klog.Info("Setting up SubmarineNotebookProfile event handlers")
+	profileInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueNotebookProfile,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueNotebookProfile(new)
+		},
+	})
+
+	return controller
+}
+
+// Run waits for the caches to sync and processes SubmarineNotebookProfiles
+// until stopCh is closed
+func (c *NotebookProfileController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Info("Starting SubmarineNotebookProfile controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.profilesSynced, c.namespaceSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down SubmarineNotebookProfile workers")
+
+	return nil
+}
+
+func (c *NotebookProfileController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *NotebookProfileController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(key); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(key)
+		utilruntime.HandleError(fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.Infof("Successfully synced '%s'", key)
+	return true
+}
+
+func (c *NotebookProfileController) enqueueNotebookProfile(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// syncHandler stops the idle Notebooks of the SubmarineNotebookProfile named
+// key and counts its Notebooks, and checks them again after
+// notebookProfileResyncPeriod
+func (c *NotebookProfileController) syncHandler(key string) error {
+	profile, err := c.profilesLister.Get(key)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	profile = profile.DeepCopy()
+	klog.Info("[syncNotebookProfile] ", key)
+
+	namespaces, err := c.profileNamespaces(profile)
+	if err != nil {
+		return err
+	}
+	profile.Status = v1alpha1.SubmarineNotebookProfileStatus{}
+	for _, namespace := range namespaces {
+		profile.Status.Namespaces = append(profile.Status.Namespaces, namespace.Name)
+		if err := c.cullIdleNotebooks(profile, namespace.Name); err != nil {
+			return err
+		}
+	}
+
+	if err := c.updateNotebookProfileStatus(profile); err != nil {
+		return err
+	}
+	c.workqueue.AddAfter(key, notebookProfileResyncPeriod)
+	return nil
+}
+
+// profileNamespaces returns the namespaces selected by a
+// SubmarineNotebookProfile, sorted by name
+func (c *NotebookProfileController) profileNamespaces(profile *v1alpha1.SubmarineNotebookProfile) ([]*corev1.Namespace, error) {
+	selector := labels.Everything()
+	if profile.Spec.NamespaceSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(profile.Spec.NamespaceSelector); err != nil {
+			return nil, err
+		}
+	}
+	namespaces, err := c.namespaceLister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+	return namespaces, nil
+}
+
+// notebookKernels returns the kernels of the Jupyter server of a Notebook,
+// served by the Service of the Notebook under its NB_PREFIX
+func notebookKernels(httpClient *http.Client, notebook *unstructured.Unstructured) ([]notebookKernel, error) {
+	url := fmt.Sprintf("http://%s.%s.svc/notebook/%s/%s/api/kernels", notebook.GetName(), notebook.GetNamespace(), notebook.GetNamespace(), notebook.GetName())
+	response, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	var kernels []notebookKernel
+	if err := json.NewDecoder(response.Body).Decode(&kernels); err != nil {
+		return nil, err
+	}
+	return kernels, nil
+}
+
+// notebookLastActivity returns the last activity of a Notebook: its
+// last-activity annotation, or its creation without the annotation, moved
+// forward by the activity of its kernels. A busy kernel is active now.
+func notebookLastActivity(notebook *unstructured.Unstructured, kernels []notebookKernel, now time.Time) time.Time {
+	lastActivity := notebook.GetCreationTimestamp().Time
+	if value, ok := notebook.GetAnnotations()[notebookLastActivityAnnotation]; ok {
+		if activity, err := time.Parse(time.RFC3339, value); err == nil && activity.After(lastActivity) {
+			lastActivity = activity
+		}
+	}
+	for _, kernel := range kernels {
+		if kernel.ExecutionState == "busy" {
+			return now
+		}
+		if kernel.LastActivity.After(lastActivity) {
+			lastActivity = kernel.LastActivity
+		}
+	}
+	return lastActivity
+}
+
+// cullIdleNotebooks records the activity of the kernels of the running
+// Notebooks of a namespace in their last-activity annotation, and stops
+// the ones idle for longer than the timeout of the profile. Notebooks whose
+// kernels cannot be reached are left alone.
+func (c *NotebookProfileController) cullIdleNotebooks(profile *v1alpha1.SubmarineNotebookProfile, namespace string) error {
+	client := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource("notebooks")).Namespace(namespace)
+	notebooks, err := client.List(context.TODO(), metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	for i := range notebooks.Items {
+		notebook := &notebooks.Items[i]
+		if _, stopped := notebook.GetAnnotations()[notebookStoppedAnnotation]; stopped || notebook.GetDeletionTimestamp() != nil {
+			profile.Status.Stopped++
+			continue
+		}
+		profile.Status.Running++
+		if profile.Spec.IdleTimeoutSeconds == nil {
+			continue
+		}
+
+		kernels, err := c.kernels(notebook)
+		if err != nil {
+			klog.Info("Skip culling Notebook ", namespace, "/", notebook.GetName(), ": ", err)
+			continue
+		}
+		lastActivity := notebookLastActivity(notebook, kernels, now)
+		annotations := map[string]string{}
+		if value := lastActivity.UTC().Format(time.RFC3339); value != notebook.GetAnnotations()[notebookLastActivityAnnotation] {
+			annotations[notebookLastActivityAnnotation] = value
+		}
+		idle := now.Sub(lastActivity)
+		culled := idle >= time.Duration(*profile.Spec.IdleTimeoutSeconds)*time.Second
+		if culled {
+			annotations[notebookStoppedAnnotation] = now.UTC().Format(time.RFC3339)
+		}
+		if len(annotations) == 0 {
+			continue
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": annotations,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = client.Patch(context.TODO(), notebook.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if culled {
+			profile.Status.Running--
+			profile.Status.Stopped++
+			klog.Info("	Stop Notebook: ", namespace, "/", notebook.GetName())
+			c.recorder.Eventf(profile, corev1.EventTypeNormal, NotebookCulled, MessageNotebookCulled, namespace+"/"+notebook.GetName(), lastActivity.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// applyNotebookProfile fills the pod spec of a Notebook with the defaults of
+// a SubmarineNotebookProfile, and tells whether it changed
+func applyNotebookProfile(spec *corev1.PodSpec, profile *v1alpha1.SubmarineNotebookProfile) bool {
+	if len(spec.Containers) == 0 {
+		return false
+	}
+	original := spec.DeepCopy()
+	container := &spec.Containers[0]
+	if container.Image == "" {
+		container.Image = profile.Spec.Image
+	}
+	for _, envVar := range profile.Spec.Env {
+		if !hasEnv(*container, envVar.Name) {
+			container.Env = append(container.Env, envVar)
+		}
+	}
+	container.Resources.Requests = defaultResources(container.Resources.Requests, profile.Spec.Resources.Requests)
+	container.Resources.Limits = defaultResources(container.Resources.Limits, profile.Spec.Resources.Limits)
+	for _, volume := range profile.Spec.Volumes {
+		if !hasVolume(&corev1.Pod{Spec: *spec}, volume.Name) {
+			spec.Volumes = append(spec.Volumes, volume)
+		}
+	}
+	for _, mount := range profile.Spec.VolumeMounts {
+		if !hasMountPath(*container, mount.MountPath) {
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+		}
+	}
+	return !equality.Semantic.DeepEqual(original, spec)
+}
+
+// defaultResources adds the quantities of defaults missing from resources
+func defaultResources(resources corev1.ResourceList, defaults corev1.ResourceList) corev1.ResourceList {
+	for name, quantity := range defaults {
+		if _, ok := resources[name]; ok {
+			continue
+		}
+		if resources == nil {
+			resources = corev1.ResourceList{}
+		}
+		resources[name] = quantity
+	}
+	return resources
+}
+
+// applyProfiles fills the Notebooks created in the namespaces selected by
+// SubmarineNotebookProfiles with their defaults. Profiles apply in the order
+// of their names, so the first profile setting a default wins.
+func (c *NotebookProfileController) applyProfiles(request *admissionv1.AdmissionRequest) ([]jsonPatchOperation, error) {
+	if request.Resource.Resource != "notebooks" || request.Operation != admissionv1.Create {
+		return nil, nil
+	}
+	namespace, err := c.namespaceLister.Get(request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := c.profilesLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	notebook := &unstructured.Unstructured{}
+	if err := notebook.UnmarshalJSON(request.Object.Raw); err != nil {
+		return nil, err
+	}
+	templateSpec, _, _ := unstructured.NestedMap(notebook.Object, "spec", "template", "spec")
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateSpec, spec); err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, profile := range profiles {
+		selector := labels.Everything()
+		if profile.Spec.NamespaceSelector != nil {
+			if selector, err = metav1.LabelSelectorAsSelector(profile.Spec.NamespaceSelector); err != nil {
+				klog.Info("Skip SubmarineNotebookProfile ", profile.Name, ": ", err)
+				continue
+			}
+		}
+		if selector.Matches(labels.Set(namespace.Labels)) && applyNotebookProfile(spec, profile) {
+			applied = append(applied, profile.Name)
+		}
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
+		return nil, err
+	}
+	patch := []jsonPatchOperation{{Op: "add", Path: "/spec/template/spec", Value: value}}
+	if notebook.GetAnnotations() == nil {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/metadata/annotations", Value: map[string]string{
+			notebookProfilesAnnotation: strings.Join(applied, ","),
+		}})
+	} else {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/metadata/annotations/" + jsonPointer(notebookProfilesAnnotation), Value: strings.Join(applied, ",")})
+	}
+	klog.Info("	Apply SubmarineNotebookProfiles ", strings.Join(applied, ","), " to Notebook: ", request.Namespace, "/", notebook.GetName())
+	return patch, nil
+}
+
+func (c *NotebookProfileController) updateNotebookProfileStatus(profile *v1alpha1.SubmarineNotebookProfile) error {
+	current, err := c.profilesLister.Get(profile.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(current.Status, profile.Status) {
+		return nil
+	}
+	_, err = c.submarineclientset.SubmarineV1alpha1().SubmarineNotebookProfiles().Update(context.TODO(), profile, metav1.UpdateOptions{})
+	return err
+}
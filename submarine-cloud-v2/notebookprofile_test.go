@@ -0,0 +1,252 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	submarinefake "submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var notebookProfileTestNow = time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newNotebookProfileTestController returns a controller whose listers hold
+// the namespaces and profiles of objs, and whose API server holds their
+// notebooks. kernels maps the name of a notebook to its kernels; notebooks
+// missing from it cannot be reached.
+func newNotebookProfileTestController(kernels map[string][]notebookKernel, objs ...runtime.Object) *NotebookProfileController {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	submarineClient := submarinefake.NewSimpleClientset()
+	var notebooks []runtime.Object
+	for _, obj := range objs {
+		switch obj := obj.(type) {
+		case *corev1.Namespace:
+			namespaceIndexer.Add(obj)
+		case *v1alpha1.SubmarineNotebookProfile:
+			indexer.Add(obj)
+			submarineClient.Tracker().Add(obj)
+		default:
+			notebooks = append(notebooks, obj)
+		}
+	}
+	return &NotebookProfileController{
+		submarineclientset: submarineClient,
+		dynamicclientset:   dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), testListKinds, notebooks...),
+		profilesLister:     listers.NewSubmarineNotebookProfileLister(indexer),
+		namespaceLister:    corelisters.NewNamespaceLister(namespaceIndexer),
+		workqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineNotebookProfiles"),
+		recorder:           record.NewFakeRecorder(100),
+		now:                func() time.Time { return notebookProfileTestNow },
+		kernels: func(notebook *unstructured.Unstructured) ([]notebookKernel, error) {
+			kernels, ok := kernels[notebook.GetName()]
+			if !ok {
+				return nil, fmt.Errorf("notebook %s is not reachable", notebook.GetName())
+			}
+			return kernels, nil
+		},
+	}
+}
+
+func newNotebookProfileTestNotebook(name string, annotations map[string]string) *unstructured.Unstructured {
+	notebook := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":      name,
+							"image":     "custom-notebook",
+							"env":       []interface{}{map[string]interface{}{"name": "SUBMARINE_TEAM", "value": "custom"}},
+							"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "1"}},
+						},
+					},
+				},
+			},
+		},
+	}}
+	notebook.SetAPIVersion(kubeflowGroupVersion.String())
+	notebook.SetKind("Notebook")
+	notebook.SetName(name)
+	notebook.SetNamespace("team-a")
+	notebook.SetCreationTimestamp(metav1.Time{Time: notebookProfileTestNow.Add(-48 * time.Hour)})
+	notebook.SetAnnotations(annotations)
+	return notebook
+}
+
+func newTestNotebookProfile(name string) *v1alpha1.SubmarineNotebookProfile {
+	timeout := int32(3600)
+	return &v1alpha1.SubmarineNotebookProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.SubmarineNotebookProfileSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{tenantLabel: "team-a"}},
+			Image:             "apache/submarine:jupyter-notebook-0.6.0-SNAPSHOT",
+			Env:               []corev1.EnvVar{{Name: "SUBMARINE_TEAM", Value: "team-a"}, {Name: "PROFILE", Value: name}},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			Volumes:            []corev1.Volume{{Name: "datasets", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			VolumeMounts:       []corev1.VolumeMount{{Name: "datasets", MountPath: "/home/jovyan/datasets"}},
+			IdleTimeoutSeconds: &timeout,
+		},
+	}
+}
+
+func newNotebookProfileTestNamespaces() []runtime.Object {
+	return []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{tenantLabel: "team-a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{tenantLabel: "team-b"}}},
+	}
+}
+
+func TestNotebookProfileApply(t *testing.T) {
+	second := newTestNotebookProfile("b")
+	second.Spec.Env = append(second.Spec.Env, corev1.EnvVar{Name: "SECOND", Value: "b"})
+	objs := append(newNotebookProfileTestNamespaces(), second, newTestNotebookProfile("a"))
+	c := newNotebookProfileTestController(nil, objs...)
+
+	notebook := newNotebookProfileTestNotebook("notebook", nil)
+	unstructured.RemoveNestedField(notebook.Object, "spec", "template", "spec", "containers")
+	unstructured.SetNestedSlice(notebook.Object, []interface{}{
+		map[string]interface{}{
+			"name":      "notebook",
+			"env":       []interface{}{map[string]interface{}{"name": "SUBMARINE_TEAM", "value": "custom"}},
+			"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "1"}},
+		},
+	}, "spec", "template", "spec", "containers")
+	raw, err := notebook.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &admissionv1.AdmissionRequest{
+		Resource:  metav1.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "notebooks"},
+		Namespace: "team-a",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+	patch, err := c.applyProfiles(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 2 || patch[0].Path != "/spec/template/spec" {
+		t.Fatalf("unexpected patch %v", patch)
+	}
+	if value := patch[1].Value.(map[string]string)[notebookProfilesAnnotation]; value != "a,b" {
+		t.Errorf("profiles = %s, want a,b", value)
+	}
+
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(patch[0].Value.(map[string]interface{}), spec); err != nil {
+		t.Fatal(err)
+	}
+	container := spec.Containers[0]
+	if container.Image != "apache/submarine:jupyter-notebook-0.6.0-SNAPSHOT" {
+		t.Errorf("image = %s", container.Image)
+	}
+	env, _ := json.Marshal(container.Env)
+	// The env of the notebook and then of the first profile win
+	if want := `[{"name":"SUBMARINE_TEAM","value":"custom"},{"name":"PROFILE","value":"a"},{"name":"SECOND","value":"b"}]`; string(env) != want {
+		t.Errorf("env = %s, want %s", env, want)
+	}
+	if cpu := container.Resources.Requests[corev1.ResourceCPU]; cpu.String() != "1" {
+		t.Errorf("cpu = %s, want 1", cpu.String())
+	}
+	if memory := container.Resources.Requests[corev1.ResourceMemory]; memory.String() != "1Gi" {
+		t.Errorf("memory = %s, want 1Gi", memory.String())
+	}
+	if len(spec.Volumes) != 1 || len(container.VolumeMounts) != 1 {
+		t.Errorf("expected a volume and its mount, got %v and %v", spec.Volumes, container.VolumeMounts)
+	}
+
+	// Not selected: not patched
+	request.Namespace = "team-b"
+	if patch, err := c.applyProfiles(request); err != nil || patch != nil {
+		t.Fatalf("expected no patch in a namespace not selected, got %v, %v", patch, err)
+	}
+}
+
+func TestNotebookProfileCulling(t *testing.T) {
+	profile := newTestNotebookProfile("team-a")
+	objs := append(newNotebookProfileTestNamespaces(), profile,
+		// Idle kernels for two hours: stopped
+		newNotebookProfileTestNotebook("idle", nil),
+		// Busy kernel: active now
+		newNotebookProfileTestNotebook("busy", nil),
+		// Kernel active 30 minutes ago: kept
+		newNotebookProfileTestNotebook("recent", nil),
+		// Not reachable: kept
+		newNotebookProfileTestNotebook("unreachable", nil),
+		newNotebookProfileTestNotebook("stopped", map[string]string{notebookStoppedAnnotation: "2021-05-01T00:00:00Z"}))
+	kernels := map[string][]notebookKernel{
+		"idle":   {{ExecutionState: "idle", LastActivity: notebookProfileTestNow.Add(-2 * time.Hour)}},
+		"busy":   {{ExecutionState: "busy", LastActivity: notebookProfileTestNow.Add(-2 * time.Hour)}},
+		"recent": {{ExecutionState: "idle", LastActivity: notebookProfileTestNow.Add(-30 * time.Minute)}},
+	}
+	c := newNotebookProfileTestController(kernels, objs...)
+	if err := c.syncHandler("team-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	notebooks := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource("notebooks")).Namespace("team-a")
+	for name, want := range map[string]struct {
+		stopped      bool
+		lastActivity string
+	}{
+		"idle":        {true, "2021-05-01T10:00:00Z"},
+		"busy":        {false, "2021-05-01T12:00:00Z"},
+		"recent":      {false, "2021-05-01T11:30:00Z"},
+		"unreachable": {false, ""},
+	} {
+		notebook, err := notebooks.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, stopped := notebook.GetAnnotations()[notebookStoppedAnnotation]; stopped != want.stopped {
+			t.Errorf("stopped %s = %v, want %v", name, stopped, want.stopped)
+		}
+		if lastActivity := notebook.GetAnnotations()[notebookLastActivityAnnotation]; lastActivity != want.lastActivity {
+			t.Errorf("last activity of %s = %s, want %s", name, lastActivity, want.lastActivity)
+		}
+	}
+
+	updated, err := c.submarineclientset.SubmarineV1alpha1().SubmarineNotebookProfiles().Get(context.TODO(), "team-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := updated.Status; len(status.Namespaces) != 1 || status.Running != 3 || status.Stopped != 2 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
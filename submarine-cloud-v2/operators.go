@@ -0,0 +1,145 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// workloadOperator describes a workload operator that can be installed as a
+// subchart
+type workloadOperator struct {
+	name v1alpha1.SubmarineOperator
+	// resources are the kubeflow.org resources of the operator, which the
+	// server manages
+	resources []string
+	// deployments are the names of the deployments of compatible operators,
+	// i.e. of the subchart and of the Kubeflow installation
+	deployments []string
+}
+
+// workloadOperators are the operators that can be enabled in
+// spec.operators, in the order they are installed
+var workloadOperators = []workloadOperator{
+	{
+		name:        v1alpha1.OperatorNotebookController,
+		resources:   []string{"notebooks", "notebooks/status"},
+		deployments: []string{"notebook-controller-deployment"},
+	},
+	{
+		name:        v1alpha1.OperatorTFJob,
+		resources:   []string{"tfjobs", "tfjobs/status"},
+		deployments: []string{"tf-job-operator", "training-operator"},
+	},
+	{
+		name:        v1alpha1.OperatorPyTorchJob,
+		resources:   []string{"pytorchjobs", "pytorchjobs/status"},
+		deployments: []string{"pytorch-operator", "training-operator"},
+	},
+	{
+		name:        v1alpha1.OperatorMPIJob,
+		resources:   []string{"mpijobs", "mpijobs/status"},
+		deployments: []string{"mpi-operator", "training-operator"},
+	},
+	{
+		name:        v1alpha1.OperatorXGBoostJob,
+		resources:   []string{"xgboostjobs", "xgboostjobs/status"},
+		deployments: []string{"xgboost-operator", "training-operator"},
+	},
+}
+
+// defaultOperators are installed when spec.operators is empty
+var defaultOperators = []v1alpha1.SubmarineOperator{
+	v1alpha1.OperatorNotebookController,
+	v1alpha1.OperatorTFJob,
+	v1alpha1.OperatorPyTorchJob,
+}
+
+// enabledOperators returns the operators enabled by spec.operators, in the
+// order of workloadOperators. Unknown operators are ignored.
+func enabledOperators(submarine *v1alpha1.Submarine) []workloadOperator {
+	names := submarine.Spec.Operators
+	if len(names) == 0 {
+		names = defaultOperators
+	}
+	enabled := map[v1alpha1.SubmarineOperator]bool{}
+	for _, name := range names {
+		enabled[name] = true
+	}
+
+	var operators []workloadOperator
+	for _, operator := range workloadOperators {
+		if enabled[operator.name] {
+			operators = append(operators, operator)
+		}
+	}
+	return operators
+}
+
+// subChartsOf returns the subcharts of a Submarine: traefik and the charts
+// of the enabled operators
+func subChartsOf(submarine *v1alpha1.Submarine) []string {
+	charts := []string{"traefik"}
+	for _, operator := range enabledOperators(submarine) {
+		charts = append(charts, string(operator.name))
+	}
+	return charts
+}
+
+// operatorResources returns the kubeflow.org resources of the enabled
+// operators
+func operatorResources(submarine *v1alpha1.Submarine) []string {
+	var resources []string
+	for _, operator := range enabledOperators(submarine) {
+		resources = append(resources, operator.resources...)
+	}
+	return resources
+}
+
+// findOperator returns the namespace/name of a deployment of a compatible
+// operator running outside namespace. Operators watch every namespace, so
+// another one would process the jobs twice.
+func (c *Controller) findOperator(name string, namespace string) (string, error) {
+	var operator *workloadOperator
+	for i := range workloadOperators {
+		if string(workloadOperators[i].name) == name {
+			operator = &workloadOperators[i]
+		}
+	}
+	if operator == nil {
+		return "", nil
+	}
+
+	deployments, err := c.deploymentLister.List(labels.Everything())
+	if err != nil {
+		return "", err
+	}
+	for _, deployment := range deployments {
+		if deployment.Namespace == namespace {
+			continue
+		}
+		for _, deploymentName := range operator.deployments {
+			if deployment.Name == deploymentName {
+				return deployment.Namespace + "/" + deployment.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
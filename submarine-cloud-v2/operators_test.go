@@ -0,0 +1,65 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSubChartsOf(t *testing.T) {
+	submarine := &v1alpha1.Submarine{}
+	if charts := subChartsOf(submarine); !reflect.DeepEqual(charts, []string{"traefik", "notebook-controller", "tfjob", "pytorchjob"}) {
+		t.Errorf("subChartsOf() = %q, expected the default operators", charts)
+	}
+
+	submarine.Spec.Operators = []v1alpha1.SubmarineOperator{v1alpha1.OperatorXGBoostJob, "unknown", v1alpha1.OperatorMPIJob, v1alpha1.OperatorMPIJob}
+	if charts := subChartsOf(submarine); !reflect.DeepEqual(charts, []string{"traefik", "mpijob", "xgboostjob"}) {
+		t.Errorf("subChartsOf() = %q", charts)
+	}
+	if resources := operatorResources(submarine); !reflect.DeepEqual(resources, []string{"mpijobs", "mpijobs/status", "xgboostjobs", "xgboostjobs/status"}) {
+		t.Errorf("operatorResources() = %q", resources)
+	}
+}
+
+func TestFindOperator(t *testing.T) {
+	c, _, _ := newTestController(t, testControllerOptions{objects: []runtime.Object{&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "pytorch-operator", Namespace: "submarine-user-test"}}, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "training-operator", Namespace: "kubeflow"}}}})
+
+	if operator, err := c.findOperator("pytorchjob", "kubeflow"); err != nil || operator != "submarine-user-test/pytorch-operator" {
+		t.Errorf("findOperator() = %q, %v, expected submarine-user-test/pytorch-operator", operator, err)
+	}
+	// Deployments in the own namespace belong to its subcharts and are skipped
+	if operator, err := c.findOperator("pytorchjob", "submarine-user-test"); err != nil || operator != "kubeflow/training-operator" {
+		t.Errorf("findOperator() = %q, %v, expected kubeflow/training-operator", operator, err)
+	}
+	if operator, err := c.findOperator("notebook-controller", "submarine-user-test"); err != nil || operator != "" {
+		t.Errorf("findOperator() = %q, %v, expected no operator", operator, err)
+	}
+	if operator, err := c.findOperator("traefik", "submarine-user-test"); err != nil || operator != "" {
+		t.Errorf("findOperator() = %q, %v, expected traefik to be no operator", operator, err)
+	}
+	if operator, err := c.findOperator("mpijob", "submarine-user-test"); err != nil || operator != "kubeflow/training-operator" {
+		t.Errorf("findOperator() = %q, %v, expected kubeflow/training-operator", operator, err)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package charts
+
+import (
+	"bytes"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sourceDir is the directory the charts are copied from
+const sourceDir = "../../../helm-charts/submarine/charts"
+
+// TestChartsUpToDate fails when the embedded charts differ from
+// helm-charts/submarine/charts. Run "make charts" to copy them again.
+func TestChartsUpToDate(t *testing.T) {
+	if _, err := os.Stat(sourceDir); err != nil {
+		t.Skipf("%s not found", sourceDir)
+	}
+
+	err := filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		expected, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		actual, err := fs.ReadFile(FS, filepath.ToSlash(name))
+		if err != nil {
+			t.Errorf("%s is not embedded, run \"make charts\": %v", name, err)
+			return nil
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("%s differs from %s, run \"make charts\"", name, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
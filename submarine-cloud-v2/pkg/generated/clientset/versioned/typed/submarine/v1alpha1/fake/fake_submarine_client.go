@@ -34,6 +34,26 @@ func (c *FakeSubmarineV1alpha1) Submarines(namespace string) v1alpha1.SubmarineI
 	return &FakeSubmarines{c, namespace}
 }
 
+func (c *FakeSubmarineV1alpha1) SubmarineExperiments(namespace string) v1alpha1.SubmarineExperimentInterface {
+	return &FakeSubmarineExperiments{c, namespace}
+}
+
+func (c *FakeSubmarineV1alpha1) SubmarineNotebookProfiles() v1alpha1.SubmarineNotebookProfileInterface {
+	return &FakeSubmarineNotebookProfiles{c}
+}
+
+func (c *FakeSubmarineV1alpha1) SubmarineSweeps(namespace string) v1alpha1.SubmarineSweepInterface {
+	return &FakeSubmarineSweeps{c, namespace}
+}
+
+func (c *FakeSubmarineV1alpha1) SubmarineTenants() v1alpha1.SubmarineTenantInterface {
+	return &FakeSubmarineTenants{c}
+}
+
+func (c *FakeSubmarineV1alpha1) SubmarineTensorboards(namespace string) v1alpha1.SubmarineTensorboardInterface {
+	return &FakeSubmarineTensorboards{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeSubmarineV1alpha1) RESTClient() rest.Interface {
@@ -20,3 +20,13 @@
 package v1alpha1
 
 type SubmarineExpansion interface{}
+
+type SubmarineExperimentExpansion interface{}
+
+type SubmarineNotebookProfileExpansion interface{}
+
+type SubmarineSweepExpansion interface{}
+
+type SubmarineTenantExpansion interface{}
+
+type SubmarineTensorboardExpansion interface{}
@@ -29,6 +29,11 @@ import (
 type SubmarineV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	SubmarinesGetter
+	SubmarineExperimentsGetter
+	SubmarineNotebookProfilesGetter
+	SubmarineSweepsGetter
+	SubmarineTenantsGetter
+	SubmarineTensorboardsGetter
 }
 
 // SubmarineV1alpha1Client is used to interact with features provided by the submarine.k8s.io group.
@@ -40,6 +45,26 @@ func (c *SubmarineV1alpha1Client) Submarines(namespace string) SubmarineInterfac
 	return newSubmarines(c, namespace)
 }
 
+func (c *SubmarineV1alpha1Client) SubmarineExperiments(namespace string) SubmarineExperimentInterface {
+	return newSubmarineExperiments(c, namespace)
+}
+
+func (c *SubmarineV1alpha1Client) SubmarineNotebookProfiles() SubmarineNotebookProfileInterface {
+	return newSubmarineNotebookProfiles(c)
+}
+
+func (c *SubmarineV1alpha1Client) SubmarineSweeps(namespace string) SubmarineSweepInterface {
+	return newSubmarineSweeps(c, namespace)
+}
+
+func (c *SubmarineV1alpha1Client) SubmarineTenants() SubmarineTenantInterface {
+	return newSubmarineTenants(c)
+}
+
+func (c *SubmarineV1alpha1Client) SubmarineTensorboards(namespace string) SubmarineTensorboardInterface {
+	return newSubmarineTensorboards(c, namespace)
+}
+
 // NewForConfig creates a new SubmarineV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*SubmarineV1alpha1Client, error) {
 	config := *c
@@ -56,6 +56,16 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=submarine.k8s.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("submarines"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Submarine().V1alpha1().Submarines().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("submarineexperiments"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Submarine().V1alpha1().SubmarineExperiments().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("submarinenotebookprofiles"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Submarine().V1alpha1().SubmarineNotebookProfiles().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("submarinesweeps"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Submarine().V1alpha1().SubmarineSweeps().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("submarinetenants"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Submarine().V1alpha1().SubmarineTenants().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("submarinetensorboards"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Submarine().V1alpha1().SubmarineTensorboards().Informer()}, nil
 
 	}
 
@@ -27,6 +27,16 @@ import (
 type Interface interface {
 	// Submarines returns a SubmarineInformer.
 	Submarines() SubmarineInformer
+	// SubmarineExperiments returns a SubmarineExperimentInformer.
+	SubmarineExperiments() SubmarineExperimentInformer
+	// SubmarineNotebookProfiles returns a SubmarineNotebookProfileInformer.
+	SubmarineNotebookProfiles() SubmarineNotebookProfileInformer
+	// SubmarineSweeps returns a SubmarineSweepInformer.
+	SubmarineSweeps() SubmarineSweepInformer
+	// SubmarineTenants returns a SubmarineTenantInformer.
+	SubmarineTenants() SubmarineTenantInformer
+	// SubmarineTensorboards returns a SubmarineTensorboardInformer.
+	SubmarineTensorboards() SubmarineTensorboardInformer
 }
 
 type version struct {
@@ -44,3 +54,28 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) Submarines() SubmarineInformer {
 	return &submarineInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// SubmarineExperiments returns a SubmarineExperimentInformer.
+func (v *version) SubmarineExperiments() SubmarineExperimentInformer {
+	return &submarineExperimentInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SubmarineNotebookProfiles returns a SubmarineNotebookProfileInformer.
+func (v *version) SubmarineNotebookProfiles() SubmarineNotebookProfileInformer {
+	return &submarineNotebookProfileInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// SubmarineSweeps returns a SubmarineSweepInformer.
+func (v *version) SubmarineSweeps() SubmarineSweepInformer {
+	return &submarineSweepInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SubmarineTenants returns a SubmarineTenantInformer.
+func (v *version) SubmarineTenants() SubmarineTenantInformer {
+	return &submarineTenantInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// SubmarineTensorboards returns a SubmarineTensorboardInformer.
+func (v *version) SubmarineTensorboards() SubmarineTensorboardInformer {
+	return &submarineTensorboardInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -26,3 +26,35 @@ type SubmarineListerExpansion interface{}
 // SubmarineNamespaceListerExpansion allows custom methods to be added to
 // SubmarineNamespaceLister.
 type SubmarineNamespaceListerExpansion interface{}
+
+// SubmarineExperimentListerExpansion allows custom methods to be added to
+// SubmarineExperimentLister.
+type SubmarineExperimentListerExpansion interface{}
+
+// SubmarineExperimentNamespaceListerExpansion allows custom methods to be added to
+// SubmarineExperimentNamespaceLister.
+type SubmarineExperimentNamespaceListerExpansion interface{}
+
+// SubmarineNotebookProfileListerExpansion allows custom methods to be added to
+// SubmarineNotebookProfileLister.
+type SubmarineNotebookProfileListerExpansion interface{}
+
+// SubmarineSweepListerExpansion allows custom methods to be added to
+// SubmarineSweepLister.
+type SubmarineSweepListerExpansion interface{}
+
+// SubmarineSweepNamespaceListerExpansion allows custom methods to be added to
+// SubmarineSweepNamespaceLister.
+type SubmarineSweepNamespaceListerExpansion interface{}
+
+// SubmarineTenantListerExpansion allows custom methods to be added to
+// SubmarineTenantLister.
+type SubmarineTenantListerExpansion interface{}
+
+// SubmarineTensorboardListerExpansion allows custom methods to be added to
+// SubmarineTensorboardLister.
+type SubmarineTensorboardListerExpansion interface{}
+
+// SubmarineTensorboardNamespaceListerExpansion allows custom methods to be added to
+// SubmarineTensorboardNamespaceLister.
+type SubmarineTensorboardNamespaceListerExpansion interface{}
@@ -36,13 +36,18 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"helm.sh/helm/v3/pkg/strvals"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 type HelmUninstallInfo struct {
@@ -50,6 +55,11 @@ type HelmUninstallInfo struct {
 	releaseName string
 }
 
+// ReleaseName returns the name of the release
+func (info HelmUninstallInfo) ReleaseName() string {
+	return info.releaseName
+}
+
 func HelmInstall(url string, repoName string, chartName string, releaseName string, namespace string, args map[string]string) HelmUninstallInfo {
 	var settings *cli.EnvSettings
 	os.Setenv("HELM_NAMESPACE", namespace)
@@ -63,13 +73,84 @@ func HelmInstall(url string, repoName string, chartName string, releaseName stri
 	return HelmUninstallInfo{config: cfg, releaseName: releaseName}
 }
 
-func HelmInstallLocalChart(chartName string, chartPath string, releaseName string, namespace string, args map[string]string) HelmUninstallInfo {
-	var settings *cli.EnvSettings
-	os.Setenv("HELM_NAMESPACE", namespace)
-	settings = cli.New()
-	// Install charts
-	cfg := InstallLocalChart(releaseName, chartName, chartPath, args, settings)
-	return HelmUninstallInfo{config: cfg, releaseName: releaseName}
+// HelmInstallChart installs the chart of source as releaseName, and returns
+// the release
+func HelmInstallChart(source ChartSource, releaseName string, namespace string, args map[string]string, options ReleaseOptions) (HelmUninstallInfo, *release.Release, error) {
+	actionConfig, err := newActionConfig(namespace)
+	if err != nil {
+		return HelmUninstallInfo{}, nil, err
+	}
+
+	client := action.NewInstall(actionConfig)
+	client.ReleaseName = releaseName
+	client.Namespace = namespace
+	// Replace reuses the name of an uninstalled release whose history is kept
+	client.Replace = true
+	client.Wait = options.Wait
+	client.Atomic = options.Atomic
+	client.Timeout = options.Timeout
+
+	vals := map[string]interface{}{}
+	if err := strvals.ParseInto(args["set"], vals); err != nil {
+		return HelmUninstallInfo{}, nil, errors.Wrap(err, "failed parsing --set data")
+	}
+
+	chartRequested, err := source.Load()
+	if err != nil {
+		return HelmUninstallInfo{}, nil, err
+	}
+	if validInstallableChart, err := isChartInstallable(chartRequested); !validInstallableChart {
+		return HelmUninstallInfo{}, nil, err
+	}
+
+	rel, err := client.Run(chartRequested, vals)
+	if err != nil {
+		return HelmUninstallInfo{}, nil, err
+	}
+	debug("%s", rel.Manifest)
+	return HelmUninstallInfo{config: actionConfig, releaseName: releaseName}, rel, nil
+}
+
+// GetRelease returns the latest revision of a release, or nil if the release
+// does not exist
+func GetRelease(releaseName string, namespace string) (*release.Release, error) {
+	actionConfig, err := newActionConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(releaseName)
+	if err == driver.ErrReleaseNotFound {
+		return nil, nil
+	}
+	return rel, err
+}
+
+// newActionConfig returns the configuration of the Helm actions in
+// namespace
+func newActionConfig(namespace string) (*action.Configuration, error) {
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(newRESTClientGetter(namespace), namespace, os.Getenv("HELM_DRIVER"), debug); err != nil {
+		return nil, err
+	}
+	return actionConfig, nil
+}
+
+// newRESTClientGetter returns the Kubernetes client configuration of the Helm
+// settings, scoped to namespace. The namespace is set on the returned flags
+// rather than through HELM_NAMESPACE, which is shared by the whole process
+// while the releases of several Submarines are operated concurrently.
+func newRESTClientGetter(namespace string) *genericclioptions.ConfigFlags {
+	settings := cli.New()
+	config := genericclioptions.NewConfigFlags(false)
+	config.Namespace = &namespace
+	config.Context = &settings.KubeContext
+	config.BearerToken = &settings.KubeToken
+	config.APIServer = &settings.KubeAPIServer
+	config.CAFile = &settings.KubeCaFile
+	config.KubeConfig = &settings.KubeConfig
+	config.Impersonate = &settings.KubeAsUser
+	config.ImpersonateGroup = &settings.KubeAsGroups
+	return config
 }
 
 func HelmUninstall(info HelmUninstallInfo) error {
@@ -251,98 +332,49 @@ func InstallChart(releaseName string, repo string, chart string, args map[string
 	return actionConfig
 }
 
-// InstallChart
-func InstallLocalChart(releaseName string, chart string, chartPath string, args map[string]string, settings *cli.EnvSettings) *action.Configuration {
-	actionConfig := new(action.Configuration)
-	if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), os.Getenv("HELM_DRIVER"), debug); err != nil {
-		log.Fatal(err)
+// TemplateChart renders the manifests of the chart of source the same way as
+// "helm template" does, without contacting a cluster.
+func TemplateChart(source ChartSource, releaseName string, namespace string, args map[string]string) (string, error) {
+	actionConfig := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          debug,
 	}
 
 	client := action.NewInstall(actionConfig)
-
-	if client.Version == "" && client.Devel {
-		client.Version = ">0.0.0-0"
-	}
-
+	client.DryRun = true
+	client.ClientOnly = true
+	client.Replace = true
+	client.IncludeCRDs = true
 	client.ReleaseName = releaseName
+	client.Namespace = namespace
 
-	debug("CHART PATH: %s\n", chartPath)
-
-	p := getter.All(settings)
-	valueOpts := &values.Options{}
-	vals, err := valueOpts.MergeValues(p)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Add args
+	vals := map[string]interface{}{}
 	if err := strvals.ParseInto(args["set"], vals); err != nil {
-		log.Fatal(errors.Wrap(err, "failed parsing --set data"))
+		return "", errors.Wrap(err, "failed parsing --set data")
 	}
 
-	// Check chart dependencies to make sure all are present in /charts
-	chartRequested, err := loader.Load(chartPath)
+	chartRequested, err := source.Load()
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
-	validInstallableChart, err := isChartInstallable(chartRequested)
-	if !validInstallableChart {
-		log.Fatal(err)
+	if validInstallableChart, err := isChartInstallable(chartRequested); !validInstallableChart {
+		return "", err
 	}
 
-	if req := chartRequested.Metadata.Dependencies; req != nil {
-		// If CheckDependencies returns an error, we have unfulfilled dependencies.
-		// As of Helm 2.4.0, this is treated as a stopping condition:
-		// https://github.com/helm/helm/issues/2209
-		if err := action.CheckDependencies(chartRequested, req); err != nil {
-			if client.DependencyUpdate {
-				man := &downloader.Manager{
-					Out:              os.Stdout,
-					ChartPath:        chartPath,
-					Keyring:          client.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          p,
-					RepositoryConfig: settings.RepositoryConfig,
-					RepositoryCache:  settings.RepositoryCache,
-				}
-				if err := man.Update(); err != nil {
-					log.Fatal(err)
-				}
-			} else {
-				log.Fatal(err)
-			}
-		}
-	}
-
-	client.Namespace = settings.Namespace()
 	release, err := client.Run(chartRequested, vals)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	fmt.Println(release.Manifest)
-	return actionConfig
-}
-
-func CheckRelease(releaseName string, namespace string) bool {
-	debug("[CheckRelease] %s %s\n", releaseName, namespace)
-	var settings *cli.EnvSettings
-	os.Setenv("HELM_NAMESPACE", namespace)
-	settings = cli.New()
 
-	actionConfig := new(action.Configuration)
-	if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), os.Getenv("HELM_DRIVER"), debug); err != nil {
-		log.Fatal(err)
+	var manifests strings.Builder
+	manifests.WriteString(strings.TrimSpace(release.Manifest))
+	for _, hook := range release.Hooks {
+		fmt.Fprintf(&manifests, "\n---\n# Source: %s\n%s", hook.Path, strings.TrimSpace(hook.Manifest))
 	}
-
-	histClient := action.NewHistory(actionConfig)
-	if _, err := histClient.Run(releaseName); err == driver.ErrReleaseNotFound {
-		debug("		The release %s does not exist.\n", releaseName)
-		return false
-	}
-
-	debug("		The release %s exists.\n", releaseName)
-	return true
+	return manifests.String(), nil
 }
 
 func isChartInstallable(ch *chart.Chart) (bool, error) {
@@ -0,0 +1,53 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Run with -race: the releases of several Submarines are operated from
+// concurrent goroutines, each in the namespace of its Submarine
+func TestNewRESTClientGetterConcurrentNamespaces(t *testing.T) {
+	namespaces := []string{"submarine-a", "submarine-b"}
+	errs := make(chan error, len(namespaces)*50)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, namespace := range namespaces {
+			wg.Add(1)
+			go func(namespace string) {
+				defer wg.Done()
+				got, _, err := newRESTClientGetter(namespace).ToRawKubeConfigLoader().Namespace()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if got != namespace {
+					errs <- fmt.Errorf("namespace = %q, want %q", got, namespace)
+				}
+			}(namespace)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/strvals"
+)
+
+// ReleaseOptions are the options of installs, upgrades and rollbacks
+type ReleaseOptions struct {
+	// Wait waits until the resources of the release are ready
+	Wait bool
+	// Atomic uninstalls a failed install, and rolls back a failed upgrade
+	Atomic bool
+	// Timeout bounds each operation, and how long a release may stay pending
+	Timeout time.Duration
+}
+
+// HelmUpgradeChart upgrades the release releaseName to the chart of source,
+// and returns the release
+func HelmUpgradeChart(source ChartSource, releaseName string, namespace string, args map[string]string, options ReleaseOptions) (*release.Release, error) {
+	actionConfig, err := newActionConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	client := action.NewUpgrade(actionConfig)
+	client.Namespace = namespace
+	client.Wait = options.Wait
+	client.Atomic = options.Atomic
+	client.Timeout = options.Timeout
+
+	vals := map[string]interface{}{}
+	if err := strvals.ParseInto(args["set"], vals); err != nil {
+		return nil, errors.Wrap(err, "failed parsing --set data")
+	}
+
+	chartRequested, err := source.Load()
+	if err != nil {
+		return nil, err
+	}
+	if validInstallableChart, err := isChartInstallable(chartRequested); !validInstallableChart {
+		return nil, err
+	}
+
+	rel, err := client.Run(releaseName, chartRequested, vals)
+	if err != nil {
+		return nil, err
+	}
+	debug("%s", rel.Manifest)
+	return rel, nil
+}
+
+// HelmRollback rolls the release releaseName back to revision
+func HelmRollback(releaseName string, namespace string, revision int, options ReleaseOptions) error {
+	actionConfig, err := newActionConfig(namespace)
+	if err != nil {
+		return err
+	}
+
+	client := action.NewRollback(actionConfig)
+	client.Version = revision
+	client.Wait = options.Wait
+	client.Timeout = options.Timeout
+	client.CleanupOnFail = true
+	return client.Run(releaseName)
+}
+
+// HelmUninstallRelease uninstalls the release releaseName
+func HelmUninstallRelease(releaseName string, namespace string) error {
+	actionConfig, err := newActionConfig(namespace)
+	if err != nil {
+		return err
+	}
+	return HelmUninstall(HelmUninstallInfo{config: actionConfig, releaseName: releaseName})
+}
+
+// LastDeployedRevision returns the last revision of a release that was
+// deployed successfully, or 0 if there is none
+func LastDeployedRevision(releaseName string, namespace string) (int, error) {
+	actionConfig, err := newActionConfig(namespace)
+	if err != nil {
+		return 0, err
+	}
+	history, err := action.NewHistory(actionConfig).Run(releaseName)
+	if err != nil {
+		return 0, err
+	}
+	return lastDeployedRevision(history), nil
+}
+
+func lastDeployedRevision(history []*release.Release) int {
+	revision := 0
+	for _, rel := range history {
+		if rel.Info == nil || rel.Version <= revision {
+			continue
+		}
+		// A release is superseded once a later revision was deployed
+		switch rel.Info.Status {
+		case release.StatusDeployed, release.StatusSuperseded:
+			revision = rel.Version
+		}
+	}
+	return revision
+}
+
+// ValuesChanged reports whether the values of args differ from the values a
+// release was installed or upgraded with
+func ValuesChanged(rel *release.Release, args map[string]string) (bool, error) {
+	vals := map[string]interface{}{}
+	if err := strvals.ParseInto(args["set"], vals); err != nil {
+		return false, errors.Wrap(err, "failed parsing --set data")
+	}
+	config := rel.Config
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	// Compare the JSON, as stored releases decode numbers as float64
+	desired, err := json.Marshal(vals)
+	if err != nil {
+		return false, err
+	}
+	actual, err := json.Marshal(config)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(desired, actual), nil
+}
+
+// IsStuck returns whether a release has been pending for longer than
+// timeout, e.g. because the operator was restarted during an install
+func IsStuck(rel *release.Release, timeout time.Duration) bool {
+	if rel.Info == nil || !rel.Info.Status.IsPending() {
+		return false
+	}
+	return time.Since(rel.Info.LastDeployed.Time) > timeout
+}
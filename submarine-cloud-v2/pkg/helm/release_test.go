@@ -0,0 +1,79 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func newTestRelease(version int, status release.Status, lastDeployed time.Time) *release.Release {
+	return &release.Release{
+		Version: version,
+		Info:    &release.Info{Status: status, LastDeployed: helmtime.Time{Time: lastDeployed}},
+	}
+}
+
+func TestLastDeployedRevision(t *testing.T) {
+	now := time.Now()
+	history := []*release.Release{
+		newTestRelease(3, release.StatusFailed, now),
+		newTestRelease(1, release.StatusSuperseded, now),
+		newTestRelease(2, release.StatusSuperseded, now),
+		newTestRelease(4, release.StatusPendingUpgrade, now),
+	}
+	if revision := lastDeployedRevision(history); revision != 2 {
+		t.Errorf("lastDeployedRevision() = %d, expected 2", revision)
+	}
+	if revision := lastDeployedRevision(history[:1]); revision != 0 {
+		t.Errorf("lastDeployedRevision() = %d, expected no revision", revision)
+	}
+}
+
+func TestIsStuck(t *testing.T) {
+	timeout := 5 * time.Minute
+	if IsStuck(newTestRelease(1, release.StatusPendingInstall, time.Now()), timeout) {
+		t.Errorf("a recent pending release should not be stuck")
+	}
+	if !IsStuck(newTestRelease(1, release.StatusPendingInstall, time.Now().Add(-time.Hour)), timeout) {
+		t.Errorf("a release pending for an hour should be stuck")
+	}
+	if IsStuck(newTestRelease(1, release.StatusFailed, time.Now().Add(-time.Hour)), timeout) {
+		t.Errorf("a failed release is not pending")
+	}
+}
+
+func TestValuesChanged(t *testing.T) {
+	// Stored releases decode their values from JSON
+	rel := &release.Release{Config: map[string]interface{}{
+		"image": "traefik:2.2.8",
+		"ports": map[string]interface{}{"metrics": map[string]interface{}{"port": float64(9100), "expose": false}},
+	}}
+	if changed, err := ValuesChanged(rel, map[string]string{"set": "image=traefik:2.2.8,ports.metrics.port=9100,ports.metrics.expose=false"}); err != nil || changed {
+		t.Errorf("ValuesChanged() = %v, %v, expected the same values", changed, err)
+	}
+	if changed, err := ValuesChanged(rel, map[string]string{"set": "image=traefik:2.2.8"}); err != nil || !changed {
+		t.Errorf("ValuesChanged() = %v, %v, expected changed values", changed, err)
+	}
+	if changed, err := ValuesChanged(&release.Release{}, map[string]string{"set": ""}); err != nil || changed {
+		t.Errorf("ValuesChanged() = %v, %v, expected no values", changed, err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+// ChartSource is where a chart is loaded from
+type ChartSource interface {
+	// Load returns the chart
+	Load() (*chart.Chart, error)
+	// String describes the source, e.g. for the status of a Submarine
+	String() string
+}
+
+// EmbeddedChart loads the chart in the directory Name of FS, e.g. of the
+// charts embedded in the binary
+type EmbeddedChart struct {
+	FS   fs.FS
+	Name string
+}
+
+func (c EmbeddedChart) Load() (*chart.Chart, error) {
+	var files []*loader.BufferedFile
+	err := fs.WalkDir(c.FS, c.Name, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(c.FS, name)
+		if err != nil {
+			return err
+		}
+		files = append(files, &loader.BufferedFile{Name: name[len(c.Name)+1:], Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading embedded chart %s: %v", c.Name, err)
+	}
+	return loader.LoadFiles(files)
+}
+
+func (c EmbeddedChart) String() string {
+	return "embedded"
+}
+
+// LocalChart loads the chart in the directory or archive Path
+type LocalChart struct {
+	Path string
+}
+
+func (c LocalChart) Load() (*chart.Chart, error) {
+	return loader.Load(c.Path)
+}
+
+func (c LocalChart) String() string {
+	return c.Path
+}
+
+// RepoChart downloads the chart Name from the chart repository at URL. An
+// empty Version selects the latest version.
+type RepoChart struct {
+	URL     string
+	Name    string
+	Version string
+}
+
+func (c RepoChart) Load() (*chart.Chart, error) {
+	options := action.ChartPathOptions{RepoURL: c.URL, Version: c.Version}
+	chartPath, err := options.LocateChart(c.Name, cli.New())
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(chartPath)
+}
+
+func (c RepoChart) String() string {
+	source := strings.TrimSuffix(c.URL, "/") + "/" + c.Name
+	if c.Version != "" {
+		source += "@" + c.Version
+	}
+	return source
+}
@@ -15,93 +15,246 @@
  * limitations under the License.
  */
 
-// Reference: https://github.com/minio/operator/blob/master/kubectl-minio/cmd/proxy.go#L131
-
 package k8sutil
 
 import (
 	"context"
 	"fmt"
-	"github.com/fatih/color"
-	"io"
-	"log"
-	"os/exec"
+	"io/ioutil"
+	"net/http"
 	"strconv"
 	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// How often the backing pod is checked while forwarding
+	podCheckInterval = 2 * time.Second
+	// How long to wait before reconnecting after the forwarding stopped
+	reconnectInterval = time.Second
 )
 
-// run the command inside a goroutine, return a channel that closes then the command dies
-func ServicePortForwardPort(ctx context.Context, namespace string, service string, localPort int, remotePort int, dcolor color.Attribute) *exec.Cmd {
-	// service we are going to forward
-	serviceName := fmt.Sprintf("service/%s", service)
-	// command to run
-	portStr := strconv.Itoa(localPort) + ":" + strconv.Itoa(remotePort)
-	cmd := exec.CommandContext(ctx, "kubectl", "port-forward", "--address", "0.0.0.0", "-n", namespace, serviceName, portStr)
-
-	go func(cmd *exec.Cmd) {
-		// prepare to capture the output
-		var errStdout, errStderr error
-		stdoutIn, _ := cmd.StdoutPipe()
-		stderrIn, _ := cmd.StderrPipe()
-		err := cmd.Start()
-		if err != nil {
-			log.Fatalf("cmd.Start() failed with '%s'\n", err)
+// PortForwarder forwards a local port to a port of a Service, the same way
+// as "kubectl port-forward service/<name>". The connection goes to one ready
+// pod backing the Service. When that pod goes away, PortForwarder picks
+// another ready pod and reconnects on the same local port.
+type PortForwarder struct {
+	config *rest.Config
+	client kubernetes.Interface
+
+	namespace  string
+	service    string
+	address    string
+	localPort  int
+	remotePort int
+
+	// Ready is closed once the local port is listening for the first time
+	Ready chan struct{}
+	// Errors receives the errors that caused a reconnect. Errors are dropped
+	// if nobody is receiving.
+	Errors chan error
+
+	readyOnce sync.Once
+	mu        sync.Mutex
+}
+
+// NewServicePortForwarder returns a PortForwarder for port remotePort of the
+// Service namespace/service. client resolves the Service to its pods, and
+// config opens the connection to the pod. A localPort of 0 picks a free port,
+// which is returned by LocalPort once Ready is closed. An empty address
+// listens on localhost only, unlike "kubectl port-forward --address 0.0.0.0"
+// which was used before; pass 0.0.0.0 to listen on every interface.
+func NewServicePortForwarder(config *rest.Config, client kubernetes.Interface, namespace string, service string, address string, localPort int, remotePort int) *PortForwarder {
+	if address == "" {
+		address = "localhost"
+	}
+	return &PortForwarder{
+		config:     config,
+		client:     client,
+		namespace:  namespace,
+		service:    service,
+		address:    address,
+		localPort:  localPort,
+		remotePort: remotePort,
+		Ready:      make(chan struct{}),
+		Errors:     make(chan error, 1),
+	}
+}
+
+// LocalPort returns the local port being listened on. It is only meaningful
+// after Ready is closed.
+func (f *PortForwarder) LocalPort() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.localPort
+}
+
+// Run forwards the port until ctx is cancelled. It reconnects whenever the
+// connection to the pod is lost, and only returns once ctx is done.
+func (f *PortForwarder) Run(ctx context.Context) error {
+	for {
+		err := f.forwardOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("lost connection to service %s/%s", f.namespace, f.service)
+		}
+		klog.Warningf("[PortForwarder] %s, reconnecting", err.Error())
+		select {
+		case f.Errors <- err:
+		default:
 		}
 
-		// cmd.Wait() should be called only after we finish reading
-		// from stdoutIn and stderrIn.
-		// wg ensures that we finish
-		var wg sync.WaitGroup
-		wg.Add(1)
-		go func() {
-			errStdout = copyAndCapture(stdoutIn, dcolor)
-			wg.Done()
-		}()
-
-		errStderr = copyAndCapture(stderrIn, dcolor)
-
-		wg.Wait()
-
-		err = cmd.Wait()
-		if err != nil {
-			if err.Error() == "signal: killed" {
-				log.Printf("Stop port-forward\n")
-			} else {
-				log.Printf("cmd.Run() failed with %s\n", err.Error())
-			}
-			return
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectInterval):
 		}
-		if errStdout != nil || errStderr != nil {
-			log.Printf("failed to capture stdout or stderr\n")
+	}
+}
+
+// forwardOnce resolves the Service to a pod and forwards to it until the
+// pod is no longer ready, the connection is lost, or ctx is done.
+func (f *PortForwarder) forwardOnce(ctx context.Context) error {
+	pod, podPort, err := f.resolveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(f.config)
+	if err != nil {
+		return err
+	}
+	url := f.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	ports := []string{strconv.Itoa(f.LocalPort()) + ":" + strconv.Itoa(podPort)}
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{f.address}, ports, stopChan, readyChan, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return err
+	}
+
+	// Stop forwarding when ctx is done or the pod is no longer ready
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(stopChan) }) }
+	defer stop()
+	go func() {
+		select {
+		case <-ctx.Done():
+			stop()
+		case <-stopChan:
+		}
+	}()
+	go func() {
+		select {
+		case <-readyChan:
+		case <-stopChan:
 			return
 		}
-	}(cmd)
+		forwardedPorts, err := forwarder.GetPorts()
+		if err == nil && len(forwardedPorts) > 0 {
+			// Keep the same local port when reconnecting
+			f.mu.Lock()
+			f.localPort = int(forwardedPorts[0].Local)
+			f.mu.Unlock()
+		}
+		klog.Infof("[PortForwarder] Forwarding %s:%d -> pod %s/%s:%d", f.address, f.LocalPort(), pod.Namespace, pod.Name, podPort)
+		f.readyOnce.Do(func() { close(f.Ready) })
 
-	return cmd
+		wait.Until(func() {
+			current, err := f.client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return
+			}
+			if errors.IsNotFound(err) || current.UID != pod.UID || !isPodReady(current) {
+				klog.Infof("[PortForwarder] Pod %s/%s is gone or not ready", pod.Namespace, pod.Name)
+				stop()
+			}
+		}, podCheckInterval, stopChan)
+	}()
+
+	return forwarder.ForwardPorts()
 }
 
-// capture and print the output of the command
-func copyAndCapture(r io.Reader, dcolor color.Attribute) error {
-	var out []byte
-	buf := make([]byte, 1024)
-	for {
-		n, err := r.Read(buf[:])
-		if n > 0 {
-			d := buf[:n]
-			out = append(out, d...)
-			theColor := color.New(dcolor)
-			_, err := theColor.Print(string(d))
+// resolveService returns a ready pod backing the Service and the container
+// port that remotePort of the Service maps to on that pod.
+func (f *PortForwarder) resolveService(ctx context.Context) (*corev1.Pod, int, error) {
+	service, err := f.client.CoreV1().Services(f.namespace).Get(ctx, f.service, metav1.GetOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+	var servicePort *corev1.ServicePort
+	for i := range service.Spec.Ports {
+		if int(service.Spec.Ports[i].Port) == f.remotePort {
+			servicePort = &service.Spec.Ports[i]
+			break
+		}
+	}
+	if servicePort == nil {
+		return nil, 0, fmt.Errorf("service %s/%s has no port %d", f.namespace, f.service, f.remotePort)
+	}
 
-			if err != nil {
-				return err
+	// The Endpoints object lists the ready pods together with the resolved
+	// target port, which also covers named target ports.
+	endpoints, err := f.client.CoreV1().Endpoints(f.namespace).Get(ctx, f.service, metav1.GetOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, subset := range endpoints.Subsets {
+		podPort := 0
+		for _, port := range subset.Ports {
+			if port.Name == servicePort.Name {
+				podPort = int(port.Port)
+				break
 			}
 		}
-		if err != nil {
-			// Read returns io.EOF at the end of file, which is not an error for us
-			if err == io.EOF {
-				err = nil
+		if podPort == 0 {
+			continue
+		}
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+			pod, err := f.client.CoreV1().Pods(f.namespace).Get(ctx, address.TargetRef.Name, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			if isPodReady(pod) {
+				return pod, podPort, nil
 			}
-			return err
 		}
 	}
+
+	return nil, 0, fmt.Errorf("no ready pod backs service %s/%s", f.namespace, f.service)
+}
+
+// isPodReady returns whether the pod is running and has the Ready condition
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
 }
@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8sutil
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "submarine-user-test"
+
+func newTestService(port corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "workbench", Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{port}},
+	}
+}
+
+// newTestEndpoints returns the Endpoints of the workbench Service, with the
+// pods as ready addresses
+func newTestEndpoints(port corev1.EndpointPort, pods ...string) *corev1.Endpoints {
+	subset := corev1.EndpointSubset{Ports: []corev1.EndpointPort{port}}
+	for _, pod := range pods {
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{
+			IP:        "10.0.0.1",
+			TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: pod, Namespace: testNamespace},
+		})
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "workbench", Namespace: testNamespace},
+		Subsets:    []corev1.EndpointSubset{subset},
+	}
+}
+
+func newTestPod(name string, ready corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+		},
+	}
+}
+
+func TestResolveService(t *testing.T) {
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		remotePort int
+		pod        string
+		podPort    int
+		wantErr    bool
+	}{
+		{
+			name: "no ready endpoints",
+			objects: []runtime.Object{
+				newTestService(corev1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}),
+				newTestEndpoints(corev1.EndpointPort{Port: 8080}, "starting"),
+				newTestPod("starting", corev1.ConditionFalse),
+			},
+			remotePort: 8080,
+			wantErr:    true,
+		},
+		{
+			name: "named targetPort",
+			objects: []runtime.Object{
+				newTestService(corev1.ServicePort{Name: "http", Port: 80, TargetPort: intstr.FromString("web")}),
+				newTestEndpoints(corev1.EndpointPort{Name: "http", Port: 8000}, "starting", "ready"),
+				newTestPod("starting", corev1.ConditionFalse),
+				newTestPod("ready", corev1.ConditionTrue),
+			},
+			remotePort: 80,
+			pod:        "ready",
+			podPort:    8000,
+		},
+		{
+			name: "numeric targetPort",
+			objects: []runtime.Object{
+				newTestService(corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+				newTestEndpoints(corev1.EndpointPort{Port: 8080}, "ready"),
+				newTestPod("ready", corev1.ConditionTrue),
+			},
+			remotePort: 80,
+			pod:        "ready",
+			podPort:    8080,
+		},
+		{
+			name: "unknown port",
+			objects: []runtime.Object{
+				newTestService(corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+				newTestEndpoints(corev1.EndpointPort{Port: 8080}, "ready"),
+				newTestPod("ready", corev1.ConditionTrue),
+			},
+			remotePort: 443,
+			wantErr:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset(test.objects...)
+			forwarder := NewServicePortForwarder(nil, client, testNamespace, "workbench", "", 0, test.remotePort)
+			pod, podPort, err := forwarder.resolveService(context.TODO())
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got pod %s port %d", pod.Name, podPort)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pod.Name != test.pod || podPort != test.podPort {
+				t.Errorf("resolved pod %s port %d, want pod %s port %d", pod.Name, podPort, test.pod, test.podPort)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logarchive
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// S3Config is a bucket of an S3-compatible store, e.g. MinIO
+type S3Config struct {
+	// Endpoint is the URL of the store, e.g. http://minio:9000
+	Endpoint string
+	Bucket   string
+	// Region defaults to us-east-1, which MinIO uses by default
+	Region string
+	// Prefix is prepended to the keys
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store puts objects into an existing bucket with path-style requests
+// signed with AWS Signature Version 4
+type S3Store struct {
+	config S3Config
+	client *http.Client
+	// now returns the time of the signatures
+	now func() time.Time
+}
+
+// NewS3Store returns a Store writing into a bucket of an S3-compatible store
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q, expected http(s)://host[:port]", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("missing S3 bucket")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	return &S3Store{
+		config: config,
+		client: &http.Client{Timeout: time.Minute},
+		now:    time.Now,
+	}, nil
+}
+
+func (s *S3Store) objectKey(key string) string {
+	return strings.TrimPrefix(path.Join(s.config.Prefix, key), "/")
+}
+
+// Location returns s3://bucket/key
+func (s *S3Store) Location(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.config.Bucket, s.objectKey(key))
+}
+
+// Put uploads data as the object key. The payload of a signed request
+// needs its length and its hash up front, so data is spooled into a
+// temporary file rather than held in memory.
+func (s *S3Store) Put(ctx context.Context, key string, data io.Reader) error {
+	spool, err := ioutil.TempFile("", "logarchive-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), data)
+	if err != nil {
+		return err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	objectPath := "/" + s.config.Bucket + "/" + escapePath(s.objectKey(key))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, s.config.Endpoint+objectPath, ioutil.NopCloser(spool))
+	if err != nil {
+		return err
+	}
+	request.ContentLength = size
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	s.sign(request, objectPath, hex.EncodeToString(hash.Sum(nil)))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("PUT %s: %s: %s", s.Location(key), response.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds the headers of AWS Signature Version 4 to request
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3Store) sign(request *http.Request, canonicalPath string, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalPath,
+		"",
+		"host:" + request.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join([]string{date, s.config.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + s.config.SecretKey)
+	for _, part := range []string{date, s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath URI-encodes every byte of a key but the unreserved characters
+// and the slashes, as S3 expects in canonical requests
+func escapePath(key string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(key) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '.', b == '_', b == '~', b == '/':
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
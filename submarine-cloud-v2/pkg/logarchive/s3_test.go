@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logarchive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		experimentID string
+		want         string
+	}{
+		{"experiment-1620000000000-0001", "experiment-1620000000000-0001/mnist-worker-0/tensorflow.log"},
+		{"/a/../b/", "b/mnist-worker-0/tensorflow.log"},
+		{"../../etc", "etc/mnist-worker-0/tensorflow.log"},
+	}
+	for _, test := range tests {
+		if got := Key(test.experimentID, "mnist-worker-0", "tensorflow"); got != test.want {
+			t.Errorf("Key(%q) = %q, want %q", test.experimentID, got, test.want)
+		}
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	if got, want := escapePath("a b/c+d~e_f.log"), "a%20b/c%2Bd~e_f.log"; got != want {
+		t.Errorf("escapePath = %q, want %q", got, want)
+	}
+}
+
+func TestS3StorePut(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:  server.URL + "/",
+		Bucket:    "submarine",
+		Prefix:    "/logs",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.now = func() time.Time { return time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC) }
+	if err := store.Put(context.TODO(), "experiment-1/worker 0/tensorflow.log", strings.NewReader("epoch 1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != http.MethodPut || got.URL.EscapedPath() != "/submarine/logs/experiment-1/worker%200/tensorflow.log" {
+		t.Errorf("request = %s %s", got.Method, got.URL.EscapedPath())
+	}
+	if string(body) != "epoch 1\n" {
+		t.Errorf("body = %q", body)
+	}
+	sum := sha256.Sum256(body)
+	if hash := got.Header.Get("X-Amz-Content-Sha256"); hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("payload hash = %s", hash)
+	}
+	authorization := got.Header.Get("Authorization")
+	if prefix := "AWS4-HMAC-SHA256 Credential=access/20210501/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="; !strings.HasPrefix(authorization, prefix) {
+		t.Errorf("authorization = %s", authorization)
+	}
+	if location := store.Location("experiment-1/worker 0/tensorflow.log"); location != "s3://submarine/logs/experiment-1/worker 0/tensorflow.log" {
+		t.Errorf("location = %s", location)
+	}
+
+	if _, err := NewS3Store(S3Config{Endpoint: "minio:9000", Bucket: "submarine"}); err == nil {
+		t.Error("expected an endpoint without scheme to be invalid")
+	}
+}
+
+// TestS3StoreMinIO puts an object into a local MinIO, e.g.
+//
+//	docker run -p 9000:9000 -e MINIO_ROOT_USER=submarine -e MINIO_ROOT_PASSWORD=submarine \
+//	  --entrypoint sh minio/minio -c 'mkdir -p /data/submarine && minio server /data'
+//	MINIO_ENDPOINT=http://localhost:9000 MINIO_ACCESS_KEY=submarine MINIO_SECRET_KEY=submarine \
+//	  MINIO_BUCKET=submarine go test ./pkg/logarchive -run MinIO
+func TestS3StoreMinIO(t *testing.T) {
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_ENDPOINT is not set")
+	}
+	store, err := NewS3Store(S3Config{
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("MINIO_BUCKET"),
+		AccessKey: os.Getenv("MINIO_ACCESS_KEY"),
+		SecretKey: os.Getenv("MINIO_SECRET_KEY"),
+		Prefix:    "logarchive-test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.TODO(), Key("experiment-1", "mnist-worker-0", "tensorflow"), strings.NewReader("epoch 1\n")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package logarchive stores the logs of the containers of finished
+// experiments, so that they outlive the pods.
+package logarchive
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+)
+
+// Store writes logs under keys, e.g. into a bucket or a volume
+type Store interface {
+	// Put writes what is read from data under key, replacing what is there
+	Put(ctx context.Context, key string, data io.Reader) error
+	// Location returns the URL of key, e.g. s3://bucket/key
+	Location(key string) string
+}
+
+// Key returns the key of the log of a container of a pod of an experiment.
+// The logs of an experiment are under the directory ExperimentDir.
+func Key(experimentID string, pod string, container string) string {
+	return path.Join(ExperimentDir(experimentID), pod, container+".log")
+}
+
+// ExperimentDir returns the directory of the logs of an experiment, without
+// trailing slash
+func ExperimentDir(experimentID string) string {
+	return strings.Trim(path.Clean("/"+experimentID), "/")
+}
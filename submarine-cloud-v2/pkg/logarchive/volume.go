@@ -0,0 +1,119 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logarchive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// writeScript writes its stdin to the file $1, creating its directory
+const writeScript = `mkdir -p "$(dirname "$1")" && cat > "$1"`
+
+// VolumeConfig is a volume mounted by the running pods selected by Selector
+type VolumeConfig struct {
+	Namespace string
+	Selector  map[string]string
+	Container string
+	// MountPath is where the volume is mounted in Container
+	MountPath string
+	// ClaimName is the PersistentVolumeClaim of the volume, used in the
+	// locations
+	ClaimName string
+}
+
+// VolumeStore writes files into a volume through a pod mounting it, as
+// "kubectl exec -i" would, since the operator cannot mount the volumes of
+// other namespaces
+type VolumeStore struct {
+	config VolumeConfig
+	rest   *rest.Config
+	client kubernetes.Interface
+}
+
+// NewVolumeStore returns a Store writing into the volume of the pods of
+// config. The pods need sh, mkdir, dirname and cat.
+func NewVolumeStore(restConfig *rest.Config, client kubernetes.Interface, config VolumeConfig) *VolumeStore {
+	return &VolumeStore{config: config, rest: restConfig, client: client}
+}
+
+// Location returns pvc://claim/key
+func (s *VolumeStore) Location(key string) string {
+	return fmt.Sprintf("pvc://%s/%s", s.config.ClaimName, key)
+}
+
+// Put streams data to the file key of the volume
+func (s *VolumeStore) Put(ctx context.Context, key string, data io.Reader) error {
+	pod, err := s.runningPod(ctx)
+	if err != nil {
+		return err
+	}
+	request := s.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: s.config.Container,
+			Command:   []string{"sh", "-c", writeScript, "sh", path.Join(s.config.MountPath, key)},
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(s.rest, "POST", request.URL())
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{
+		Stdin:  data,
+		Stdout: &stderr,
+		Stderr: &stderr,
+	}); err != nil {
+		return fmt.Errorf("writing %s in pod %s/%s: %v: %s", key, pod.Namespace, pod.Name, err, stderr.String())
+	}
+	return nil
+}
+
+// runningPod returns a running pod mounting the volume
+func (s *VolumeStore) runningPod(ctx context.Context) (*corev1.Pod, error) {
+	pods, err := s.client.CoreV1().Pods(s.config.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(s.config.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("no running pod mounts volume %s in namespace %s", s.config.ClaimName, s.config.Namespace)
+}
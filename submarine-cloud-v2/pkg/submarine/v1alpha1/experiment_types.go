@@ -0,0 +1,134 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineExperiment is an experiment run as the TFJob or PyTorchJob of the
+// same name
+type SubmarineExperiment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubmarineExperimentSpec   `json:"spec"`
+	Status SubmarineExperimentStatus `json:"status"`
+}
+
+// ExperimentFramework is the framework of an experiment, i.e. the kind of
+// its job
+type ExperimentFramework string
+
+// Frameworks of a SubmarineExperiment
+const (
+	ExperimentFrameworkTensorFlow ExperimentFramework = "TensorFlow"
+	ExperimentFrameworkPyTorch    ExperimentFramework = "PyTorch"
+)
+
+// SubmarineExperimentSpec is the spec for a SubmarineExperiment resource
+type SubmarineExperimentSpec struct {
+	// Submarine is the Submarine in the same namespace whose operator runs
+	// the job
+	Submarine string              `json:"submarine"`
+	Framework ExperimentFramework `json:"framework"`
+	// Image, Command and Env are the defaults of every replica
+	Image   string          `json:"image,omitempty"`
+	Command []string        `json:"command,omitempty"`
+	Env     []corev1.EnvVar `json:"env,omitempty"`
+	// TerminationMessagePath is the file the containers write their final
+	// message to, e.g. the objective metric of a sweep trial
+	TerminationMessagePath string `json:"terminationMessagePath,omitempty"`
+	// Replicas are the replica specs by replica type, e.g. Ps, Worker and
+	// Chief for TensorFlow, Master and Worker for PyTorch
+	Replicas map[string]SubmarineExperimentReplicaSpec `json:"replicas"`
+}
+
+// SubmarineExperimentReplicaSpec is the spec of one replica type of an
+// experiment
+type SubmarineExperimentReplicaSpec struct {
+	// Replicas defaults to 1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Image, Command and Env override the ones of the experiment. Env is
+	// appended to the environment of the experiment.
+	Image     string                      `json:"image,omitempty"`
+	Command   []string                    `json:"command,omitempty"`
+	Env       []corev1.EnvVar             `json:"env,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ExperimentPhase is the phase of an experiment, i.e. the latest condition
+// of its job
+type ExperimentPhase string
+
+// Phases of a SubmarineExperiment
+const (
+	ExperimentPending    ExperimentPhase = "Pending"
+	ExperimentCreated    ExperimentPhase = "Created"
+	ExperimentRunning    ExperimentPhase = "Running"
+	ExperimentRestarting ExperimentPhase = "Restarting"
+	ExperimentSucceeded  ExperimentPhase = "Succeeded"
+	ExperimentFailed     ExperimentPhase = "Failed"
+)
+
+// SubmarineExperimentStatus is the status for a SubmarineExperiment resource
+type SubmarineExperimentStatus struct {
+	// JobKind and JobName identify the job of the experiment
+	JobKind        string          `json:"jobKind,omitempty"`
+	JobName        string          `json:"jobName,omitempty"`
+	Phase          ExperimentPhase `json:"phase,omitempty"`
+	StartTime      *metav1.Time    `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time    `json:"completionTime,omitempty"`
+	// Pods are the states of the pods of the job
+	Pods []SubmarineExperimentPodStatus `json:"pods,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SubmarineExperimentPodStatus is the state of a pod of an experiment
+type SubmarineExperimentPodStatus struct {
+	Name        string          `json:"name"`
+	ReplicaType string          `json:"replicaType,omitempty"`
+	Phase       corev1.PodPhase `json:"phase,omitempty"`
+	// Reason is the reason of the first waiting or terminated container,
+	// e.g. ImagePullBackOff or OOMKilled
+	Reason   string `json:"reason,omitempty"`
+	Restarts int32  `json:"restarts,omitempty"`
+}
+
+// Condition types of a SubmarineExperiment
+const (
+	// SubmarineExperimentSubmitted is true once the job of the experiment
+	// has been created
+	SubmarineExperimentSubmitted = "Submitted"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineExperimentList is a list of SubmarineExperiment resources
+type SubmarineExperimentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SubmarineExperiment `json:"items"`
+}
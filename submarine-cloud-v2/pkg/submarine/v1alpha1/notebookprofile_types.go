@@ -0,0 +1,83 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineNotebookProfile holds the defaults of the Notebooks of the
+// namespaces it selects, and stops them when they are idle
+type SubmarineNotebookProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubmarineNotebookProfileSpec   `json:"spec"`
+	Status SubmarineNotebookProfileStatus `json:"status"`
+}
+
+// SubmarineNotebookProfileSpec is the spec for a SubmarineNotebookProfile
+// resource. The defaults apply to the first container of the Notebooks
+// created in the selected namespaces, and never override what the Notebook
+// sets.
+type SubmarineNotebookProfileSpec struct {
+	// NamespaceSelector selects the namespaces of the Notebooks. An empty
+	// selector selects every namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Image is the image of a container without image
+	Image string `json:"image,omitempty"`
+	// Env are added unless the container has an env var of the same name
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Resources are the requests and limits of the resources the container
+	// does not request or limit
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Volumes are added unless the pod has a volume of the same name
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// VolumeMounts are added unless the container has a mount at the same
+	// path
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// IdleTimeoutSeconds stops the Notebooks whose kernels have been idle
+	// for longer. Unset keeps them running.
+	IdleTimeoutSeconds *int32 `json:"idleTimeoutSeconds,omitempty"`
+}
+
+// SubmarineNotebookProfileStatus is the status for a
+// SubmarineNotebookProfile resource
+type SubmarineNotebookProfileStatus struct {
+	// Namespaces are the selected namespaces
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Running and Stopped count the Notebooks of the selected namespaces
+	Running int32 `json:"running"`
+	Stopped int32 `json:"stopped"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineNotebookProfileList is a list of SubmarineNotebookProfile
+// resources
+type SubmarineNotebookProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SubmarineNotebookProfile `json:"items"`
+}
@@ -53,6 +53,16 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Submarine{},
 		&SubmarineList{},
+		&SubmarineTensorboard{},
+		&SubmarineTensorboardList{},
+		&SubmarineExperiment{},
+		&SubmarineExperimentList{},
+		&SubmarineSweep{},
+		&SubmarineSweepList{},
+		&SubmarineTenant{},
+		&SubmarineTenantList{},
+		&SubmarineNotebookProfile{},
+		&SubmarineNotebookProfileList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
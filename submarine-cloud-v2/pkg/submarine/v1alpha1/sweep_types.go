@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineSweep is a hyperparameter search, run as one SubmarineExperiment
+// per trial
+type SubmarineSweep struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubmarineSweepSpec   `json:"spec"`
+	Status SubmarineSweepStatus `json:"status"`
+}
+
+// SweepAlgorithm is how the parameters of the trials are chosen
+type SweepAlgorithm string
+
+// Algorithms of a SubmarineSweep
+const (
+	// SweepGrid runs every combination of the values of the parameters
+	SweepGrid SweepAlgorithm = "Grid"
+	// SweepRandom draws the value of every parameter at random, for
+	// MaxTrials trials
+	SweepRandom SweepAlgorithm = "Random"
+	// SweepList runs the parameter sets of Trials
+	SweepList SweepAlgorithm = "List"
+)
+
+// SubmarineSweepSpec is the spec for a SubmarineSweep resource
+type SubmarineSweepSpec struct {
+	// Template is the experiment of every trial. ${name} in its commands and
+	// environment values is replaced by the value of the parameter name.
+	Template  SubmarineExperimentSpec `json:"template"`
+	Algorithm SweepAlgorithm          `json:"algorithm"`
+	// Parameters are the parameter space of the Grid and Random algorithms
+	Parameters []SweepParameter `json:"parameters,omitempty"`
+	// Trials are the parameter sets of the List algorithm
+	Trials []map[string]string `json:"trials,omitempty"`
+	// MaxTrials limits the number of trials. It is required by the Random
+	// algorithm.
+	MaxTrials *int32 `json:"maxTrials,omitempty"`
+	// Parallelism is the maximum number of trials running at once, 1 by
+	// default
+	Parallelism *int32         `json:"parallelism,omitempty"`
+	Objective   SweepObjective `json:"objective"`
+}
+
+// SweepParameter is a parameter of a sweep, either the list of its Values or
+// the range from Min to Max. A range of integers yields integers.
+type SweepParameter struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values,omitempty"`
+	Min    string   `json:"min,omitempty"`
+	Max    string   `json:"max,omitempty"`
+	// Step divides the range into the values of the Grid algorithm
+	Step string `json:"step,omitempty"`
+}
+
+// SweepGoal is whether the objective metric is maximized or minimized
+type SweepGoal string
+
+// Goals of a SubmarineSweep
+const (
+	SweepMaximize SweepGoal = "Maximize"
+	SweepMinimize SweepGoal = "Minimize"
+)
+
+// SweepObjective is the metric the best trial is chosen by. It is read from
+// the lines "<metric>=<value>" or "<metric>: <value>" that the trial writes
+// to File or, without File, to its logs. The last value wins.
+type SweepObjective struct {
+	Metric string `json:"metric"`
+	// Goal is Maximize by default
+	Goal SweepGoal `json:"goal,omitempty"`
+	// File is written by the trial before it exits. It is read through the
+	// termination message of the containers, so it holds at most 4096 bytes.
+	File string `json:"file,omitempty"`
+	// LogPattern overrides the regular expression matching the value in
+	// the logs or the file, whose first group is the value
+	LogPattern string `json:"logPattern,omitempty"`
+}
+
+// SubmarineSweepStatus is the status for a SubmarineSweep resource
+type SubmarineSweepStatus struct {
+	// Trials are the trials created so far
+	Trials []SweepTrialStatus `json:"trials,omitempty"`
+	// TotalTrials is the number of trials of the sweep
+	TotalTrials int32 `json:"totalTrials,omitempty"`
+	Active      int32 `json:"active,omitempty"`
+	Succeeded   int32 `json:"succeeded,omitempty"`
+	Failed      int32 `json:"failed,omitempty"`
+	// BestTrial is the succeeded trial with the best objective
+	BestTrial      *SweepTrialStatus `json:"bestTrial,omitempty"`
+	CompletionTime *metav1.Time      `json:"completionTime,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SweepTrialStatus is the state of a trial of a sweep
+type SweepTrialStatus struct {
+	// Name is the name of the SubmarineExperiment of the trial
+	Name       string            `json:"name"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Phase      ExperimentPhase   `json:"phase,omitempty"`
+	// Objective is the value of the objective metric, once the trial
+	// succeeded
+	Objective string `json:"objective,omitempty"`
+}
+
+// Condition types of a SubmarineSweep
+const (
+	// SubmarineSweepComplete is true once every trial finished
+	SubmarineSweepComplete = "Complete"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineSweepList is a list of SubmarineSweep resources
+type SubmarineSweepList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SubmarineSweep `json:"items"`
+}
@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineTenant is a team of a Submarine, with its own namespace, quota
+// and permissions
+type SubmarineTenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubmarineTenantSpec   `json:"spec"`
+	Status SubmarineTenantStatus `json:"status"`
+}
+
+// SubmarineReference is the namespace and name of a Submarine
+type SubmarineReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// SubmarineTenantSpec is the spec for a SubmarineTenant resource
+type SubmarineTenantSpec struct {
+	// Submarine is the Submarine whose server manages the workloads of the
+	// tenant
+	Submarine SubmarineReference `json:"submarine"`
+	// Namespace is the namespace of the tenant, the name of the tenant by
+	// default. An existing namespace is taken over unless another tenant
+	// owns it.
+	Namespace string `json:"namespace,omitempty"`
+	// NamespaceLabels are added to the namespace
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	// Quota is the hard limits of the ResourceQuota of the namespace
+	Quota corev1.ResourceList `json:"quota,omitempty"`
+	// Limits are the limits of the LimitRange of the namespace, e.g. the
+	// default requests of the containers
+	Limits []corev1.LimitRangeItem `json:"limits,omitempty"`
+	// Members may manage the notebooks, jobs and experiments of the
+	// namespace
+	Members []rbacv1.Subject `json:"members,omitempty"`
+}
+
+// SubmarineTenantStatus is the status for a SubmarineTenant resource
+type SubmarineTenantStatus struct {
+	Namespace string `json:"namespace,omitempty"`
+	// Hard and Used are the limits and the usage of the ResourceQuota
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+	Used corev1.ResourceList `json:"used,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Condition types of a SubmarineTenant
+const (
+	// SubmarineTenantReady is true once the namespace and its objects are
+	// reconciled
+	SubmarineTenantReady = "Ready"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineTenantList is a list of SubmarineTenant resources
+type SubmarineTenantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SubmarineTenant `json:"items"`
+}
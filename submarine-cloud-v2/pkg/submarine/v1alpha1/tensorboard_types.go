@@ -0,0 +1,90 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineTensorboard is a dedicated Tensorboard for the logs of one
+// experiment, served at /tensorboard/<name>
+type SubmarineTensorboard struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubmarineTensorboardSpec   `json:"spec"`
+	Status SubmarineTensorboardStatus `json:"status"`
+}
+
+// SubmarineTensorboardSpec is the spec for a SubmarineTensorboard resource
+type SubmarineTensorboardSpec struct {
+	// Submarine is the Submarine in the same namespace whose tensorboard
+	// volume holds the logs
+	Submarine string `json:"submarine"`
+	// ExperimentID reads the logs of an experiment, i.e. the directory named
+	// after it. It takes precedence over LogPath.
+	ExperimentID string `json:"experimentId,omitempty"`
+	// LogPath is the directory of the logs, relative to the tensorboard
+	// volume. Empty reads every log of the volume.
+	LogPath string `json:"logPath,omitempty"`
+	// Image overrides the tensorboard image of the Submarine
+	Image string `json:"image,omitempty"`
+	// TTLSecondsAfterIdle deletes the SubmarineTensorboard once it has been
+	// idle for that long, i.e. since its creation or the time in its
+	// submarine.k8s.io/last-activity annotation. Unset keeps it forever.
+	TTLSecondsAfterIdle *int32 `json:"ttlSecondsAfterIdle,omitempty"`
+}
+
+// SubmarineTensorboardStatus is the status for a SubmarineTensorboard
+// resource
+type SubmarineTensorboardStatus struct {
+	// Path is the path of the Tensorboard on the workbench, i.e.
+	// /tensorboard/<name>
+	Path string `json:"path,omitempty"`
+	// LogPath is the directory of the logs, relative to the tensorboard
+	// volume
+	LogPath string `json:"logPath,omitempty"`
+	// LastActivityTime is the latest of the creation and of the
+	// submarine.k8s.io/last-activity annotation
+	LastActivityTime *metav1.Time `json:"lastActivityTime,omitempty"`
+	// ExpirationTime is when the idle SubmarineTensorboard is deleted
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Condition types of a SubmarineTensorboard
+const (
+	// SubmarineTensorboardReady is true once the Deployment of the
+	// Tensorboard is available
+	SubmarineTensorboardReady = "Ready"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SubmarineTensorboardList is a list of SubmarineTensorboard resources
+type SubmarineTensorboardList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SubmarineTensorboard `json:"items"`
+}
@@ -17,6 +17,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,8 +34,35 @@ type Submarine struct {
 }
 
 type SubmarineServer struct {
-	Image    string `json:"image"`
-	Replicas *int32 `json:"replicas"`
+	Image       string                      `json:"image"`
+	Replicas    *int32                      `json:"replicas"`
+	Autoscaling *SubmarineServerAutoscaling `json:"autoscaling,omitempty"`
+	// TopologySpreadConstraints overrides the default spreading of the
+	// server pods across nodes and zones
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	Config                    *SubmarineServerConfig            `json:"config,omitempty"`
+}
+
+// SubmarineServerConfig is rendered into the submarine-site.xml of
+// submarine-server. Any change of it triggers a rolling restart of the server.
+type SubmarineServerConfig struct {
+	// Properties are rendered as the <property> entries of submarine-site.xml
+	Properties map[string]string `json:"properties,omitempty"`
+	// SiteXML is a complete submarine-site.xml. It takes precedence over
+	// Properties.
+	SiteXML string `json:"siteXml,omitempty"`
+}
+
+// SubmarineServerAutoscaling configures a HorizontalPodAutoscaler for the
+// submarine-server Deployment. While it is enabled the HPA owns the replica
+// count: the Deployment starts from minReplicas, or from spec.server.replicas
+// when minReplicas is unset.
+type SubmarineServerAutoscaling struct {
+	Enabled                           *bool  `json:"enabled"`
+	MinReplicas                       *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas                       int32  `json:"maxReplicas"`
+	TargetCPUUtilizationPercentage    *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
 }
 
 type SubmarineDatabase struct {
@@ -42,11 +70,18 @@ type SubmarineDatabase struct {
 	Replicas                *int32 `json:"replicas"`
 	StorageSize             string `json:"storageSize"`
 	MysqlRootPasswordSecret string `json:"mysqlRootPasswordSecret"`
+	// TopologySpreadConstraints overrides the default spreading of the
+	// database pods across nodes and zones
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
-type SubmarineTensorboard struct {
+// SubmarineSharedTensorboard is the Tensorboard shared by the experiments of
+// a Submarine, served at /tensorboard
+type SubmarineSharedTensorboard struct {
 	Enabled     *bool  `json:"enabled"`
 	StorageSize string `json:"storageSize"`
+	// Image overrides tensorflow/tensorflow:1.11.0
+	Image string `json:"image,omitempty"`
 }
 
 type SubmarineMlflow struct {
@@ -63,18 +98,343 @@ type SubmarineStorage struct {
 
 // SubmarineSpec is the spec for a Submarine resource
 type SubmarineSpec struct {
-	Version     string                `json:"version"`
-	Server      *SubmarineServer      `json:"server"`
-	Database    *SubmarineDatabase    `json:"database"`
-	Tensorboard *SubmarineTensorboard `json:"tensorboard"`
-	Mlflow      *SubmarineMlflow      `json:"mlflow"`
-	Storage     *SubmarineStorage     `json:"storage"`
+	Version     string                      `json:"version"`
+	Server      *SubmarineServer            `json:"server"`
+	Database    *SubmarineDatabase          `json:"database"`
+	Tensorboard *SubmarineSharedTensorboard `json:"tensorboard"`
+	Mlflow      *SubmarineMlflow            `json:"mlflow"`
+	Storage     *SubmarineStorage           `json:"storage"`
+	// AdoptionPolicy decides what happens to an existing object that has
+	// the name of an object of the Submarine but is not owned by it
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// ImageRegistry replaces the registry of every default image, e.g. with
+	// a mirror of an air-gapped cluster. Images set explicitly are used as
+	// they are.
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+	// ImagePullSecrets are added to every pod of the Submarine and of the
+	// subcharts
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Images overrides the images of the subcharts
+	Images *SubmarineImages `json:"images,omitempty"`
+	// Suspend scales the server and the tensorboard to zero and pauses the
+	// reconcile until it is unset. Volumes and Helm releases are kept. The
+	// operator does not manage an mlflow Deployment, so there is none to
+	// scale.
+	Suspend bool `json:"suspend,omitempty"`
+	// SuspendDatabase scales the database to zero as well while the
+	// Submarine is suspended
+	SuspendDatabase bool `json:"suspendDatabase,omitempty"`
+	// Operators are the workload operators to install. Defaults to
+	// notebook-controller, tfjob and pytorchjob. An operator already running
+	// in another namespace is not installed again.
+	Operators []SubmarineOperator `json:"operators,omitempty"`
+	// Monitoring exposes the metrics of the server, the database and traefik
+	Monitoring *SubmarineMonitoring `json:"monitoring,omitempty"`
+	// Cleanup deletes finished jobs and stops idle notebooks in the
+	// namespace
+	Cleanup *SubmarineCleanup `json:"cleanup,omitempty"`
+	// Queue holds the TFJobs and PyTorchJobs created in the namespace until
+	// they fit in its capacity
+	Queue *SubmarineQueue `json:"queue,omitempty"`
+	// PrePull pulls the images of the experiments on the nodes before the
+	// experiments run
+	PrePull *SubmarinePrePull `json:"prePull,omitempty"`
+	// LogArchive copies the logs of the finished jobs of the namespace
+	// before their pods are gone
+	LogArchive *SubmarineLogArchive `json:"logArchive,omitempty"`
+}
+
+// SubmarineLogArchive copies the container logs of the pods of the finished
+// TFJobs and PyTorchJobs of the namespace into a bucket or a volume, under
+// <experiment ID>/<pod>/<container>.log, and records the location of the
+// directory of the experiment in the submarine.k8s.io/log-archive
+// annotation of the job. Exactly one of S3 and PersistentVolumeClaim is
+// set.
+type SubmarineLogArchive struct {
+	S3                    *SubmarineS3Archive     `json:"s3,omitempty"`
+	PersistentVolumeClaim *SubmarineVolumeArchive `json:"persistentVolumeClaim,omitempty"`
+}
+
+// SubmarineS3Archive is an existing bucket of an S3-compatible store, e.g.
+// MinIO
+type SubmarineS3Archive struct {
+	// Endpoint is the URL of the store, e.g. http://minio:9000
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	// Region defaults to us-east-1
+	Region string `json:"region,omitempty"`
+	// Prefix is prepended to the keys of the logs
+	Prefix string `json:"prefix,omitempty"`
+	// CredentialsSecret is a Secret of the namespace with the accessKey and
+	// the secretKey of the store
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// SubmarineVolumeArchive is a PersistentVolumeClaim of the namespace,
+// mounted by a pod that receives the logs
+type SubmarineVolumeArchive struct {
+	ClaimName string `json:"claimName"`
+}
+
+// SubmarinePrePull is a DaemonSet pulling images on the selected nodes. Its
+// pods run every image once, with sh, then stay idle.
+type SubmarinePrePull struct {
+	// Images are pulled along with the images of the environments
+	Images []string `json:"images,omitempty"`
+	// Environments pulls the images of the environments of the server, i.e.
+	// its /api/v1/environment API
+	Environments bool `json:"environments,omitempty"`
+	// NodeSelector selects the nodes pulling the images. Defaults to every
+	// node.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations let the images be pulled on tainted nodes, e.g. GPU nodes
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 }
 
+// SubmarineQueue is the admission queue of the jobs of the namespace. A job
+// is admitted once the requests of all its replicas fit in the capacity left
+// by the admitted jobs that have not finished. Queued jobs are admitted by
+// descending priority, i.e. their submarine.k8s.io/queue-priority
+// annotation, then in the order they were created. MPIJobs and XGBoostJobs
+// are not held, and count as admitted.
+type SubmarineQueue struct {
+	Enabled bool `json:"enabled"`
+	// Capacity limits the requests of the admitted jobs, e.g. cpu, memory
+	// and nvidia.com/gpu. Resources that are not listed are not limited.
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+}
+
+// SubmarineCleanup garbage collects the TFJobs, PyTorchJobs, MPIJobs and
+// XGBoostJobs of the Submarine: the jobs labelled with its name and, when it
+// is the only Submarine of its namespace, the unlabelled jobs. It also stops
+// the idle notebooks of the namespace. An unset TTL or timeout keeps the
+// objects.
+type SubmarineCleanup struct {
+	// SucceededJobTTLSeconds deletes a succeeded job, and its pods, that
+	// long after it completed
+	SucceededJobTTLSeconds *int32 `json:"succeededJobTTLSeconds,omitempty"`
+	// FailedJobTTLSeconds deletes a failed job, and its pods, that long
+	// after it failed
+	FailedJobTTLSeconds *int32 `json:"failedJobTTLSeconds,omitempty"`
+	// KeepJobs is the number of most recently finished jobs that are kept
+	// regardless of the TTLs
+	KeepJobs int32 `json:"keepJobs,omitempty"`
+	// NotebookIdleTimeoutSeconds stops a notebook whose last activity is
+	// that long ago. The notebook controller scales it to zero and keeps its
+	// volumes.
+	NotebookIdleTimeoutSeconds *int32 `json:"notebookIdleTimeoutSeconds,omitempty"`
+}
+
+// SubmarineMonitoring adds metrics ports to the server, the database and
+// traefik. When the Prometheus Operator is installed, ServiceMonitors and a
+// PodMonitor scrape them.
+type SubmarineMonitoring struct {
+	Enabled bool `json:"enabled"`
+	// Interval is the scrape interval, e.g. 30s. Defaults to the interval of
+	// Prometheus.
+	Interval string `json:"interval,omitempty"`
+	// Labels are added to the ServiceMonitors and the PodMonitor, e.g. to
+	// match the selectors of Prometheus
+	Labels map[string]string `json:"labels,omitempty"`
+	// ServerExporterImage overrides the JMX exporter sidecar of the server
+	ServerExporterImage string `json:"serverExporterImage,omitempty"`
+	// DatabaseExporterImage overrides the MySQL exporter sidecar of the
+	// database
+	DatabaseExporterImage string `json:"databaseExporterImage,omitempty"`
+}
+
+// SubmarineImages overrides the images installed by the subcharts
+type SubmarineImages struct {
+	Traefik            string `json:"traefik,omitempty"`
+	NotebookController string `json:"notebookController,omitempty"`
+	TFJob              string `json:"tfJob,omitempty"`
+	PyTorchJob         string `json:"pyTorchJob,omitempty"`
+	MPIJob             string `json:"mpiJob,omitempty"`
+	XGBoostJob         string `json:"xgboostJob,omitempty"`
+}
+
+// SubmarineOperator is a workload operator, installed with the subchart of
+// the same name
+type SubmarineOperator string
+
+const (
+	OperatorNotebookController SubmarineOperator = "notebook-controller"
+	OperatorTFJob              SubmarineOperator = "tfjob"
+	OperatorPyTorchJob         SubmarineOperator = "pytorchjob"
+	OperatorMPIJob             SubmarineOperator = "mpijob"
+	OperatorXGBoostJob         SubmarineOperator = "xgboostjob"
+)
+
+// AdoptionPolicy is the policy for existing objects that collide with the
+// objects of a Submarine
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyNever refuses to reconcile while an object of the same
+	// name exists. This is the default.
+	AdoptionPolicyNever AdoptionPolicy = "Never"
+	// AdoptionPolicyIfUnowned takes over objects that have no controller,
+	// such as the leftovers of an installation with the Helm chart. Objects
+	// controlled by something else are still refused.
+	AdoptionPolicyIfUnowned AdoptionPolicy = "IfUnowned"
+)
+
 // SubmarineStatus is the status for a Submarine resource
 type SubmarineStatus struct {
 	AvailableServerReplicas   int32 `json:"availableServerReplicas"`
 	AvailableDatabaseReplicas int32 `json:"availableDatabaseReplicas"`
+	// ServerSelector is the label selector of the submarine-server pods, used
+	// by the scale subresource.
+	ServerSelector string `json:"serverSelector,omitempty"`
+	// Conditions are the latest observations of the components of the
+	// Submarine. The "Ready" condition is true once every component is
+	// available.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Version is the version the server and the database run. It catches up
+	// with spec.version once an upgrade has succeeded.
+	Version string `json:"version,omitempty"`
+	// Upgrade is the progress of the latest upgrade
+	Upgrade *SubmarineUpgradeStatus `json:"upgrade,omitempty"`
+	// Suspension is the state of the latest suspension
+	Suspension *SubmarineSuspensionStatus `json:"suspension,omitempty"`
+	// Charts are the Helm releases of the subcharts used by the Submarine
+	Charts []SubmarineChartStatus `json:"charts,omitempty"`
+	// Queue summarizes the admission queue of the jobs
+	Queue *SubmarineQueueStatus `json:"queue,omitempty"`
+	// PrePull is the state of the images on the nodes pulling them
+	PrePull *SubmarinePrePullStatus `json:"prePull,omitempty"`
+}
+
+// SubmarinePrePullStatus is the state of the pre-pulled images
+type SubmarinePrePullStatus struct {
+	// Images are the pre-pulled images
+	Images []string `json:"images,omitempty"`
+	// EnvironmentImages are the images of the environments of the server at
+	// the latest successful sync
+	EnvironmentImages []string `json:"environmentImages,omitempty"`
+	// DesiredNodes is the number of nodes pulling the images, and ReadyNodes
+	// the number of nodes that pulled all of them
+	DesiredNodes int32 `json:"desiredNodes"`
+	ReadyNodes   int32 `json:"readyNodes"`
+	// Nodes are the states of the nodes, sorted by name
+	Nodes []SubmarineNodePullStatus `json:"nodes,omitempty"`
+}
+
+// SubmarineNodePullStatus is the state of the pre-pulled images on a node
+type SubmarineNodePullStatus struct {
+	Node string `json:"node"`
+	// Pulled is the number of images pulled on the node
+	Pulled int32 `json:"pulled"`
+	// Failed are the images that cannot be pulled, e.g. with ErrImagePull
+	Failed []string `json:"failed,omitempty"`
+}
+
+// SubmarineQueueStatus is the state of the admission queue
+type SubmarineQueueStatus struct {
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+	// Used are the requests of the admitted jobs that have not finished
+	Used     corev1.ResourceList `json:"used,omitempty"`
+	Admitted int32               `json:"admitted"`
+	Queued   int32               `json:"queued"`
+	// Jobs are the held jobs, in the order they are admitted
+	Jobs []SubmarineQueuedJob `json:"jobs,omitempty"`
+}
+
+// SubmarineQueuedJob is a job held by the admission queue
+type SubmarineQueuedJob struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Priority int32  `json:"priority,omitempty"`
+	// Position is the 1-based position in the queue, or 0 when the job
+	// can never fit in the capacity
+	Position int32         `json:"position"`
+	State    JobQueueState `json:"state"`
+}
+
+// JobQueueState is the state of a job in the admission queue, recorded in
+// its submarine.k8s.io/queue-state annotation
+type JobQueueState string
+
+const (
+	JobQueued   JobQueueState = "Queued"
+	JobAdmitted JobQueueState = "Admitted"
+	// JobExceedsCapacity is the state of a job whose requests exceed the
+	// whole capacity. It is held without blocking the jobs behind it.
+	JobExceedsCapacity JobQueueState = "ExceedsCapacity"
+)
+
+// Condition types of a Submarine
+const (
+	SubmarineReady             = "Ready"
+	SubmarineServerAvailable   = "ServerAvailable"
+	SubmarineDatabaseAvailable = "DatabaseAvailable"
+	// SubmarineUpgrading is true while an upgrade is in progress. It is
+	// false with the reason of the outcome otherwise, e.g. UpgradeFailed or
+	// DowngradeBlocked.
+	SubmarineUpgrading = "Upgrading"
+	// SubmarineSuspended is true while the components are scaled to zero
+	SubmarineSuspended = "Suspended"
+	// SubmarineMonitored is true while ServiceMonitors and a PodMonitor
+	// scrape the metrics of the Submarine. It is false with the reason
+	// PrometheusOperatorNotFound when the monitoring.coreos.com CRDs are
+	// missing.
+	SubmarineMonitored = "Monitored"
+)
+
+// SubmarineUpgradeStatus records an upgrade from one version to another. A
+// change of spec.version backs up the database, migrates its schema with the
+// new database image and then rolls the server and the database.
+type SubmarineUpgradeStatus struct {
+	FromVersion string                `json:"fromVersion"`
+	ToVersion   string                `json:"toVersion"`
+	Phase       SubmarineUpgradePhase `json:"phase"`
+	// Backup is the file of the database dump taken before the upgrade, in
+	// the backups directory of the database volume
+	Backup         string       `json:"backup,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	Message        string       `json:"message,omitempty"`
+}
+
+// SubmarineUpgradePhase is a step of an upgrade
+type SubmarineUpgradePhase string
+
+const (
+	UpgradePhaseBackingUp SubmarineUpgradePhase = "BackingUp"
+	UpgradePhaseMigrating SubmarineUpgradePhase = "Migrating"
+	// UpgradePhaseRollingOut waits for the server to run the new version
+	UpgradePhaseRollingOut SubmarineUpgradePhase = "RollingOut"
+	UpgradePhaseSucceeded  SubmarineUpgradePhase = "Succeeded"
+	// UpgradePhaseFailed stops the upgrade. Deleting the failed Job retries
+	// it.
+	UpgradePhaseFailed SubmarineUpgradePhase = "Failed"
+)
+
+// SubmarineSuspensionStatus records the suspension of a Submarine
+type SubmarineSuspensionStatus struct {
+	Suspended bool `json:"suspended"`
+	// Replicas are the replicas of the deployments before they were scaled
+	// to zero, by name of the deployment. They are restored on resume.
+	Replicas    map[string]int32 `json:"replicas,omitempty"`
+	SuspendTime *metav1.Time     `json:"suspendTime,omitempty"`
+	ResumeTime  *metav1.Time     `json:"resumeTime,omitempty"`
+}
+
+// SubmarineChartStatus is the Helm release of a subchart
+type SubmarineChartStatus struct {
+	Name string `json:"name"`
+	// Version is the version of the chart of the release
+	Version string `json:"version,omitempty"`
+	// Source is where the chart is loaded from: "embedded", a directory or a
+	// chart repository
+	Source string `json:"source,omitempty"`
+	// Status is the status of the release, e.g. deployed, failed or
+	// pending-install
+	Status string `json:"status,omitempty"`
+	// Revision is the revision of the release
+	Revision int `json:"revision,omitempty"`
+	// Message describes the last operation on the release, or why it failed
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -22,6 +22,9 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -31,7 +34,7 @@ func (in *Submarine) DeepCopyInto(out *Submarine) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -53,6 +56,53 @@ func (in *Submarine) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineChartStatus) DeepCopyInto(out *SubmarineChartStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineChartStatus.
+func (in *SubmarineChartStatus) DeepCopy() *SubmarineChartStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineChartStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineCleanup) DeepCopyInto(out *SubmarineCleanup) {
+	*out = *in
+	if in.SucceededJobTTLSeconds != nil {
+		in, out := &in.SucceededJobTTLSeconds, &out.SucceededJobTTLSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobTTLSeconds != nil {
+		in, out := &in.FailedJobTTLSeconds, &out.FailedJobTTLSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NotebookIdleTimeoutSeconds != nil {
+		in, out := &in.NotebookIdleTimeoutSeconds, &out.NotebookIdleTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineCleanup.
+func (in *SubmarineCleanup) DeepCopy() *SubmarineCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubmarineDatabase) DeepCopyInto(out *SubmarineDatabase) {
 	*out = *in
@@ -61,6 +111,13 @@ func (in *SubmarineDatabase) DeepCopyInto(out *SubmarineDatabase) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -75,13 +132,41 @@ func (in *SubmarineDatabase) DeepCopy() *SubmarineDatabase {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineList) DeepCopyInto(out *SubmarineList) {
+func (in *SubmarineExperiment) DeepCopyInto(out *SubmarineExperiment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineExperiment.
+func (in *SubmarineExperiment) DeepCopy() *SubmarineExperiment {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineExperiment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineExperiment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineExperimentList) DeepCopyInto(out *SubmarineExperimentList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Submarine, len(*in))
+		*out = make([]SubmarineExperiment, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -89,18 +174,18 @@ func (in *SubmarineList) DeepCopyInto(out *SubmarineList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineList.
-func (in *SubmarineList) DeepCopy() *SubmarineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineExperimentList.
+func (in *SubmarineExperimentList) DeepCopy() *SubmarineExperimentList {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineList)
+	out := new(SubmarineExperimentList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SubmarineList) DeepCopyObject() runtime.Object {
+func (in *SubmarineExperimentList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -108,122 +193,203 @@ func (in *SubmarineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineMlflow) DeepCopyInto(out *SubmarineMlflow) {
+func (in *SubmarineExperimentPodStatus) DeepCopyInto(out *SubmarineExperimentPodStatus) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineMlflow.
-func (in *SubmarineMlflow) DeepCopy() *SubmarineMlflow {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineExperimentPodStatus.
+func (in *SubmarineExperimentPodStatus) DeepCopy() *SubmarineExperimentPodStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineMlflow)
+	out := new(SubmarineExperimentPodStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineServer) DeepCopyInto(out *SubmarineServer) {
+func (in *SubmarineExperimentReplicaSpec) DeepCopyInto(out *SubmarineExperimentReplicaSpec) {
 	*out = *in
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineServer.
-func (in *SubmarineServer) DeepCopy() *SubmarineServer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineExperimentReplicaSpec.
+func (in *SubmarineExperimentReplicaSpec) DeepCopy() *SubmarineExperimentReplicaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineServer)
+	out := new(SubmarineExperimentReplicaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineSpec) DeepCopyInto(out *SubmarineSpec) {
+func (in *SubmarineExperimentSpec) DeepCopyInto(out *SubmarineExperimentSpec) {
 	*out = *in
-	if in.Server != nil {
-		in, out := &in.Server, &out.Server
-		*out = new(SubmarineServer)
-		(*in).DeepCopyInto(*out)
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Database != nil {
-		in, out := &in.Database, &out.Database
-		*out = new(SubmarineDatabase)
-		(*in).DeepCopyInto(*out)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Tensorboard != nil {
-		in, out := &in.Tensorboard, &out.Tensorboard
-		*out = new(SubmarineTensorboard)
-		(*in).DeepCopyInto(*out)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make(map[string]SubmarineExperimentReplicaSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
 	}
-	if in.Mlflow != nil {
-		in, out := &in.Mlflow, &out.Mlflow
-		*out = new(SubmarineMlflow)
-		(*in).DeepCopyInto(*out)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineExperimentSpec.
+func (in *SubmarineExperimentSpec) DeepCopy() *SubmarineExperimentSpec {
+	if in == nil {
+		return nil
 	}
-	if in.Storage != nil {
-		in, out := &in.Storage, &out.Storage
-		*out = new(SubmarineStorage)
-		**out = **in
+	out := new(SubmarineExperimentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineExperimentStatus) DeepCopyInto(out *SubmarineExperimentStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]SubmarineExperimentPodStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSpec.
-func (in *SubmarineSpec) DeepCopy() *SubmarineSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineExperimentStatus.
+func (in *SubmarineExperimentStatus) DeepCopy() *SubmarineExperimentStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineSpec)
+	out := new(SubmarineExperimentStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineStatus) DeepCopyInto(out *SubmarineStatus) {
+func (in *SubmarineImages) DeepCopyInto(out *SubmarineImages) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineStatus.
-func (in *SubmarineStatus) DeepCopy() *SubmarineStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineImages.
+func (in *SubmarineImages) DeepCopy() *SubmarineImages {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineStatus)
+	out := new(SubmarineImages)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineStorage) DeepCopyInto(out *SubmarineStorage) {
+func (in *SubmarineList) DeepCopyInto(out *SubmarineList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Submarine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineStorage.
-func (in *SubmarineStorage) DeepCopy() *SubmarineStorage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineList.
+func (in *SubmarineList) DeepCopy() *SubmarineList {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineStorage)
+	out := new(SubmarineList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubmarineTensorboard) DeepCopyInto(out *SubmarineTensorboard) {
+func (in *SubmarineLogArchive) DeepCopyInto(out *SubmarineLogArchive) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(SubmarineS3Archive)
+		**out = **in
+	}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(SubmarineVolumeArchive)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineLogArchive.
+func (in *SubmarineLogArchive) DeepCopy() *SubmarineLogArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineLogArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineMlflow) DeepCopyInto(out *SubmarineMlflow) {
 	*out = *in
 	if in.Enabled != nil {
 		in, out := &in.Enabled, &out.Enabled
@@ -233,12 +399,1156 @@ func (in *SubmarineTensorboard) DeepCopyInto(out *SubmarineTensorboard) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTensorboard.
-func (in *SubmarineTensorboard) DeepCopy() *SubmarineTensorboard {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineMlflow.
+func (in *SubmarineMlflow) DeepCopy() *SubmarineMlflow {
 	if in == nil {
 		return nil
 	}
-	out := new(SubmarineTensorboard)
+	out := new(SubmarineMlflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineMonitoring) DeepCopyInto(out *SubmarineMonitoring) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineMonitoring.
+func (in *SubmarineMonitoring) DeepCopy() *SubmarineMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineNodePullStatus) DeepCopyInto(out *SubmarineNodePullStatus) {
+	*out = *in
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineNodePullStatus.
+func (in *SubmarineNodePullStatus) DeepCopy() *SubmarineNodePullStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineNodePullStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineNotebookProfile) DeepCopyInto(out *SubmarineNotebookProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineNotebookProfile.
+func (in *SubmarineNotebookProfile) DeepCopy() *SubmarineNotebookProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineNotebookProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineNotebookProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineNotebookProfileList) DeepCopyInto(out *SubmarineNotebookProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SubmarineNotebookProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineNotebookProfileList.
+func (in *SubmarineNotebookProfileList) DeepCopy() *SubmarineNotebookProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineNotebookProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineNotebookProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineNotebookProfileSpec) DeepCopyInto(out *SubmarineNotebookProfileSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IdleTimeoutSeconds != nil {
+		in, out := &in.IdleTimeoutSeconds, &out.IdleTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineNotebookProfileSpec.
+func (in *SubmarineNotebookProfileSpec) DeepCopy() *SubmarineNotebookProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineNotebookProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineNotebookProfileStatus) DeepCopyInto(out *SubmarineNotebookProfileStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineNotebookProfileStatus.
+func (in *SubmarineNotebookProfileStatus) DeepCopy() *SubmarineNotebookProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineNotebookProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarinePrePull) DeepCopyInto(out *SubmarinePrePull) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarinePrePull.
+func (in *SubmarinePrePull) DeepCopy() *SubmarinePrePull {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarinePrePull)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarinePrePullStatus) DeepCopyInto(out *SubmarinePrePullStatus) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnvironmentImages != nil {
+		in, out := &in.EnvironmentImages, &out.EnvironmentImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]SubmarineNodePullStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarinePrePullStatus.
+func (in *SubmarinePrePullStatus) DeepCopy() *SubmarinePrePullStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarinePrePullStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineQueue) DeepCopyInto(out *SubmarineQueue) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineQueue.
+func (in *SubmarineQueue) DeepCopy() *SubmarineQueue {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineQueue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineQueueStatus) DeepCopyInto(out *SubmarineQueueStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]SubmarineQueuedJob, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineQueueStatus.
+func (in *SubmarineQueueStatus) DeepCopy() *SubmarineQueueStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineQueueStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineQueuedJob) DeepCopyInto(out *SubmarineQueuedJob) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineQueuedJob.
+func (in *SubmarineQueuedJob) DeepCopy() *SubmarineQueuedJob {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineQueuedJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineReference) DeepCopyInto(out *SubmarineReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineReference.
+func (in *SubmarineReference) DeepCopy() *SubmarineReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineS3Archive) DeepCopyInto(out *SubmarineS3Archive) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineS3Archive.
+func (in *SubmarineS3Archive) DeepCopy() *SubmarineS3Archive {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineS3Archive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineServer) DeepCopyInto(out *SubmarineServer) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(SubmarineServerAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(SubmarineServerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineServer.
+func (in *SubmarineServer) DeepCopy() *SubmarineServer {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineServerAutoscaling) DeepCopyInto(out *SubmarineServerAutoscaling) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineServerAutoscaling.
+func (in *SubmarineServerAutoscaling) DeepCopy() *SubmarineServerAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineServerAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineServerConfig) DeepCopyInto(out *SubmarineServerConfig) {
+	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineServerConfig.
+func (in *SubmarineServerConfig) DeepCopy() *SubmarineServerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineServerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSharedTensorboard) DeepCopyInto(out *SubmarineSharedTensorboard) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSharedTensorboard.
+func (in *SubmarineSharedTensorboard) DeepCopy() *SubmarineSharedTensorboard {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSharedTensorboard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSpec) DeepCopyInto(out *SubmarineSpec) {
+	*out = *in
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(SubmarineServer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(SubmarineDatabase)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tensorboard != nil {
+		in, out := &in.Tensorboard, &out.Tensorboard
+		*out = new(SubmarineSharedTensorboard)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mlflow != nil {
+		in, out := &in.Mlflow, &out.Mlflow
+		*out = new(SubmarineMlflow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(SubmarineStorage)
+		**out = **in
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = new(SubmarineImages)
+		**out = **in
+	}
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]SubmarineOperator, len(*in))
+		copy(*out, *in)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(SubmarineMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = new(SubmarineCleanup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Queue != nil {
+		in, out := &in.Queue, &out.Queue
+		*out = new(SubmarineQueue)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrePull != nil {
+		in, out := &in.PrePull, &out.PrePull
+		*out = new(SubmarinePrePull)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogArchive != nil {
+		in, out := &in.LogArchive, &out.LogArchive
+		*out = new(SubmarineLogArchive)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSpec.
+func (in *SubmarineSpec) DeepCopy() *SubmarineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineStatus) DeepCopyInto(out *SubmarineStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(SubmarineUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Suspension != nil {
+		in, out := &in.Suspension, &out.Suspension
+		*out = new(SubmarineSuspensionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Charts != nil {
+		in, out := &in.Charts, &out.Charts
+		*out = make([]SubmarineChartStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Queue != nil {
+		in, out := &in.Queue, &out.Queue
+		*out = new(SubmarineQueueStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrePull != nil {
+		in, out := &in.PrePull, &out.PrePull
+		*out = new(SubmarinePrePullStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineStatus.
+func (in *SubmarineStatus) DeepCopy() *SubmarineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineStorage) DeepCopyInto(out *SubmarineStorage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineStorage.
+func (in *SubmarineStorage) DeepCopy() *SubmarineStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSuspensionStatus) DeepCopyInto(out *SubmarineSuspensionStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SuspendTime != nil {
+		in, out := &in.SuspendTime, &out.SuspendTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ResumeTime != nil {
+		in, out := &in.ResumeTime, &out.ResumeTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSuspensionStatus.
+func (in *SubmarineSuspensionStatus) DeepCopy() *SubmarineSuspensionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSuspensionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSweep) DeepCopyInto(out *SubmarineSweep) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSweep.
+func (in *SubmarineSweep) DeepCopy() *SubmarineSweep {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSweep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineSweep) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSweepList) DeepCopyInto(out *SubmarineSweepList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SubmarineSweep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSweepList.
+func (in *SubmarineSweepList) DeepCopy() *SubmarineSweepList {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSweepList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineSweepList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSweepSpec) DeepCopyInto(out *SubmarineSweepSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]SweepParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Trials != nil {
+		in, out := &in.Trials, &out.Trials
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	if in.MaxTrials != nil {
+		in, out := &in.MaxTrials, &out.MaxTrials
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int32)
+		**out = **in
+	}
+	out.Objective = in.Objective
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSweepSpec.
+func (in *SubmarineSweepSpec) DeepCopy() *SubmarineSweepSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSweepSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineSweepStatus) DeepCopyInto(out *SubmarineSweepStatus) {
+	*out = *in
+	if in.Trials != nil {
+		in, out := &in.Trials, &out.Trials
+		*out = make([]SweepTrialStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BestTrial != nil {
+		in, out := &in.BestTrial, &out.BestTrial
+		*out = new(SweepTrialStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineSweepStatus.
+func (in *SubmarineSweepStatus) DeepCopy() *SubmarineSweepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineSweepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTenant) DeepCopyInto(out *SubmarineTenant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTenant.
+func (in *SubmarineTenant) DeepCopy() *SubmarineTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineTenant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTenantList) DeepCopyInto(out *SubmarineTenantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SubmarineTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTenantList.
+func (in *SubmarineTenantList) DeepCopy() *SubmarineTenantList {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTenantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineTenantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTenantSpec) DeepCopyInto(out *SubmarineTenantSpec) {
+	*out = *in
+	out.Submarine = in.Submarine
+	if in.NamespaceLabels != nil {
+		in, out := &in.NamespaceLabels, &out.NamespaceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make([]v1.LimitRangeItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTenantSpec.
+func (in *SubmarineTenantSpec) DeepCopy() *SubmarineTenantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTenantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTenantStatus) DeepCopyInto(out *SubmarineTenantStatus) {
+	*out = *in
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTenantStatus.
+func (in *SubmarineTenantStatus) DeepCopy() *SubmarineTenantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTenantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTensorboard) DeepCopyInto(out *SubmarineTensorboard) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTensorboard.
+func (in *SubmarineTensorboard) DeepCopy() *SubmarineTensorboard {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTensorboard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineTensorboard) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTensorboardList) DeepCopyInto(out *SubmarineTensorboardList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SubmarineTensorboard, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTensorboardList.
+func (in *SubmarineTensorboardList) DeepCopy() *SubmarineTensorboardList {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTensorboardList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubmarineTensorboardList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTensorboardSpec) DeepCopyInto(out *SubmarineTensorboardSpec) {
+	*out = *in
+	if in.TTLSecondsAfterIdle != nil {
+		in, out := &in.TTLSecondsAfterIdle, &out.TTLSecondsAfterIdle
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTensorboardSpec.
+func (in *SubmarineTensorboardSpec) DeepCopy() *SubmarineTensorboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTensorboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineTensorboardStatus) DeepCopyInto(out *SubmarineTensorboardStatus) {
+	*out = *in
+	if in.LastActivityTime != nil {
+		in, out := &in.LastActivityTime, &out.LastActivityTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineTensorboardStatus.
+func (in *SubmarineTensorboardStatus) DeepCopy() *SubmarineTensorboardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineTensorboardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineUpgradeStatus) DeepCopyInto(out *SubmarineUpgradeStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineUpgradeStatus.
+func (in *SubmarineUpgradeStatus) DeepCopy() *SubmarineUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmarineVolumeArchive) DeepCopyInto(out *SubmarineVolumeArchive) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmarineVolumeArchive.
+func (in *SubmarineVolumeArchive) DeepCopy() *SubmarineVolumeArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(SubmarineVolumeArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepObjective) DeepCopyInto(out *SweepObjective) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepObjective.
+func (in *SweepObjective) DeepCopy() *SweepObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepParameter) DeepCopyInto(out *SweepParameter) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepParameter.
+func (in *SweepParameter) DeepCopy() *SweepParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepTrialStatus) DeepCopyInto(out *SweepTrialStatus) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepTrialStatus.
+func (in *SweepTrialStatus) DeepCopy() *SweepTrialStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepTrialStatus)
 	in.DeepCopyInto(out)
 	return out
 }
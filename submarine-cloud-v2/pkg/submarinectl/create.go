@@ -0,0 +1,229 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The defaults of "submarinectl create", the same as
+// artifacts/examples/example-submarine.yaml
+const (
+	defaultVersion = "0.6.0-SNAPSHOT"
+)
+
+type createOptions struct {
+	version                 string
+	serverImage             string
+	serverReplicas          int32
+	databaseImage           string
+	databaseReplicas        int32
+	databaseStorageSize     string
+	mysqlRootPasswordSecret string
+	tensorboard             bool
+	tensorboardStorageSize  string
+	mlflow                  bool
+	mlflowStorageSize       string
+	storageType             string
+	hostPath                string
+	nfsPath                 string
+	nfsIP                   string
+	operators               []string
+	interactive             bool
+}
+
+// interactiveFlags are the flags prompted for by "create --interactive", in
+// order
+var interactiveFlags = []string{
+	"version",
+	"server-replicas",
+	"database-replicas",
+	"database-storage-size",
+	"mysql-root-password-secret",
+	"tensorboard",
+	"tensorboard-storage-size",
+	"mlflow",
+	"mlflow-storage-size",
+	"storage-type",
+	"host-path",
+	"nfs-path",
+	"nfs-ip",
+}
+
+func newCreateCommand(o *Options) *cobra.Command {
+	co := &createOptions{}
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a Submarine",
+		Example: `  # Create a Submarine with the default settings
+  submarinectl create example-submarine -n submarine-user-test
+
+  # Store the data on NFS
+  submarinectl create example-submarine --storage-type nfs --nfs-ip 10.96.0.2 --nfs-path /
+
+  # Prompt for every setting that is not given as a flag
+  submarinectl create example-submarine -i`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if co.interactive {
+				if err := promptFlags(cmd.Flags(), o.In, o.Out); err != nil {
+					return err
+				}
+			}
+			return co.run(o, args[0])
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&co.version, "version", defaultVersion, "Version of Submarine")
+	flags.StringVar(&co.serverImage, "server-image", "", "Image of submarine-server, defaults to the image of the version")
+	flags.Int32Var(&co.serverReplicas, "server-replicas", 1, "Replicas of submarine-server")
+	flags.StringVar(&co.databaseImage, "database-image", "", "Image of submarine-database, defaults to the image of the version")
+	flags.Int32Var(&co.databaseReplicas, "database-replicas", 1, "Replicas of submarine-database")
+	flags.StringVar(&co.databaseStorageSize, "database-storage-size", "1Gi", "Storage size of submarine-database")
+	flags.StringVar(&co.mysqlRootPasswordSecret, "mysql-root-password-secret", "root-pass-secret", "Secret holding the MySQL root password")
+	flags.BoolVar(&co.tensorboard, "tensorboard", true, "Enable tensorboard")
+	flags.StringVar(&co.tensorboardStorageSize, "tensorboard-storage-size", "10Gi", "Storage size of tensorboard")
+	flags.BoolVar(&co.mlflow, "mlflow", true, "Enable mlflow")
+	flags.StringVar(&co.mlflowStorageSize, "mlflow-storage-size", "10Gi", "Storage size of mlflow")
+	flags.StringVar(&co.storageType, "storage-type", "host", "Type of the persistent volumes: host or nfs")
+	flags.StringVar(&co.hostPath, "host-path", "/tmp/submarine/host", "Host path of the persistent volumes if the storage type is host")
+	flags.StringVar(&co.nfsPath, "nfs-path", "", "NFS path of the persistent volumes if the storage type is nfs")
+	flags.StringVar(&co.nfsIP, "nfs-ip", "", "NFS server of the persistent volumes if the storage type is nfs")
+	flags.StringSliceVar(&co.operators, "operators", nil, "Workload operators to install: notebook-controller, tfjob, pytorchjob, mpijob, xgboostjob. Defaults to notebook-controller, tfjob and pytorchjob")
+	flags.BoolVarP(&co.interactive, "interactive", "i", false, "Prompt for the settings that are not given as flags")
+	return cmd
+}
+
+// promptFlags asks for the value of every interactive flag that was not set
+// on the command line. An empty answer keeps the default.
+func promptFlags(flags *pflag.FlagSet, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	for _, name := range interactiveFlags {
+		flag := flags.Lookup(name)
+		if flag.Changed {
+			continue
+		}
+		for {
+			fmt.Fprintf(out, "%s [%s]: ", flag.Usage, flag.Value.String())
+			answer, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				break
+			}
+			if setErr := flags.Set(name, answer); setErr != nil {
+				fmt.Fprintf(out, "Invalid value: %s\n", setErr.Error())
+				if err == io.EOF {
+					return setErr
+				}
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func (co *createOptions) run(o *Options, name string) error {
+	submarine, err := co.newSubmarine(name, o.Namespace)
+	if err != nil {
+		return err
+	}
+
+	created, err := o.SubmarineClient.SubmarineV1alpha1().Submarines(o.Namespace).Create(context.TODO(), submarine, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	if o.Output != outputTable {
+		return printObject(o.Out, o.Output, withTypeMeta(created))
+	}
+	fmt.Fprintf(o.Out, "submarine/%s created in namespace %s\n", created.Name, created.Namespace)
+	return nil
+}
+
+func (co *createOptions) newSubmarine(name string, namespace string) (*v1alpha1.Submarine, error) {
+	switch co.storageType {
+	case "host":
+		if co.hostPath == "" {
+			return nil, fmt.Errorf("--host-path is required for storage type host")
+		}
+	case "nfs":
+		if co.nfsPath == "" || co.nfsIP == "" {
+			return nil, fmt.Errorf("--nfs-path and --nfs-ip are required for storage type nfs")
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage type %q, must be host or nfs", co.storageType)
+	}
+
+	var operators []v1alpha1.SubmarineOperator
+	for _, operator := range co.operators {
+		operators = append(operators, v1alpha1.SubmarineOperator(operator))
+	}
+
+	return &v1alpha1.Submarine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "Submarine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.SubmarineSpec{
+			Version: co.version,
+			Server: &v1alpha1.SubmarineServer{
+				Image:    co.serverImage,
+				Replicas: &co.serverReplicas,
+			},
+			Database: &v1alpha1.SubmarineDatabase{
+				Image:                   co.databaseImage,
+				Replicas:                &co.databaseReplicas,
+				StorageSize:             co.databaseStorageSize,
+				MysqlRootPasswordSecret: co.mysqlRootPasswordSecret,
+			},
+			Tensorboard: &v1alpha1.SubmarineSharedTensorboard{
+				Enabled:     &co.tensorboard,
+				StorageSize: co.tensorboardStorageSize,
+			},
+			Mlflow: &v1alpha1.SubmarineMlflow{
+				Enabled:     &co.mlflow,
+				StorageSize: co.mlflowStorageSize,
+			},
+			Storage: &v1alpha1.SubmarineStorage{
+				StorageType: co.storageType,
+				HostPath:    co.hostPath,
+				NfsPath:     co.nfsPath,
+				NfsIP:       co.nfsIP,
+			},
+			Operators: operators,
+		},
+	}, nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// How often "delete --wait" checks whether the Submarine is gone
+var deletePollInterval = 2 * time.Second
+
+func newDeleteCommand(o *Options) *cobra.Command {
+	waitForDeletion := false
+	timeout := 5 * time.Minute
+	cmd := &cobra.Command{
+		Use:   "delete NAME...",
+		Short: "Delete Submarines",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDelete(o, args, waitForDeletion, timeout)
+		},
+	}
+	cmd.Flags().BoolVar(&waitForDeletion, "wait", false, "Wait until the Submarines and the objects they own are deleted")
+	cmd.Flags().DurationVar(&timeout, "timeout", timeout, "How long to wait with --wait")
+	return cmd
+}
+
+func runDelete(o *Options, names []string, waitForDeletion bool, timeout time.Duration) error {
+	submarines := o.SubmarineClient.SubmarineV1alpha1().Submarines(o.Namespace)
+
+	// With --wait, the Submarine only disappears after the garbage collector
+	// has deleted everything it owns
+	options := metav1.DeleteOptions{}
+	if waitForDeletion {
+		propagation := metav1.DeletePropagationForeground
+		options.PropagationPolicy = &propagation
+	}
+	for _, name := range names {
+		if err := submarines.Delete(context.TODO(), name, options); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "submarine/%s deleted\n", name)
+	}
+	if !waitForDeletion {
+		return nil
+	}
+
+	for _, name := range names {
+		err := wait.PollImmediate(deletePollInterval, timeout, func() (bool, error) {
+			_, err := submarines.Get(context.TODO(), name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err == wait.ErrWaitTimeout {
+			return fmt.Errorf("timed out waiting for submarine/%s to be deleted", name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "submarine/%s is gone\n", name)
+	}
+	return nil
+}
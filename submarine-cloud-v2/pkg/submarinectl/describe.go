@@ -0,0 +1,175 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDescribeCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe NAME",
+		Short: "Show the status, conditions and component health of a Submarine",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDescribe(o, args[0])
+		},
+	}
+}
+
+func runDescribe(o *Options, name string) error {
+	submarine, err := o.SubmarineClient.SubmarineV1alpha1().Submarines(o.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if o.Output != outputTable {
+		return printObject(o.Out, o.Output, withTypeMeta(submarine))
+	}
+
+	components, err := listComponents(o, submarine)
+	if err != nil {
+		return err
+	}
+
+	w := newTabWriter(o.Out)
+	fmt.Fprintf(w, "Name:\t%s\n", submarine.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", submarine.Namespace)
+	fmt.Fprintf(w, "Version:\t%s\n", submarine.Spec.Version)
+	if submarine.Status.Version != "" && submarine.Status.Version != submarine.Spec.Version {
+		fmt.Fprintf(w, "Running Version:\t%s\n", submarine.Status.Version)
+	}
+	if upgrade := submarine.Status.Upgrade; upgrade != nil {
+		fmt.Fprintf(w, "Upgrade:\t%s -> %s (%s)\n", upgrade.FromVersion, upgrade.ToVersion, upgrade.Phase)
+		if upgrade.Backup != "" {
+			fmt.Fprintf(w, "Backup:\t%s\n", upgrade.Backup)
+		}
+	}
+	if suspension := submarine.Status.Suspension; suspension != nil && suspension.Suspended && suspension.SuspendTime != nil {
+		fmt.Fprintf(w, "Suspended:\tsince %s (%s ago)\n", suspension.SuspendTime.UTC().Format("2006-01-02T15:04:05Z"), age(*suspension.SuspendTime))
+	}
+	fmt.Fprintf(w, "Created:\t%s (%s ago)\n", submarine.CreationTimestamp.UTC().Format("2006-01-02T15:04:05Z"), age(submarine.CreationTimestamp))
+	fmt.Fprintf(w, "Ready:\t%s\n", readyStatus(submarine))
+	if submarine.Spec.Server != nil {
+		fmt.Fprintf(w, "Server:\t%d/%d available\n", submarine.Status.AvailableServerReplicas, desiredReplicas(submarine.Spec.Server.Replicas))
+	}
+	if submarine.Spec.Database != nil {
+		fmt.Fprintf(w, "Database:\t%d/%d available\n", submarine.Status.AvailableDatabaseReplicas, desiredReplicas(submarine.Spec.Database.Replicas))
+	}
+	if submarine.Spec.Tensorboard != nil {
+		fmt.Fprintf(w, "Tensorboard:\t%s\n", enabledString(submarine.Spec.Tensorboard.Enabled))
+	}
+	if submarine.Spec.Mlflow != nil {
+		fmt.Fprintf(w, "Mlflow:\t%s\n", enabledString(submarine.Spec.Mlflow.Enabled))
+	}
+	if submarine.Spec.Storage != nil {
+		fmt.Fprintf(w, "Storage:\t%s\n", submarine.Spec.Storage.StorageType)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "\nConditions:\n")
+	if len(submarine.Status.Conditions) == 0 {
+		fmt.Fprintf(o.Out, "  <none>\n")
+	} else {
+		w = newTabWriter(o.Out)
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tAGE\tMESSAGE")
+		for _, condition := range submarine.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, age(condition.LastTransitionTime), condition.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(submarine.Status.Charts) > 0 {
+		fmt.Fprintf(o.Out, "\nCharts:\n")
+		w = newTabWriter(o.Out)
+		fmt.Fprintln(w, "  NAME\tVERSION\tSTATUS\tREVISION\tSOURCE\tMESSAGE")
+		for _, chart := range submarine.Status.Charts {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%s\t%s\n", chart.Name, chart.Version, chart.Status, chart.Revision, chart.Source, chart.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(o.Out, "\nComponents:\n")
+	if len(components) == 0 {
+		fmt.Fprintf(o.Out, "  <none>\n")
+		return nil
+	}
+	w = newTabWriter(o.Out)
+	fmt.Fprintln(w, "  NAME\tREADY\tUP-TO-DATE\tAVAILABLE\tHEALTHY")
+	for _, deployment := range components {
+		fmt.Fprintf(w, "  %s\t%d/%d\t%d\t%d\t%s\n",
+			deployment.Name,
+			deployment.Status.ReadyReplicas, desiredReplicas(deployment.Spec.Replicas),
+			deployment.Status.UpdatedReplicas,
+			deployment.Status.AvailableReplicas,
+			deploymentHealth(&deployment),
+		)
+	}
+	return w.Flush()
+}
+
+// listComponents returns the Deployments of a Submarine: the ones it owns and
+// the ones installed by its Helm charts, which have no owner.
+func listComponents(o *Options, submarine *v1alpha1.Submarine) ([]appsv1.Deployment, error) {
+	list, err := o.KubeClient.AppsV1().Deployments(submarine.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var components []appsv1.Deployment
+	for _, deployment := range list.Items {
+		owner := metav1.GetControllerOf(&deployment)
+		if owner == nil || owner.UID == submarine.UID {
+			components = append(components, deployment)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+	return components, nil
+}
+
+// deploymentHealth returns the status of the Available condition of the
+// Deployment
+func deploymentHealth(deployment *appsv1.Deployment) string {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			return string(condition.Status)
+		}
+	}
+	return string(metav1.ConditionUnknown)
+}
+
+func enabledString(enabled *bool) string {
+	if enabled != nil && *enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
@@ -0,0 +1,95 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newListCommand(o *Options) *cobra.Command {
+	allNamespaces := false
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List Submarines",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace := o.Namespace
+			if allNamespaces {
+				namespace = metav1.NamespaceAll
+			}
+			return runList(o, namespace)
+		},
+	}
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List the Submarines in all namespaces")
+	return cmd
+}
+
+func runList(o *Options, namespace string) error {
+	list, err := o.SubmarineClient.SubmarineV1alpha1().Submarines(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	if o.Output != outputTable {
+		result := &v1alpha1.SubmarineList{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "SubmarineList",
+			},
+			ListMeta: list.ListMeta,
+		}
+		for i := range list.Items {
+			result.Items = append(result.Items, *withTypeMeta(&list.Items[i]))
+		}
+		return printObject(o.Out, o.Output, result)
+	}
+
+	if len(list.Items) == 0 {
+		fmt.Fprintf(o.ErrOut, "No Submarines found\n")
+		return nil
+	}
+
+	w := newTabWriter(o.Out)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tVERSION\tSERVER\tDATABASE\tREADY\tAGE")
+	for _, submarine := range list.Items {
+		var serverReplicas, databaseReplicas int32
+		if submarine.Spec.Server != nil {
+			serverReplicas = desiredReplicas(submarine.Spec.Server.Replicas)
+		}
+		if submarine.Spec.Database != nil {
+			databaseReplicas = desiredReplicas(submarine.Spec.Database.Replicas)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d/%d\t%s\t%s\n",
+			submarine.Namespace,
+			submarine.Name,
+			submarine.Spec.Version,
+			submarine.Status.AvailableServerReplicas, serverReplicas,
+			submarine.Status.AvailableDatabaseReplicas, databaseReplicas,
+			readyStatus(&submarine),
+			age(submarine.CreationTimestamp),
+		)
+	}
+	return w.Flush()
+}
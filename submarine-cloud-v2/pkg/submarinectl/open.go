@@ -0,0 +1,88 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"submarine-cloud-v2/pkg/k8sutil"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The workbench is served by traefik, which is installed by the traefik
+// subchart
+const (
+	workbenchService = "traefik"
+	workbenchPort    = 80
+)
+
+type openOptions struct {
+	address   string
+	localPort int
+}
+
+func newOpenCommand(o *Options) *cobra.Command {
+	oo := &openOptions{}
+	cmd := &cobra.Command{
+		Use:   "open NAME",
+		Short: "Forward a local port to the workbench of a Submarine and print its URL",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+			return oo.run(ctx, o, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&oo.address, "address", "localhost", "Local address to listen on, localhost only by default; use 0.0.0.0 to listen on every interface")
+	cmd.Flags().IntVar(&oo.localPort, "port", 0, "Local port to listen on, a free port is picked if 0")
+	return cmd
+}
+
+func (oo *openOptions) run(ctx context.Context, o *Options, name string) error {
+	if o.RestConfig == nil {
+		return fmt.Errorf("open needs a connection to a cluster")
+	}
+	submarine, err := o.SubmarineClient.SubmarineV1alpha1().Submarines(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	forwarder := k8sutil.NewServicePortForwarder(o.RestConfig, o.KubeClient, submarine.Namespace, workbenchService, oo.address, oo.localPort, workbenchPort)
+	done := make(chan error, 1)
+	go func() {
+		done <- forwarder.Run(ctx)
+	}()
+
+	select {
+	case <-forwarder.Ready:
+		fmt.Fprintf(o.Out, "Workbench of submarine/%s is available at http://%s:%d\n", name, oo.address, forwarder.LocalPort())
+		fmt.Fprintf(o.Out, "Press Ctrl+C to stop\n")
+	case err := <-forwarder.Errors:
+		// Stop instead of retrying if the first connection fails
+		return err
+	case err := <-done:
+		return err
+	}
+	return <-done
+}
@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// printObject writes obj as JSON or YAML
+func printObject(out io.Writer, format string, obj interface{}) error {
+	var data []byte
+	var err error
+	switch format {
+	case outputJSON:
+		data, err = json.MarshalIndent(obj, "", "    ")
+		data = append(data, '\n')
+	case outputYAML:
+		data, err = yaml.Marshal(obj)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// withTypeMeta sets the apiVersion and kind, which are not filled in for the
+// items of a list
+func withTypeMeta(submarine *v1alpha1.Submarine) *v1alpha1.Submarine {
+	submarine = submarine.DeepCopy()
+	submarine.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	submarine.Kind = "Submarine"
+	return submarine
+}
+
+func newTabWriter(out io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+}
+
+// readyStatus returns the status of the Ready condition, or "Unknown" if the
+// operator has not reported it yet
+func readyStatus(submarine *v1alpha1.Submarine) string {
+	condition := meta.FindStatusCondition(submarine.Status.Conditions, v1alpha1.SubmarineReady)
+	if condition == nil {
+		return string(metav1.ConditionUnknown)
+	}
+	return string(condition.Status)
+}
+
+// desiredReplicas returns the replicas in the spec of a component, or 0
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 0
+	}
+	return *replicas
+}
+
+// age returns the age of an object in the format used by kubectl
+func age(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
@@ -0,0 +1,132 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package submarinectl implements the submarinectl command line tool, which
+// manages Submarine resources through the generated clientset.
+package submarinectl
+
+import (
+	"fmt"
+	"io"
+
+	"submarine-cloud-v2/pkg/generated/clientset/versioned"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Options holds the global flags and the clients shared by all subcommands.
+// The clients are created from the kubeconfig unless they are already set,
+// which lets tests run the commands against fake clientsets.
+type Options struct {
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+
+	Kubeconfig string
+	Namespace  string
+	Output     string
+
+	RestConfig      *rest.Config
+	KubeClient      kubernetes.Interface
+	SubmarineClient versioned.Interface
+}
+
+// NewCommand returns the root submarinectl command
+func NewCommand(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "submarinectl",
+		Short:        "Manage Submarine instances on Kubernetes",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.complete()
+		},
+	}
+	cmd.SetIn(o.In)
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	flags.StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "Namespace of the Submarine, defaults to the namespace of the current context")
+	flags.StringVarP(&o.Output, "output", "o", "table", "Output format: table, json or yaml")
+
+	cmd.AddCommand(
+		newCreateCommand(o),
+		newListCommand(o),
+		newDescribeCommand(o),
+		newUpgradeCommand(o),
+		newSuspendCommand(o),
+		newResumeCommand(o),
+		newDeleteCommand(o),
+		newOpenCommand(o),
+		newSupportBundleCommand(o),
+	)
+	return cmd
+}
+
+// complete validates the global flags and creates the clients that are not
+// set yet
+func (o *Options) complete() error {
+	switch o.Output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unknown output format %q, must be one of table, json or yaml", o.Output)
+	}
+
+	if o.KubeClient != nil && o.SubmarineClient != nil {
+		if o.Namespace == "" {
+			o.Namespace = "default"
+		}
+		return nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.Kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
+
+	if o.Namespace == "" {
+		namespace, _, err := clientConfig.Namespace()
+		if err != nil {
+			return err
+		}
+		o.Namespace = namespace
+	}
+
+	var err error
+	if o.RestConfig == nil {
+		o.RestConfig, err = clientConfig.ClientConfig()
+		if err != nil {
+			return err
+		}
+	}
+	if o.KubeClient == nil {
+		o.KubeClient, err = kubernetes.NewForConfig(o.RestConfig)
+		if err != nil {
+			return err
+		}
+	}
+	if o.SubmarineClient == nil {
+		o.SubmarineClient, err = versioned.NewForConfig(o.RestConfig)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
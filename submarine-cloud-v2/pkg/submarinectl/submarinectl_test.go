@@ -0,0 +1,281 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const testNamespace = "submarine-user-test"
+
+// runCommand runs submarinectl with args against fake clientsets holding
+// objects, and returns what was written to stdout
+func runCommand(t *testing.T, submarineClient *fake.Clientset, kubeClient *kubefake.Clientset, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	cmd := NewCommand(&Options{
+		In:              strings.NewReader(stdin),
+		Out:             &out,
+		ErrOut:          &errOut,
+		Namespace:       testNamespace,
+		KubeClient:      kubeClient,
+		SubmarineClient: submarineClient,
+	})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func newTestSubmarine(name string, version string) *v1alpha1.Submarine {
+	replicas := int32(1)
+	return &v1alpha1.Submarine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID("uid-" + name)},
+		Spec: v1alpha1.SubmarineSpec{
+			Version:  version,
+			Server:   &v1alpha1.SubmarineServer{Replicas: &replicas},
+			Database: &v1alpha1.SubmarineDatabase{Replicas: &replicas},
+		},
+		Status: v1alpha1.SubmarineStatus{
+			AvailableServerReplicas: 1,
+			Conditions: []metav1.Condition{
+				{Type: v1alpha1.SubmarineReady, Status: metav1.ConditionFalse, Reason: "ComponentsUnavailable"},
+			},
+		},
+	}
+}
+
+func TestCreate(t *testing.T) {
+	submarineClient := fake.NewSimpleClientset()
+	_, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "",
+		"create", "example-submarine", "--version", "0.6.0", "--server-replicas", "2", "--tensorboard=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submarine, err := submarineClient.SubmarineV1alpha1().Submarines(testNamespace).Get(context.TODO(), "example-submarine", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submarine.Spec.Version != "0.6.0" {
+		t.Errorf("expected version 0.6.0, got %s", submarine.Spec.Version)
+	}
+	if *submarine.Spec.Server.Replicas != 2 {
+		t.Errorf("expected 2 server replicas, got %d", *submarine.Spec.Server.Replicas)
+	}
+	if *submarine.Spec.Tensorboard.Enabled {
+		t.Errorf("expected tensorboard to be disabled")
+	}
+	if submarine.Spec.Storage.StorageType != "host" || submarine.Spec.Storage.HostPath != "/tmp/submarine/host" {
+		t.Errorf("expected the default host storage, got %+v", submarine.Spec.Storage)
+	}
+}
+
+func TestCreateInteractive(t *testing.T) {
+	submarineClient := fake.NewSimpleClientset()
+	// Keep the default version, set 3 server replicas and keep the rest
+	_, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "\n3\n",
+		"create", "example-submarine", "-i", "--storage-type", "nfs", "--nfs-ip", "10.96.0.2", "--nfs-path", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submarine, err := submarineClient.SubmarineV1alpha1().Submarines(testNamespace).Get(context.TODO(), "example-submarine", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submarine.Spec.Version != defaultVersion {
+		t.Errorf("expected version %s, got %s", defaultVersion, submarine.Spec.Version)
+	}
+	if *submarine.Spec.Server.Replicas != 3 {
+		t.Errorf("expected 3 server replicas, got %d", *submarine.Spec.Server.Replicas)
+	}
+	if submarine.Spec.Storage.StorageType != "nfs" || submarine.Spec.Storage.NfsIP != "10.96.0.2" {
+		t.Errorf("expected the nfs storage from the flags, got %+v", submarine.Spec.Storage)
+	}
+}
+
+func TestCreateInvalidStorage(t *testing.T) {
+	_, err := runCommand(t, fake.NewSimpleClientset(), kubefake.NewSimpleClientset(), "",
+		"create", "example-submarine", "--storage-type", "nfs")
+	if err == nil {
+		t.Fatal("expected an error for nfs storage without --nfs-ip")
+	}
+}
+
+func TestList(t *testing.T) {
+	submarineClient := fake.NewSimpleClientset(newTestSubmarine("a", "0.5.0"), newTestSubmarine("b", "0.6.0"))
+
+	out, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 rows, got:\n%s", out)
+	}
+	if !strings.HasPrefix(lines[0], "NAMESPACE") || !strings.Contains(lines[1], "0.5.0") || !strings.Contains(lines[2], "1/1") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+
+	out, err = runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "list", "-o", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &v1alpha1.SubmarineList{}
+	if err := yaml.UnmarshalStrict([]byte(out), list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Kind != "SubmarineList" || len(list.Items) != 2 || list.Items[0].Kind != "Submarine" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	submarine := newTestSubmarine("example-submarine", "0.6.0")
+	replicas := int32(1)
+	owned := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "submarine-server",
+			Namespace:       testNamespace,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine"))},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	chart := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "traefik", Namespace: testNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	other := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "other-server",
+			Namespace:       testNamespace,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "submarine.k8s.io/v1alpha1", Kind: "Submarine", Name: "other", UID: "other", Controller: &[]bool{true}[0]}},
+		},
+	}
+
+	out, err := runCommand(t, fake.NewSimpleClientset(submarine), kubefake.NewSimpleClientset(owned, chart, other), "", "describe", "example-submarine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Version:", "0.6.0", "Ready", "ComponentsUnavailable", "submarine-server", "traefik"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "other-server") {
+		t.Errorf("expected the deployments of other Submarines to be skipped:\n%s", out)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	submarineClient := fake.NewSimpleClientset(newTestSubmarine("example-submarine", "0.5.0"))
+
+	out, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "upgrade", "example-submarine", "--version", "0.6.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "from 0.5.0 to 0.6.0") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	submarine, err := submarineClient.SubmarineV1alpha1().Submarines(testNamespace).Get(context.TODO(), "example-submarine", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submarine.Spec.Version != "0.6.0" {
+		t.Errorf("expected version 0.6.0, got %s", submarine.Spec.Version)
+	}
+
+	if _, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "upgrade", "example-submarine"); err == nil {
+		t.Errorf("expected an error without --version")
+	}
+}
+
+func TestSuspendResume(t *testing.T) {
+	submarineClient := fake.NewSimpleClientset(newTestSubmarine("example-submarine", "0.6.0"))
+
+	if _, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "suspend", "example-submarine", "--database"); err != nil {
+		t.Fatal(err)
+	}
+	submarine, err := submarineClient.SubmarineV1alpha1().Submarines(testNamespace).Get(context.TODO(), "example-submarine", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !submarine.Spec.Suspend || !submarine.Spec.SuspendDatabase {
+		t.Errorf("expected the Submarine and its database to be suspended, got %+v", submarine.Spec)
+	}
+
+	out, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "resume", "example-submarine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "resumed") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	submarine, err = submarineClient.SubmarineV1alpha1().Submarines(testNamespace).Get(context.TODO(), "example-submarine", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submarine.Spec.Suspend || submarine.Spec.SuspendDatabase {
+		t.Errorf("expected the Submarine to be resumed, got %+v", submarine.Spec)
+	}
+}
+
+func TestDeleteWait(t *testing.T) {
+	deletePollInterval = 0
+	submarineClient := fake.NewSimpleClientset(newTestSubmarine("example-submarine", "0.6.0"))
+
+	out, err := runCommand(t, submarineClient, kubefake.NewSimpleClientset(), "", "delete", "example-submarine", "--wait")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "submarine/example-submarine is gone") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	_, err = submarineClient.SubmarineV1alpha1().Submarines(testNamespace).Get(context.TODO(), "example-submarine", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the Submarine to be deleted, got %v", err)
+	}
+}
+
+func TestInvalidOutput(t *testing.T) {
+	_, err := runCommand(t, fake.NewSimpleClientset(), kubefake.NewSimpleClientset([]runtime.Object{}...), "", "list", "-o", "wide")
+	if err == nil {
+		t.Errorf("expected an error for an unknown output format")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+
+	"submarine-cloud-v2/pkg/supportbundle"
+
+	"github.com/spf13/cobra"
+)
+
+func newSupportBundleCommand(o *Options) *cobra.Command {
+	collector := &supportbundle.Collector{}
+	dir := "."
+	cmd := &cobra.Command{
+		Use:   "support-bundle NAME",
+		Short: "Collect the state of a Submarine into a tarball for troubleshooting",
+		Long: `Collect the Submarine, the events, workloads and pod logs of its namespace,
+its Helm releases and its storage into a timestamped tarball. Secret values
+are redacted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			collector.KubeClient = o.KubeClient
+			collector.SubmarineClient = o.SubmarineClient
+			collector.Namespace = o.Namespace
+			collector.Name = args[0]
+			path, err := collector.WriteBundle(context.TODO(), dir)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(o.Out, "Support bundle written to %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", dir, "Directory to write the tarball to")
+	cmd.Flags().Int64Var(&collector.LogLimitBytes, "log-limit-bytes", supportbundle.DefaultLogLimitBytes, "Maximum bytes of log collected for each container")
+	cmd.Flags().Int64Var(&collector.LogTailLines, "log-tail-lines", supportbundle.DefaultLogTailLines, "Maximum lines of log collected for each container")
+	return cmd
+}
@@ -0,0 +1,79 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+func newSuspendCommand(o *Options) *cobra.Command {
+	database := false
+	cmd := &cobra.Command{
+		Use:   "suspend NAME",
+		Short: "Scale the components of a Submarine to zero",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSuspend(o, args[0], true, database)
+		},
+	}
+	cmd.Flags().BoolVar(&database, "database", false, "Scale the database to zero as well")
+	return cmd
+}
+
+func newResumeCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume NAME",
+		Short: "Scale the components of a suspended Submarine back up",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSuspend(o, args[0], false, false)
+		},
+	}
+}
+
+func runSuspend(o *Options, name string, suspend bool, database bool) error {
+	submarines := o.SubmarineClient.SubmarineV1alpha1().Submarines(o.Namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		submarine, err := submarines.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if submarine.Spec.Suspend == suspend && submarine.Spec.SuspendDatabase == database {
+			return nil
+		}
+		submarine.Spec.Suspend = suspend
+		submarine.Spec.SuspendDatabase = database
+		_, err = submarines.Update(context.TODO(), submarine, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if suspend {
+		fmt.Fprintf(o.Out, "submarine/%s suspended\n", name)
+	} else {
+		fmt.Fprintf(o.Out, "submarine/%s resumed\n", name)
+	}
+	return nil
+}
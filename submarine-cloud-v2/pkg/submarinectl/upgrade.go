@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package submarinectl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+func newUpgradeCommand(o *Options) *cobra.Command {
+	version := ""
+	cmd := &cobra.Command{
+		Use:   "upgrade NAME --version VERSION",
+		Short: "Change the version of a Submarine",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgrade(o, args[0], version)
+		},
+	}
+	cmd.Flags().StringVar(&version, "version", "", "Version to upgrade to")
+	cmd.MarkFlagRequired("version")
+	return cmd
+}
+
+func runUpgrade(o *Options, name string, version string) error {
+	if version == "" {
+		return fmt.Errorf("--version must not be empty")
+	}
+
+	submarines := o.SubmarineClient.SubmarineV1alpha1().Submarines(o.Namespace)
+	previous := ""
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		submarine, err := submarines.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		previous = submarine.Spec.Version
+		if previous == version {
+			return nil
+		}
+		submarine.Spec.Version = version
+		_, err = submarines.Update(context.TODO(), submarine, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if previous == version {
+		fmt.Fprintf(o.Out, "submarine/%s is already at version %s\n", name, version)
+	} else {
+		fmt.Fprintf(o.Out, "submarine/%s upgraded from %s to %s\n", name, previous, version)
+	}
+	return nil
+}
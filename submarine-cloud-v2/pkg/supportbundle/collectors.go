@@ -0,0 +1,314 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package supportbundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// addYAML adds obj to the bundle as YAML
+func (b *bundle) addYAML(name string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return b.add(name, data)
+}
+
+func (c *Collector) collectSubmarine(ctx context.Context, b *bundle) error {
+	submarine, err := c.SubmarineClient.SubmarineV1alpha1().Submarines(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	submarine.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	submarine.Kind = "Submarine"
+	return b.addYAML("submarine.yaml", submarine)
+}
+
+func (c *Collector) collectEvents(ctx context.Context, b *bundle) error {
+	events, err := c.KubeClient.CoreV1().Events(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return eventTime(&events.Items[i]).Before(eventTime(&events.Items[j]))
+	})
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	for _, event := range events.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%d\t%s\n",
+			eventTime(&event).UTC().Format("2006-01-02T15:04:05Z"),
+			event.Type,
+			event.Reason,
+			strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name,
+			event.Count,
+			redactText(strings.TrimSpace(event.Message)),
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return b.add("events.txt", buf.Bytes())
+}
+
+// eventTime returns when an event was last seen
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// collectWorkloads adds the Deployments, Pods, Services, ConfigMaps and
+// Secrets of the namespace. Secret values are never included.
+func (c *Collector) collectWorkloads(ctx context.Context, b *bundle) error {
+	deployments, err := c.KubeClient.AppsV1().Deployments(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		if redactPodSpec(&deployments.Items[i].Spec.Template.Spec) {
+			redactMetadata(&deployments.Items[i].ObjectMeta)
+		}
+	}
+	if err := b.addYAML("deployments.yaml", deployments); err != nil {
+		return err
+	}
+
+	pods, err := c.KubeClient.CoreV1().Pods(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		if redactPodSpec(&pods.Items[i].Spec) {
+			redactMetadata(&pods.Items[i].ObjectMeta)
+		}
+	}
+	if err := b.addYAML("pods.yaml", pods); err != nil {
+		return err
+	}
+
+	services, err := c.KubeClient.CoreV1().Services(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if err := b.addYAML("services.yaml", services); err != nil {
+		return err
+	}
+
+	configmaps, err := c.KubeClient.CoreV1().ConfigMaps(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range configmaps.Items {
+		redactConfigMap(&configmaps.Items[i])
+	}
+	if err := b.addYAML("configmaps.yaml", configmaps); err != nil {
+		return err
+	}
+
+	secrets, err := c.KubeClient.CoreV1().Secrets(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		redactSecret(&secrets.Items[i])
+	}
+	return b.addYAML("secrets.yaml", secrets)
+}
+
+// collectLogs adds the logs of every container of the pods of each
+// component, bounded by LogLimitBytes and LogTailLines. The logs of the
+// previous instance are added for containers that restarted.
+func (c *Collector) collectLogs(ctx context.Context, b *bundle) error {
+	components := c.Components
+	if components == nil {
+		components = DefaultComponents(c.Name)
+	}
+	limitBytes := c.LogLimitBytes
+	if limitBytes <= 0 {
+		limitBytes = DefaultLogLimitBytes
+	}
+	tailLines := c.LogTailLines
+	if tailLines <= 0 {
+		tailLines = DefaultLogTailLines
+	}
+
+	var errs []string
+	for _, component := range components {
+		pods, err := c.KubeClient.CoreV1().Pods(c.Namespace).List(ctx, metav1.ListOptions{LabelSelector: component.Selector})
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, pod := range pods.Items {
+			restarts := map[string]int32{}
+			for _, status := range pod.Status.InitContainerStatuses {
+				restarts[status.Name] = status.RestartCount
+			}
+			for _, status := range pod.Status.ContainerStatuses {
+				restarts[status.Name] = status.RestartCount
+			}
+			var containers []corev1.Container
+			containers = append(containers, pod.Spec.InitContainers...)
+			containers = append(containers, pod.Spec.Containers...)
+			for _, container := range containers {
+				dir := fmt.Sprintf("logs/%s/%s/", component.Name, pod.Name)
+				options := &corev1.PodLogOptions{
+					Container:  container.Name,
+					LimitBytes: &limitBytes,
+					TailLines:  &tailLines,
+				}
+				if err := c.collectLog(ctx, b, pod.Name, options, dir+container.Name+".log"); err != nil {
+					errs = append(errs, err.Error())
+				}
+				if restarts[container.Name] > 0 {
+					options.Previous = true
+					if err := c.collectLog(ctx, b, pod.Name, options, dir+container.Name+".previous.log"); err != nil {
+						errs = append(errs, err.Error())
+					}
+				}
+			}
+		}
+	}
+	if b.err != nil {
+		return b.err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *Collector) collectLog(ctx context.Context, b *bundle, pod string, options *corev1.PodLogOptions, name string) error {
+	stream, err := c.KubeClient.CoreV1().Pods(c.Namespace).GetLogs(pod, options).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("logs of %s/%s: %v", pod, options.Container, err)
+	}
+	defer stream.Close()
+	data, err := ioutil.ReadAll(stream)
+	if err != nil {
+		return fmt.Errorf("logs of %s/%s: %v", pod, options.Container, err)
+	}
+	return b.add(name, []byte(redactText(string(data))))
+}
+
+// collectHelmReleases adds the status and the manifest of the latest
+// revision of every Helm release in the namespace, and the history of all
+// revisions
+func (c *Collector) collectHelmReleases(ctx context.Context, b *bundle) error {
+	releases, err := driver.NewSecrets(c.KubeClient.CoreV1().Secrets(c.Namespace)).List(func(*release.Release) bool { return true })
+	if err != nil {
+		return err
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Name != releases[j].Name {
+			return releases[i].Name < releases[j].Name
+		}
+		return releases[i].Version < releases[j].Version
+	})
+
+	var history bytes.Buffer
+	w := tabwriter.NewWriter(&history, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREVISION\tUPDATED\tSTATUS\tCHART\tDESCRIPTION")
+	var latest []*release.Release
+	for i, rel := range releases {
+		if i == len(releases)-1 || releases[i+1].Name != rel.Name {
+			latest = append(latest, rel)
+		}
+		updated, status, description := "", "", ""
+		if rel.Info != nil {
+			if !rel.Info.LastDeployed.IsZero() {
+				updated = rel.Info.LastDeployed.UTC().Format("2006-01-02T15:04:05Z")
+			}
+			status = rel.Info.Status.String()
+			description = rel.Info.Description
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", rel.Name, rel.Version, updated, status, chartName(rel), description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := b.add("helm/history.txt", history.Bytes()); err != nil {
+		return err
+	}
+
+	for _, rel := range latest {
+		if err := b.add("helm/"+rel.Name+"/manifest.yaml", []byte(redactManifest(rel.Manifest))); err != nil {
+			return err
+		}
+		if rel.Info != nil && rel.Info.Notes != "" {
+			if err := b.add("helm/"+rel.Name+"/notes.txt", []byte(rel.Info.Notes)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func chartName(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return ""
+	}
+	return rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+}
+
+// collectStorage adds the PersistentVolumeClaims of the namespace and the
+// PersistentVolumes bound to them or created for the namespace
+func (c *Collector) collectStorage(ctx context.Context, b *bundle) error {
+	pvcs, err := c.KubeClient.CoreV1().PersistentVolumeClaims(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if err := b.addYAML("storage/persistentvolumeclaims.yaml", pvcs); err != nil {
+		return err
+	}
+
+	pvs, err := c.KubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	namespacePVs := &corev1.PersistentVolumeList{}
+	for _, pv := range pvs.Items {
+		// The operator names its PersistentVolumes <name>--<namespace>
+		if (pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Namespace == c.Namespace) || strings.HasSuffix(pv.Name, "--"+c.Namespace) {
+			namespacePVs.Items = append(namespacePVs.Items, pv)
+		}
+	}
+	return b.addYAML("storage/persistentvolumes.yaml", namespacePVs)
+}
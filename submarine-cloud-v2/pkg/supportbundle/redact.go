@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package supportbundle
+
+import (
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Redacted replaces every secret value in a bundle
+const Redacted = "REDACTED"
+
+var (
+	// sensitiveName matches the names of environment variables, properties
+	// and keys that hold secrets
+	sensitiveName = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private[_.-]?key|access[_.-]?key)`)
+	// sensitiveXMLProperty matches a <property> of submarine-site.xml whose
+	// name is sensitive
+	sensitiveXMLProperty = regexp.MustCompile(`(?is)(<name>[^<]*(?:password|passwd|secret|token|credential)[^<]*</name>\s*<value>)[^<]*(</value>)`)
+	// sensitiveAssignment matches "password=..." and "password: ..." in
+	// logs, URLs and configuration files
+	sensitiveAssignment = regexp.MustCompile(`(?i)((?:password|passwd|secret|token)[\w.-]*["']?\s*[:=]\s*["']?)[^\s"'&,;<]+`)
+)
+
+// redactText replaces the secret values in free-form text such as logs and
+// configuration files
+func redactText(text string) string {
+	text = sensitiveXMLProperty.ReplaceAllString(text, "${1}"+Redacted+"${2}")
+	return sensitiveAssignment.ReplaceAllString(text, "${1}"+Redacted)
+}
+
+// redactMetadata drops the copies of the redacted values kept in the
+// metadata of an object: the last configuration applied by kubectl, which
+// holds the whole object, and the managed fields
+func redactMetadata(meta *metav1.ObjectMeta) {
+	delete(meta.Annotations, corev1.LastAppliedConfigAnnotation)
+	meta.ManagedFields = nil
+}
+
+// redactSecret replaces the values of a Secret, keeping its keys. The keys
+// are moved to stringData so that the bundle stays readable.
+func redactSecret(secret *corev1.Secret) {
+	redactMetadata(&secret.ObjectMeta)
+	if len(secret.Data) > 0 && secret.StringData == nil {
+		secret.StringData = map[string]string{}
+	}
+	for key := range secret.Data {
+		secret.StringData[key] = Redacted
+	}
+	for key := range secret.StringData {
+		secret.StringData[key] = Redacted
+	}
+	secret.Data = nil
+}
+
+// redactPodSpec replaces the literal values of sensitive environment
+// variables in every container of a pod template, and tells whether it
+// replaced any value
+func redactPodSpec(spec *corev1.PodSpec) bool {
+	redacted := false
+	redactContainers := func(containers []corev1.Container) {
+		for i := range containers {
+			for j := range containers[i].Env {
+				env := &containers[i].Env[j]
+				if env.Value != "" && env.Value != Redacted && sensitiveName.MatchString(env.Name) {
+					env.Value = Redacted
+					redacted = true
+				}
+			}
+			for j, arg := range containers[i].Args {
+				if containers[i].Args[j] = redactText(arg); containers[i].Args[j] != arg {
+					redacted = true
+				}
+			}
+		}
+	}
+	redactContainers(spec.InitContainers)
+	redactContainers(spec.Containers)
+	return redacted
+}
+
+// redactConfigMap replaces the secret values in the data of a ConfigMap,
+// and drops the copies of its metadata when it replaced any value
+func redactConfigMap(configmap *corev1.ConfigMap) {
+	redacted := len(configmap.BinaryData) > 0
+	for key, value := range configmap.Data {
+		if sensitiveName.MatchString(key) {
+			configmap.Data[key] = Redacted
+		} else {
+			configmap.Data[key] = redactText(value)
+		}
+		if configmap.Data[key] != value {
+			redacted = true
+		}
+	}
+	for key := range configmap.BinaryData {
+		configmap.BinaryData[key] = []byte(Redacted)
+	}
+	if redacted {
+		redactMetadata(&configmap.ObjectMeta)
+	}
+}
+
+// redactManifest replaces the data of the Secrets and the secret values of
+// the other objects in a multi-document YAML manifest
+func redactManifest(manifest string) string {
+	documents := strings.Split(manifest, "\n---")
+	for i, document := range documents {
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil || object["kind"] != "Secret" {
+			documents[i] = redactText(document)
+			continue
+		}
+		for _, field := range []string{"data", "stringData"} {
+			if values, ok := object[field].(map[string]interface{}); ok {
+				for key := range values {
+					values[key] = Redacted
+				}
+			}
+		}
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			documents[i] = "\n# Secret " + Redacted + "\n"
+			continue
+		}
+		// Keep the "# Source:" comment of Helm
+		header := ""
+		for _, line := range strings.Split(document, "\n") {
+			if strings.HasPrefix(line, "#") {
+				header += line + "\n"
+			}
+		}
+		if i > 0 {
+			header = "\n" + header
+		}
+		documents[i] = header + string(data)
+	}
+	return strings.Join(documents, "\n---")
+}
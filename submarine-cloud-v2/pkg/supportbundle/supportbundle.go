@@ -0,0 +1,175 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package supportbundle collects the state of a Submarine and its namespace
+// into a tarball for troubleshooting.
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"submarine-cloud-v2/pkg/generated/clientset/versioned"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Component is a part of a Submarine whose pod logs are collected
+type Component struct {
+	Name     string
+	Selector string
+}
+
+// DefaultComponents returns the components created by the operator for the
+// Submarine name, and the traefik subchart shared by the namespace
+func DefaultComponents(name string) []Component {
+	return []Component{
+		{Name: "server", Selector: "run=" + name + "-server"},
+		{Name: "database", Selector: "app=" + name + "-database"},
+		{Name: "tensorboard", Selector: "app=" + name + "-tensorboard-pod"},
+		{Name: "traefik", Selector: "app.kubernetes.io/name=traefik"},
+	}
+}
+
+const (
+	// DefaultLogLimitBytes bounds the log of each container
+	DefaultLogLimitBytes = 1 << 20
+	// DefaultLogTailLines bounds the number of log lines of each container
+	DefaultLogTailLines = 10000
+)
+
+// Collector collects a support bundle for the Submarine Namespace/Name
+type Collector struct {
+	KubeClient      kubernetes.Interface
+	SubmarineClient versioned.Interface
+
+	Namespace string
+	Name      string
+
+	// Components defaults to DefaultComponents(Name)
+	Components    []Component
+	LogLimitBytes int64
+	LogTailLines  int64
+
+	// Now returns the time the bundle is named after, time.Now if nil
+	Now func() time.Time
+}
+
+// collectFunc adds the files of one kind of state to the bundle
+type collectFunc func(ctx context.Context, b *bundle) error
+
+// WriteBundle collects a bundle into a timestamped tarball in dir, and
+// returns the path of the tarball
+func (c *Collector) WriteBundle(ctx context.Context, dir string) (string, error) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	name := fmt.Sprintf("submarine-bundle-%s-%s-%s", c.Namespace, c.Name, now().UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name+".tar.gz")
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	err = c.Collect(ctx, name, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// Collect writes a gzipped tarball to out, with every file under the
+// directory root. A collector that fails does not stop the others; its
+// error is recorded in errors.txt of the bundle.
+func (c *Collector) Collect(ctx context.Context, root string, out io.Writer) error {
+	gz := gzip.NewWriter(out)
+	b := &bundle{
+		tw:   tar.NewWriter(gz),
+		root: root,
+		now:  time.Now(),
+	}
+
+	collectors := []struct {
+		name    string
+		collect collectFunc
+	}{
+		{"submarine", c.collectSubmarine},
+		{"events", c.collectEvents},
+		{"workloads", c.collectWorkloads},
+		{"logs", c.collectLogs},
+		{"helm", c.collectHelmReleases},
+		{"storage", c.collectStorage},
+	}
+	var errs []string
+	for _, collector := range collectors {
+		if err := collector.collect(ctx, b); err != nil {
+			if b.err != nil {
+				// The tarball itself could not be written
+				return b.err
+			}
+			errs = append(errs, fmt.Sprintf("%s: %s", collector.name, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		b.add("errors.txt", []byte(strings.Join(errs, "\n")+"\n"))
+	}
+
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return b.err
+}
+
+// bundle writes files into a tarball and keeps the first write error
+type bundle struct {
+	tw   *tar.Writer
+	root string
+	now  time.Time
+	err  error
+}
+
+func (b *bundle) add(name string, data []byte) error {
+	if b.err != nil {
+		return b.err
+	}
+	header := &tar.Header{
+		Name:    b.root + "/" + name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.now,
+	}
+	if b.err = b.tw.WriteHeader(header); b.err != nil {
+		return b.err
+	}
+	_, b.err = b.tw.Write(data)
+	return b.err
+}
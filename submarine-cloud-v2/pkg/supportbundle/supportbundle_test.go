@@ -0,0 +1,248 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "submarine-user-test"
+
+// readBundle returns the files of a gzipped tarball by name
+func readBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func TestCollect(t *testing.T) {
+	submarine := &v1alpha1.Submarine{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-submarine", Namespace: testNamespace},
+		Spec:       v1alpha1.SubmarineSpec{Version: "0.6.0"},
+	}
+	serverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "submarine-server-1", Namespace: testNamespace, Labels: map[string]string{"run": "example-submarine-server"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "submarine-server"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "submarine-server", RestartCount: 1}},
+		},
+	}
+	databasePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "submarine-database-1", Namespace: testNamespace, Labels: map[string]string{"app": "example-submarine-database"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "submarine-database",
+				Env:  []corev1.EnvVar{{Name: "MYSQL_ROOT_PASSWORD", Value: "hunter2"}},
+			}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "root-pass-secret", Namespace: testNamespace},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	// Created with kubectl apply, which keeps the whole object in an
+	// annotation
+	appliedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "minio-credentials",
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"minio-credentials"},"stringData":{"secretKey":"hunter2"}}`,
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}},
+		},
+		StringData: map[string]string{"secretKey": "hunter2"},
+	}
+	databaseDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "submarine-database",
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"spec":{"template":{"spec":{"containers":[{"env":[{"name":"MYSQL_ROOT_PASSWORD","value":"hunter2"}]}]}}}}`,
+			},
+		},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: databasePod.Spec}},
+	}
+	serverPod.Annotations = map[string]string{corev1.LastAppliedConfigAnnotation: `{"kind":"Pod"}`}
+	configmap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "submarine-server-config", Namespace: testNamespace},
+		Data: map[string]string{
+			"submarine-site.xml": "<property><name>jdbc.password</name><value>hunter2</value></property>",
+		},
+	}
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "event-1", Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "submarine-server-1"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "submarine-database-pv--" + testNamespace},
+	}
+	otherPV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "submarine-database-pv--other"},
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(serverPod, databasePod, databaseDeployment, secret, appliedSecret, configmap, event, pv, otherPV)
+	releases := driver.NewSecrets(kubeClient.CoreV1().Secrets(testNamespace))
+	for _, version := range []int{1, 2} {
+		err := releases.Create(fmt.Sprintf("sh.helm.release.v1.traefik.v%d", version), &release.Release{
+			Name:      "traefik",
+			Namespace: testNamespace,
+			Version:   version,
+			Info:      &release.Info{Status: release.StatusDeployed},
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "traefik", Version: "9.1.0"}},
+			Manifest:  "---\n# Source: traefik/templates/secret.yaml\napiVersion: v1\nkind: Secret\nmetadata:\n  name: traefik\ndata:\n  token: c2VjcmV0\n",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collector := &Collector{
+		KubeClient:      kubeClient,
+		SubmarineClient: fake.NewSimpleClientset(submarine),
+		Namespace:       testNamespace,
+		Name:            "example-submarine",
+	}
+	var out bytes.Buffer
+	if err := collector.Collect(context.TODO(), "bundle", &out); err != nil {
+		t.Fatal(err)
+	}
+	files := readBundle(t, out.Bytes())
+
+	for _, name := range []string{
+		"bundle/submarine.yaml",
+		"bundle/events.txt",
+		"bundle/deployments.yaml",
+		"bundle/pods.yaml",
+		"bundle/secrets.yaml",
+		"bundle/configmaps.yaml",
+		"bundle/logs/server/submarine-server-1/submarine-server.log",
+		"bundle/logs/server/submarine-server-1/submarine-server.previous.log",
+		"bundle/logs/database/submarine-database-1/submarine-database.log",
+		"bundle/helm/history.txt",
+		"bundle/helm/traefik/manifest.yaml",
+		"bundle/storage/persistentvolumeclaims.yaml",
+		"bundle/storage/persistentvolumes.yaml",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in the bundle", name)
+		}
+	}
+	if errors, ok := files["bundle/errors.txt"]; ok {
+		t.Errorf("unexpected errors: %s", errors)
+	}
+
+	for name, content := range files {
+		if strings.Contains(content, "hunter2") || strings.Contains(content, "c2VjcmV0") {
+			t.Errorf("%s contains a secret:\n%s", name, content)
+		}
+	}
+	if strings.Contains(files["bundle/secrets.yaml"], "managedFields") {
+		t.Errorf("expected no managed fields in secrets.yaml:\n%s", files["bundle/secrets.yaml"])
+	}
+	// Objects without redacted values keep their annotations
+	if !strings.Contains(files["bundle/pods.yaml"], corev1.LastAppliedConfigAnnotation) {
+		t.Errorf("expected the annotation of the server pod in pods.yaml:\n%s", files["bundle/pods.yaml"])
+	}
+	if !strings.Contains(files["bundle/events.txt"], "BackOff") {
+		t.Errorf("expected the event in events.txt:\n%s", files["bundle/events.txt"])
+	}
+	if strings.Count(files["bundle/helm/history.txt"], "\ntraefik ") != 2 {
+		t.Errorf("expected both revisions in the history:\n%s", files["bundle/helm/history.txt"])
+	}
+	if !strings.Contains(files["bundle/storage/persistentvolumes.yaml"], pv.Name) || strings.Contains(files["bundle/storage/persistentvolumes.yaml"], otherPV.Name) {
+		t.Errorf("expected only the PersistentVolumes of the namespace:\n%s", files["bundle/storage/persistentvolumes.yaml"])
+	}
+}
+
+func TestCollectMissingSubmarine(t *testing.T) {
+	collector := &Collector{
+		KubeClient:      kubefake.NewSimpleClientset(),
+		SubmarineClient: fake.NewSimpleClientset(),
+		Namespace:       testNamespace,
+		Name:            "example-submarine",
+	}
+	var out bytes.Buffer
+	if err := collector.Collect(context.TODO(), "bundle", &out); err != nil {
+		t.Fatal(err)
+	}
+	files := readBundle(t, out.Bytes())
+	if !strings.Contains(files["bundle/errors.txt"], "submarine:") {
+		t.Errorf("expected the missing Submarine in errors.txt, got %q", files["bundle/errors.txt"])
+	}
+	if _, ok := files["bundle/events.txt"]; !ok {
+		t.Errorf("expected the other collectors to run")
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	tests := map[string]string{
+		"jdbc:mysql://db:3306/submarine?user=root&password=hunter2&useSSL=false": "jdbc:mysql://db:3306/submarine?user=root&password=REDACTED&useSSL=false",
+		"<name>jdbc.password</name>\n  <value>hunter2</value>":                   "<name>jdbc.password</name>\n  <value>REDACTED</value>",
+		"token: abc123":               "token: REDACTED",
+		"Started server on port 8080": "Started server on port 8080",
+	}
+	for input, expected := range tests {
+		if actual := redactText(input); actual != expected {
+			t.Errorf("redactText(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// imagePullFailures are the reasons of the waiting containers whose image
+// cannot be pulled
+var imagePullFailures = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+const (
+	// prePullNoopVolume holds the no-op command of the pre-pull containers.
+	// It is copied from a static binary, so that images without a shell, or
+	// even without any file, can be pulled.
+	prePullNoopVolume = "prepull-noop"
+	prePullNoopDir    = "/prepull-noop"
+	// prePullNoopCommand is the command of the pre-pull containers, which
+	// exits right away. The static busybox runs the applet of its name.
+	prePullNoopCommand = prePullNoopDir + "/true"
+)
+
+// environmentImagesRefresh is how long the images of the environments of a
+// Submarine are used before they are listed again
+const environmentImagesRefresh = time.Minute
+
+// prePullEnabled returns whether the images of spec.prePull are pulled on the
+// nodes by a DaemonSet
+func prePullEnabled(submarine *v1alpha1.Submarine) bool {
+	return submarine.Spec.PrePull != nil
+}
+
+func prePullName(submarine *v1alpha1.Submarine) string {
+	return submarine.Name + "-image-prepuller"
+}
+
+func prePullContainerName(i int) string {
+	return fmt.Sprintf("prepull-%d", i)
+}
+
+// environmentList is the response of the /api/v1/environment API of the
+// server
+type environmentList struct {
+	Result []struct {
+		EnvironmentSpec struct {
+			DockerImage string `json:"dockerImage"`
+			Image       string `json:"image"`
+		} `json:"environmentSpec"`
+	} `json:"result"`
+}
+
+// environmentImagesTimeout bounds a listing of the environments of a server
+const environmentImagesTimeout = 10 * time.Second
+
+// newEnvironmentImagesFunc returns a function listing the images of the
+// environments of the server of a Submarine. The server is reached through
+// the proxy of the API server to its Service, so that the operator does not
+// need to run in-cluster.
+func newEnvironmentImagesFunc(kubeclientset kubernetes.Interface) func(submarine *v1alpha1.Submarine) ([]string, error) {
+	return func(submarine *v1alpha1.Submarine) ([]string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), environmentImagesTimeout)
+		defer cancel()
+		body, err := kubeclientset.CoreV1().Services(submarine.Namespace).
+			ProxyGet("http", serverName(submarine), "8080", "/api/v1/environment", nil).
+			DoRaw(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var environments environmentList
+		if err := json.Unmarshal(body, &environments); err != nil {
+			return nil, err
+		}
+		var images []string
+		for _, environment := range environments.Result {
+			images = append(images, environment.EnvironmentSpec.DockerImage, environment.EnvironmentSpec.Image)
+		}
+		return images, nil
+	}
+}
+
+// environmentImagesEntry holds the images of the environments of a
+// Submarine
+type environmentImagesEntry struct {
+	images []string
+	// listed is when images were listed, zero until a listing succeeded
+	listed bool
+	// started is when the latest listing started
+	started time.Time
+	// listing is set while a listing runs
+	listing bool
+}
+
+// cachedEnvironmentImages returns the images of the environments of a
+// Submarine from the latest successful listing, and whether there was one.
+// The images are listed in the background every environmentImagesRefresh,
+// so that an unavailable server does not hold the reconciles. The Submarine
+// is synced again once new images are listed.
+func (c *Controller) cachedEnvironmentImages(submarine *v1alpha1.Submarine) ([]string, bool) {
+	key := submarine.Namespace + "/" + submarine.Name
+	c.environmentImagesLock.Lock()
+	defer c.environmentImagesLock.Unlock()
+	entry := c.environmentImagesEntries[key]
+	if entry == nil {
+		entry = &environmentImagesEntry{}
+		c.environmentImagesEntries[key] = entry
+	}
+	if !entry.listing && time.Since(entry.started) >= environmentImagesRefresh {
+		entry.listing = true
+		entry.started = time.Now()
+		go func() {
+			images, err := c.environmentImages(submarine)
+			images = uniqueImages(images)
+			c.environmentImagesLock.Lock()
+			entry.listing = false
+			changed := false
+			if err != nil {
+				klog.Info("Cannot list the environments of Submarine ", submarine.Name, ": ", err)
+			} else {
+				changed = !entry.listed || !reflect.DeepEqual(images, entry.images)
+				entry.images = images
+				entry.listed = true
+			}
+			c.environmentImagesLock.Unlock()
+			if changed {
+				c.enqueueSubmarine(submarine, UPDATE)
+			}
+		}()
+	}
+	return entry.images, entry.listed
+}
+
+// uniqueImages returns the sorted, non-empty images of lists without
+// duplicates
+func uniqueImages(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var images []string
+	for _, list := range lists {
+		for _, image := range list {
+			if image != "" && !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// newPrePullDaemonSet returns the DaemonSet pulling images. Every image runs
+// once as an init container, which pulls it on the node, then the pod stays
+// idle so that the images are not garbage collected. The init containers run
+// a no-op command copied by the first one, since the images may not have a
+// shell.
+func newPrePullDaemonSet(submarine *v1alpha1.Submarine, images []string) *appsv1.DaemonSet {
+	name := prePullName(submarine)
+	selector := map[string]string{"app": name}
+	noopMount := corev1.VolumeMount{Name: prePullNoopVolume, MountPath: prePullNoopDir}
+	initContainers := []corev1.Container{
+		{
+			Name:            prePullNoopVolume,
+			Image:           prePullNoopImage(submarine),
+			Command:         []string{"cp", "/bin/true", prePullNoopCommand},
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			VolumeMounts:    []corev1.VolumeMount{noopMount},
+		},
+	}
+	noopMount.ReadOnly = true
+	for i, image := range images {
+		initContainers = append(initContainers, corev1.Container{
+			Name:            prePullContainerName(i),
+			Image:           image,
+			Command:         []string{prePullNoopCommand},
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			VolumeMounts:    []corev1.VolumeMount{noopMount},
+		})
+	}
+	// Pulled images stay on the nodes, so replacing every pod at once only
+	// pulls the new images sooner
+	maxUnavailable := intstr.FromString("100%")
+	gracePeriod := int64(0)
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: submarineLabels(submarine, "image-prepuller", selector),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: &maxUnavailable,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: submarineLabels(submarine, "image-prepuller", selector),
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name:         prePullNoopVolume,
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+					InitContainers: initContainers,
+					Containers: []corev1.Container{
+						{
+							Name:  "pause",
+							Image: prePullPauseImage(submarine),
+						},
+					},
+					NodeSelector:                  submarine.Spec.PrePull.NodeSelector,
+					Tolerations:                   submarine.Spec.PrePull.Tolerations,
+					ImagePullSecrets:              submarine.Spec.ImagePullSecrets,
+					TerminationGracePeriodSeconds: &gracePeriod,
+				},
+			},
+		},
+	}
+}
+
+// nodePullStatus returns the state of the images of a pod of the pre-puller.
+// An init container that ran, or is running, has pulled its image.
+func nodePullStatus(pod *corev1.Pod, images []string) v1alpha1.SubmarineNodePullStatus {
+	status := v1alpha1.SubmarineNodePullStatus{Node: pod.Spec.NodeName}
+	containers := map[string]corev1.ContainerStatus{}
+	for _, container := range pod.Status.InitContainerStatuses {
+		containers[container.Name] = container
+	}
+	for i := range images {
+		container := containers[prePullContainerName(i)]
+		switch {
+		case container.ImageID != "" || container.State.Running != nil || container.State.Terminated != nil:
+			status.Pulled++
+		case container.State.Waiting != nil && imagePullFailures[container.State.Waiting.Reason]:
+			status.Failed = append(status.Failed, images[i])
+		}
+	}
+	return status
+}
+
+// reconcilePrePull maintains the DaemonSet pulling the images of
+// spec.prePull and of the environments of the server, and reports the state
+// of the images on each node. The DaemonSet is deleted once spec.prePull is
+// unset.
+func (c *Controller) reconcilePrePull(submarine *v1alpha1.Submarine, namespace string) error {
+	client := c.kubeclientset.AppsV1().DaemonSets(namespace)
+	if !prePullEnabled(submarine) {
+		submarine.Status.PrePull = nil
+		daemonset, err := c.daemonsetLister.DaemonSets(namespace).Get(prePullName(submarine))
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(daemonset, submarine) {
+			return nil
+		}
+		klog.Info("	Delete DaemonSet: ", daemonset.Name)
+		return client.Delete(context.TODO(), daemonset.Name, metav1.DeleteOptions{})
+	}
+
+	status := &v1alpha1.SubmarinePrePullStatus{}
+	if submarine.Status.PrePull != nil {
+		status.EnvironmentImages = submarine.Status.PrePull.EnvironmentImages
+	}
+	if submarine.Spec.PrePull.Environments {
+		// Keep the images of the latest sync until the environments are
+		// listed, e.g. after a restart of the operator
+		if images, ok := c.cachedEnvironmentImages(submarine); ok {
+			status.EnvironmentImages = images
+		}
+	} else {
+		status.EnvironmentImages = nil
+	}
+	status.Images = uniqueImages(submarine.Spec.PrePull.Images, status.EnvironmentImages)
+	submarine.Status.PrePull = status
+
+	desired := newPrePullDaemonSet(submarine, status.Images)
+	daemonset, daemonset_err := c.daemonsetLister.DaemonSets(namespace).Get(desired.Name)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(daemonset_err) {
+		daemonset, daemonset_err = client.Create(context.TODO(), desired, metav1.CreateOptions{})
+		if daemonset_err != nil {
+			klog.Info(daemonset_err)
+		}
+		klog.Info("	Create DaemonSet: ", desired.Name)
+	}
+	if daemonset_err != nil {
+		return daemonset_err
+	}
+	if err := c.claimObject(submarine, daemonset, "image-prepuller", func(patch []byte) error {
+		daemonset, daemonset_err = client.Patch(context.TODO(), daemonset.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return daemonset_err
+	}); err != nil {
+		return err
+	}
+	// Update the DaemonSet if the images or the nodes changed
+	if !equality.Semantic.DeepDerivative(desired.Spec.Template.Spec, daemonset.Spec.Template.Spec) {
+		daemonsetCopy := daemonset.DeepCopy()
+		daemonsetCopy.Spec.Template = desired.Spec.Template
+		daemonset, daemonset_err = client.Update(context.TODO(), daemonsetCopy, metav1.UpdateOptions{})
+		if daemonset_err != nil {
+			return daemonset_err
+		}
+	}
+
+	status.DesiredNodes = daemonset.Status.DesiredNumberScheduled
+	pods, err := c.podLister.Pods(namespace).List(labels.SelectorFromSet(desired.Spec.Selector.MatchLabels))
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		// Pods of a previous template pull other images
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil || !equality.Semantic.DeepDerivative(desired.Spec.Template.Spec.InitContainers, pod.Spec.InitContainers) {
+			continue
+		}
+		node := nodePullStatus(pod, status.Images)
+		if int(node.Pulled) == len(status.Images) {
+			status.ReadyNodes++
+		}
+		status.Nodes = append(status.Nodes, node)
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool {
+		return status.Nodes[i].Node < status.Nodes[j].Node
+	})
+	return nil
+}
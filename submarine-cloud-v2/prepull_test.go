@@ -0,0 +1,251 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newPrePullTestPod returns a pod of the pre-puller on node whose init
+// containers are in states
+func newPrePullTestPod(submarine *v1alpha1.Submarine, node string, images []string, states ...corev1.ContainerState) *corev1.Pod {
+	template := newPrePullDaemonSet(submarine, images).Spec.Template
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prePullName(submarine) + "-" + node,
+			Namespace: "submarine-user-test",
+			Labels:    template.Labels,
+		},
+		Spec: template.Spec,
+	}
+	pod.Spec.NodeName = node
+	for i, state := range states {
+		pod.Status.InitContainerStatuses = append(pod.Status.InitContainerStatuses, corev1.ContainerStatus{
+			Name:  prePullContainerName(i),
+			State: state,
+		})
+	}
+	return pod
+}
+
+// listEnvironmentImages lists the images of the environments of a Submarine
+// into the cache of c, and returns them once the listing finished
+func listEnvironmentImages(t *testing.T, c *Controller, submarine *v1alpha1.Submarine) []string {
+	t.Helper()
+	c.cachedEnvironmentImages(submarine)
+	key := submarine.Namespace + "/" + submarine.Name
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		c.environmentImagesLock.Lock()
+		defer c.environmentImagesLock.Unlock()
+		return !c.environmentImagesEntries[key].listing, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, _ := c.cachedEnvironmentImages(submarine)
+	return images
+}
+
+// proxyResponse is the response of the API server proxy to a Service
+type proxyResponse string
+
+func (r proxyResponse) DoRaw(context.Context) ([]byte, error) {
+	return []byte(r), nil
+}
+
+func (r proxyResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(string(r))), nil
+}
+
+func TestEnvironmentImages(t *testing.T) {
+	submarine := newTestSubmarine()
+	_, kubeClient, _ := newTestController(t, testControllerOptions{})
+	var proxied k8stesting.ProxyGetAction
+	kubeClient.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+		proxied = action.(k8stesting.ProxyGetAction)
+		return true, proxyResponse(`{"result": [
+			{"environmentSpec": {"dockerImage": "notebook", "image": "tf-mnist"}},
+			{"environmentSpec": {"dockerImage": "notebook"}}
+		]}`), nil
+	})
+
+	images, err := newEnvironmentImagesFunc(kubeClient)(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server is reached through the API server, in and out of the
+	// cluster
+	if proxied.GetNamespace() != "submarine-user-test" || proxied.GetName() != serverName(submarine) ||
+		proxied.GetPort() != "8080" || proxied.GetPath() != "/api/v1/environment" {
+		t.Errorf("unexpected proxy request %+v", proxied)
+	}
+	if want := []string{"notebook", "tf-mnist", "notebook", ""}; !reflect.DeepEqual(images, want) {
+		t.Errorf("images = %q, want %q", images, want)
+	}
+}
+
+func TestCachedEnvironmentImages(t *testing.T) {
+	submarine := newTestSubmarine()
+	c, _, _ := newTestController(t, testControllerOptions{})
+	var environmentsErr error
+	listings := 0
+	c.environmentImages = func(*v1alpha1.Submarine) ([]string, error) {
+		listings++
+		return []string{"tf-mnist", "notebook", ""}, environmentsErr
+	}
+
+	// Nothing was listed yet: the images are listed in the background, then
+	// the Submarine is synced again
+	if _, ok := c.cachedEnvironmentImages(submarine); ok {
+		t.Error("expected no images before the first listing")
+	}
+	images := []string{"notebook", "tf-mnist"}
+	if got := listEnvironmentImages(t, c, submarine); !reflect.DeepEqual(got, images) {
+		t.Errorf("images = %v, want %v", got, images)
+	}
+	if c.workqueue.Len() != 1 {
+		t.Errorf("expected the Submarine to be synced again, got %d items", c.workqueue.Len())
+	}
+
+	// Recent images are not listed again
+	listEnvironmentImages(t, c, submarine)
+	if listings != 1 {
+		t.Errorf("listings = %d, want 1", listings)
+	}
+
+	// The server is unavailable: the images are kept
+	environmentsErr = fmt.Errorf("connection refused")
+	c.environmentImagesLock.Lock()
+	c.environmentImagesEntries[submarine.Namespace+"/"+submarine.Name].started = time.Now().Add(-environmentImagesRefresh)
+	c.environmentImagesLock.Unlock()
+	if got := listEnvironmentImages(t, c, submarine); !reflect.DeepEqual(got, images) {
+		t.Errorf("images = %v, want %v", got, images)
+	}
+	if listings != 2 {
+		t.Errorf("listings = %d, want 2", listings)
+	}
+}
+
+func TestPrePull(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.PrePull = &v1alpha1.SubmarinePrePull{
+		Images:       []string{"tf-mnist", "pytorch-mnist"},
+		Environments: true,
+		NodeSelector: map[string]string{"submarine.k8s.io/prepull": "true"},
+	}
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	c.environmentImages = func(*v1alpha1.Submarine) ([]string, error) {
+		return []string{"notebook", "tf-mnist", ""}, nil
+	}
+	listEnvironmentImages(t, c, submarine)
+
+	if err := c.reconcilePrePull(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	images := []string{"notebook", "pytorch-mnist", "tf-mnist"}
+	if got := submarine.Status.PrePull.Images; !reflect.DeepEqual(got, images) {
+		t.Errorf("images = %v, want %v", got, images)
+	}
+	daemonset, err := kubeClient.AppsV1().DaemonSets("submarine-user-test").Get(context.TODO(), prePullName(submarine), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := daemonset.Spec.Template.Spec
+	if len(spec.InitContainers) != 4 || spec.InitContainers[2].Image != "pytorch-mnist" {
+		t.Fatalf("unexpected init containers %v", spec.InitContainers)
+	}
+	// The images may not have a shell: they run the no-op command copied
+	// by the first init container
+	if noop := spec.InitContainers[0]; noop.Image != defaultPrePullNoopImage || !reflect.DeepEqual(noop.Command, []string{"cp", "/bin/true", prePullNoopCommand}) {
+		t.Errorf("unexpected first init container %v", noop)
+	}
+	for _, container := range spec.InitContainers[1:] {
+		if !reflect.DeepEqual(container.Command, []string{prePullNoopCommand}) || len(container.VolumeMounts) != 1 {
+			t.Errorf("unexpected init container %v", container)
+		}
+	}
+	if spec.NodeSelector["submarine.k8s.io/prepull"] != "true" {
+		t.Errorf("unexpected node selector %v", spec.NodeSelector)
+	}
+
+	// After a restart of the operator, the images of the environments of
+	// the status are kept until they are listed again
+	done := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	failed := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}
+	pulling := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}
+	objects := []runtime.Object{
+		daemonset,
+		newPrePullTestPod(submarine, "node-b", images, done, failed),
+		newPrePullTestPod(submarine, "node-a", images, done, done, running),
+		newPrePullTestPod(submarine, "node-c", images, done, pulling),
+		// Pod of the images before a change
+		newPrePullTestPod(submarine, "node-d", []string{"old"}, done),
+	}
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: objects})
+	c.environmentImages = func(*v1alpha1.Submarine) ([]string, error) {
+		return nil, fmt.Errorf("connection refused")
+	}
+	if err := c.reconcilePrePull(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	status := submarine.Status.PrePull
+	if !reflect.DeepEqual(status.Images, images) {
+		t.Errorf("images = %v, want %v", status.Images, images)
+	}
+	want := []v1alpha1.SubmarineNodePullStatus{
+		{Node: "node-a", Pulled: 3},
+		{Node: "node-b", Pulled: 1, Failed: []string{"pytorch-mnist"}},
+		{Node: "node-c", Pulled: 1},
+	}
+	if !reflect.DeepEqual(status.Nodes, want) {
+		t.Errorf("nodes = %+v, want %+v", status.Nodes, want)
+	}
+	if status.ReadyNodes != 1 {
+		t.Errorf("ready nodes = %d, want 1", status.ReadyNodes)
+	}
+
+	// Without spec.prePull: deleted
+	submarine.Spec.PrePull = nil
+	if err := c.reconcilePrePull(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.AppsV1().DaemonSets("submarine-user-test").Get(context.TODO(), prePullName(submarine), metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the DaemonSet to be deleted, got %v", err)
+	}
+	if submarine.Status.PrePull != nil {
+		t.Error("expected no pre-pull status")
+	}
+}
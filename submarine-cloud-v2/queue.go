@@ -0,0 +1,508 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// queueSchedulerName is the scheduler of the pods of a queued job. No
+	// scheduler has that name, so the pods stay pending until the job is
+	// admitted and they are recreated.
+	queueSchedulerName = "submarine-queue"
+	// queueStateAnnotation holds the JobQueueState of a job
+	queueStateAnnotation = "submarine.k8s.io/queue-state"
+	// queuePositionAnnotation holds the position of a queued job
+	queuePositionAnnotation = "submarine.k8s.io/queue-position"
+	// queuePriorityAnnotation is set by users to admit a job before the jobs
+	// of lower priority
+	queuePriorityAnnotation = "submarine.k8s.io/queue-priority"
+	// queueSchedulersAnnotation holds the schedulers of the replicas of a
+	// queued job, restored once it is admitted
+	queueSchedulersAnnotation = "submarine.k8s.io/queue-schedulers"
+	// queueSubmarineAnnotation holds the name of the Submarine whose queue
+	// holds a job
+	queueSubmarineAnnotation = "submarine.k8s.io/queue-submarine"
+)
+
+const (
+	// JobAdmittedReason is used as part of the Event 'reason' when a queued job is
+	// admitted
+	JobAdmittedReason = "JobAdmitted"
+	// MessageJobAdmitted is the message used for an Event fired when a
+	// queued job is admitted
+	MessageJobAdmitted = "Admitted %s %s after %s in the queue"
+)
+
+// queuedJobTypes are the jobs held by the admission queue, by resource
+var queuedJobTypes = map[string]experimentJob{
+	"tfjobs":      experimentJobs[v1alpha1.ExperimentFrameworkTensorFlow],
+	"pytorchjobs": experimentJobs[v1alpha1.ExperimentFrameworkPyTorch],
+}
+
+// countedJobTypes are the jobs that are not held by the admission queue but
+// whose replicas use its capacity, by resource. The launcher of an MPIJob is
+// a batch Job keeping the scheduler it was created with, so the pods of
+// MPIJobs cannot be held; XGBoostJobs run on the same nodes as the others.
+var countedJobTypes = map[string]experimentJob{
+	"mpijobs": {
+		kind:         "MPIJob",
+		resource:     "mpijobs",
+		replicaSpecs: "mpiReplicaSpecs",
+		operator:     v1alpha1.OperatorMPIJob,
+	},
+	"xgboostjobs": {
+		kind:         "XGBoostJob",
+		resource:     "xgboostjobs",
+		replicaSpecs: "xgbReplicaSpecs",
+		operator:     v1alpha1.OperatorXGBoostJob,
+	},
+}
+
+// capacityJobTypes are the jobs whose replicas use the capacity of the
+// admission queue, by resource
+func capacityJobTypes() map[string]experimentJob {
+	jobTypes := map[string]experimentJob{}
+	for resource, jobType := range queuedJobTypes {
+		jobTypes[resource] = jobType
+	}
+	for resource, jobType := range countedJobTypes {
+		jobTypes[resource] = jobType
+	}
+	return jobTypes
+}
+
+// queueJob is a job of the admission queue
+type queueJob struct {
+	resource string
+	job      *unstructured.Unstructured
+	priority int32
+	demand   corev1.ResourceList
+}
+
+// namespaceQueue returns the Submarine of the namespace whose queue is
+// enabled, or nil
+func (c *Controller) namespaceQueue(namespace string) (*v1alpha1.Submarine, error) {
+	submarines, err := c.submarinesLister.Submarines(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, submarine := range submarines {
+		if submarine.Spec.Queue != nil && submarine.Spec.Queue.Enabled && submarine.DeletionTimestamp == nil {
+			return submarine, nil
+		}
+	}
+	return nil, nil
+}
+
+// replicaSpecs returns the replica specs of a job by replica type
+func replicaSpecs(job *unstructured.Unstructured, jobType experimentJob) map[string]interface{} {
+	specs, _, _ := unstructured.NestedMap(job.Object, "spec", jobType.replicaSpecs)
+	return specs
+}
+
+// jobDemand returns the requests of all the replicas of a job. Containers
+// without requests count their limits.
+func jobDemand(job *unstructured.Unstructured, jobType experimentJob) (corev1.ResourceList, error) {
+	demand := corev1.ResourceList{}
+	for replicaType, spec := range replicaSpecs(job, jobType) {
+		spec, ok := spec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		replicas := int64(1)
+		if value, found, _ := unstructured.NestedInt64(spec, "replicas"); found {
+			replicas = value
+		}
+		templateObject, _, _ := unstructured.NestedMap(spec, "template")
+		template := corev1.PodTemplateSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateObject, &template); err != nil {
+			return nil, fmt.Errorf("invalid template of replica type %s: %v", replicaType, err)
+		}
+		for _, container := range template.Spec.Containers {
+			requests := container.Resources.Requests
+			if len(requests) == 0 {
+				requests = container.Resources.Limits
+			}
+			for name, quantity := range requests {
+				total := demand[name]
+				for i := int64(0); i < replicas; i++ {
+					total.Add(quantity)
+				}
+				demand[name] = total
+			}
+		}
+	}
+	return demand, nil
+}
+
+// addResources adds the quantities of b to a
+func addResources(a corev1.ResourceList, b corev1.ResourceList) {
+	for name, quantity := range b {
+		total := a[name]
+		total.Add(quantity)
+		a[name] = total
+	}
+}
+
+// fitsCapacity tells whether used and demand fit in capacity. Resources
+// missing from capacity are not limited.
+func fitsCapacity(capacity corev1.ResourceList, used corev1.ResourceList, demand corev1.ResourceList) bool {
+	for name, limit := range capacity {
+		total := used[name].DeepCopy()
+		total.Add(demand[name])
+		if total.Cmp(limit) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// admitJob queues the TFJobs and PyTorchJobs created in a namespace whose
+// Submarine has an enabled queue. The pods of their replicas go to a
+// scheduler that does not exist until reconcileQueue admits them.
+func (c *Controller) admitJob(request *admissionv1.AdmissionRequest) ([]jsonPatchOperation, error) {
+	jobType, ok := queuedJobTypes[request.Resource.Resource]
+	if !ok || request.Operation != admissionv1.Create {
+		return nil, nil
+	}
+	submarine, err := c.namespaceQueue(request.Namespace)
+	if err != nil || submarine == nil {
+		return nil, err
+	}
+	job := &unstructured.Unstructured{}
+	if err := job.UnmarshalJSON(request.Object.Raw); err != nil {
+		return nil, err
+	}
+
+	schedulers := map[string]string{}
+	var patch []jsonPatchOperation
+	for replicaType := range replicaSpecs(job, jobType) {
+		scheduler, _, _ := unstructured.NestedString(job.Object, "spec", jobType.replicaSpecs, replicaType, "template", "spec", "schedulerName")
+		schedulers[replicaType] = scheduler
+		patch = append(patch, jsonPatchOperation{
+			Op:    "add",
+			Path:  fmt.Sprintf("/spec/%s/%s/template/spec/schedulerName", jobType.replicaSpecs, jsonPointer(replicaType)),
+			Value: queueSchedulerName,
+		})
+	}
+	schedulersJSON, err := json.Marshal(schedulers)
+	if err != nil {
+		return nil, err
+	}
+	annotations := map[string]string{
+		queueStateAnnotation:      string(v1alpha1.JobQueued),
+		queueSchedulersAnnotation: string(schedulersJSON),
+		queueSubmarineAnnotation:  submarine.Name,
+	}
+	if job.GetAnnotations() == nil {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/metadata/annotations", Value: annotations})
+	} else {
+		for key, value := range annotations {
+			patch = append(patch, jsonPatchOperation{Op: "add", Path: "/metadata/annotations/" + jsonPointer(key), Value: value})
+		}
+	}
+
+	// Admit the job once it is created
+	if key, err := cache.MetaNamespaceKeyFunc(submarine); err == nil {
+		c.workqueue.AddAfter(WorkQueueItem{key: key, action: UPDATE}, time.Second)
+	}
+	klog.Info("	Queue ", jobType.kind, ": ", request.Namespace, "/", job.GetName())
+	return patch, nil
+}
+
+// queueHolds tells whether the queue of submarine holds a queued job: the
+// jobs queued by submarine, and the jobs left by a Submarine whose queue is
+// no longer enabled
+func (c *Controller) queueHolds(submarine *v1alpha1.Submarine, namespace string, job *unstructured.Unstructured) bool {
+	owner := job.GetAnnotations()[queueSubmarineAnnotation]
+	if owner == "" || owner == submarine.Name {
+		return true
+	}
+	other, err := c.submarinesLister.Submarines(namespace).Get(owner)
+	if err != nil {
+		return errors.IsNotFound(err)
+	}
+	return other.Spec.Queue == nil || !other.Spec.Queue.Enabled || other.DeletionTimestamp != nil
+}
+
+// reconcileQueue admits the queued jobs held by the queue of the Submarine
+// that fit in the capacity left by the admitted jobs of the namespace, in
+// the order of the queue, records the positions of the others, and
+// summarizes the queue in the status. When the queue is disabled, the queued
+// jobs are admitted unless another Submarine of the namespace has an enabled
+// queue, which then holds them.
+func (c *Controller) reconcileQueue(submarine *v1alpha1.Submarine, namespace string) error {
+	queue := submarine.Spec.Queue
+	enabled := queue != nil && queue.Enabled
+	if !enabled {
+		submarine.Status.Queue = nil
+		holder, err := c.namespaceQueue(namespace)
+		if err != nil {
+			return err
+		}
+		if holder != nil {
+			return nil
+		}
+	}
+
+	used := corev1.ResourceList{}
+	admitted := int32(0)
+	var queued []queueJob
+	// The jobs that are not held are never queued, and count as admitted
+	for resource, jobType := range capacityJobTypes() {
+		jobs, err := c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(resource)).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		c.watchJobs(resource)
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			demand, err := jobDemand(job, jobType)
+			if err != nil {
+				klog.Info(err)
+				continue
+			}
+			switch v1alpha1.JobQueueState(job.GetAnnotations()[queueStateAnnotation]) {
+			case v1alpha1.JobQueued, v1alpha1.JobExceedsCapacity:
+				if enabled && !c.queueHolds(submarine, namespace, job) {
+					continue
+				}
+				priority, _ := strconv.Atoi(job.GetAnnotations()[queuePriorityAnnotation])
+				queued = append(queued, queueJob{resource: resource, job: job, priority: int32(priority), demand: demand})
+			default:
+				// Jobs admitted, or created before the queue was enabled
+				if phase, _ := jobPhase(job); phase != v1alpha1.ExperimentSucceeded && phase != v1alpha1.ExperimentFailed {
+					addResources(used, demand)
+					admitted++
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(queued, func(i, j int) bool {
+		if queued[i].priority != queued[j].priority {
+			return queued[i].priority > queued[j].priority
+		}
+		ti, tj := queued[i].job.GetCreationTimestamp(), queued[j].job.GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return queued[i].job.GetName() < queued[j].job.GetName()
+	})
+
+	if !enabled {
+		for _, q := range queued {
+			if err := c.admitQueuedJob(submarine, namespace, q); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	status := &v1alpha1.SubmarineQueueStatus{Capacity: queue.Capacity}
+	// Jobs are admitted in order: a job that does not fit blocks the ones
+	// behind it, so that large jobs are not starved
+	blocked := false
+	position := int32(0)
+	for _, q := range queued {
+		state := v1alpha1.JobQueued
+		switch {
+		case !fitsCapacity(queue.Capacity, corev1.ResourceList{}, q.demand):
+			state = v1alpha1.JobExceedsCapacity
+		case !blocked && fitsCapacity(queue.Capacity, used, q.demand):
+			if err := c.admitQueuedJob(submarine, namespace, q); err != nil {
+				return err
+			}
+			addResources(used, q.demand)
+			admitted++
+			continue
+		default:
+			blocked = true
+			position++
+		}
+
+		if err := c.setQueuePosition(submarine, namespace, q, state, position); err != nil {
+			return err
+		}
+		jobPosition := position
+		if state == v1alpha1.JobExceedsCapacity {
+			jobPosition = 0
+		}
+		status.Jobs = append(status.Jobs, v1alpha1.SubmarineQueuedJob{
+			Kind:     q.job.GetKind(),
+			Name:     q.job.GetName(),
+			Priority: q.priority,
+			Position: jobPosition,
+			State:    state,
+		})
+	}
+	status.Used = used
+	status.Admitted = admitted
+	status.Queued = int32(len(status.Jobs))
+	submarine.Status.Queue = status
+	return nil
+}
+
+// setQueuePosition records the state and the position of a queued job, and
+// the Submarine holding it, in its annotations
+func (c *Controller) setQueuePosition(submarine *v1alpha1.Submarine, namespace string, q queueJob, state v1alpha1.JobQueueState, position int32) error {
+	annotations := q.job.GetAnnotations()
+	value := strconv.Itoa(int(position))
+	if state == v1alpha1.JobExceedsCapacity {
+		value = ""
+	}
+	if annotations[queueStateAnnotation] == string(state) && annotations[queuePositionAnnotation] == value && annotations[queueSubmarineAnnotation] == submarine.Name {
+		return nil
+	}
+	positionPatch := interface{}(value)
+	if value == "" {
+		positionPatch = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				queueStateAnnotation:     string(state),
+				queuePositionAnnotation:  positionPatch,
+				queueSubmarineAnnotation: submarine.Name,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(q.resource)).Namespace(namespace).Patch(context.TODO(), q.job.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// admitQueuedJob restores the schedulers of the replicas of a queued job and
+// deletes its pending pods, which the operator of the job recreates with
+// them
+func (c *Controller) admitQueuedJob(submarine *v1alpha1.Submarine, namespace string, q queueJob) error {
+	jobType := queuedJobTypes[q.resource]
+	schedulers := map[string]string{}
+	json.Unmarshal([]byte(q.job.GetAnnotations()[queueSchedulersAnnotation]), &schedulers)
+
+	specs := map[string]interface{}{}
+	for replicaType := range replicaSpecs(q.job, jobType) {
+		// null removes the scheduler, i.e. restores the default scheduler
+		var scheduler interface{}
+		if name := schedulers[replicaType]; name != "" {
+			scheduler = name
+		}
+		specs[replicaType] = map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"schedulerName": scheduler,
+				},
+			},
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				queueStateAnnotation:      string(v1alpha1.JobAdmitted),
+				queuePositionAnnotation:   nil,
+				queueSchedulersAnnotation: nil,
+				queueSubmarineAnnotation:  nil,
+			},
+		},
+		"spec": map[string]interface{}{
+			jobType.replicaSpecs: specs,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicclientset.Resource(kubeflowGroupVersion.WithResource(q.resource)).Namespace(namespace).Patch(context.TODO(), q.job.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pods, err := c.jobPods(namespace, q.job, fields.OneTermEqualSelector("spec.schedulerName", queueSchedulerName))
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if pod.Spec.SchedulerName != queueSchedulerName {
+			continue
+		}
+		if err := c.kubeclientset.CoreV1().Pods(namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	klog.Info("	Admit ", q.job.GetKind(), ": ", q.job.GetName())
+	waited := time.Since(q.job.GetCreationTimestamp().Time).Round(time.Second)
+	c.recorder.Eventf(submarine, corev1.EventTypeNormal, JobAdmittedReason, MessageJobAdmitted, q.job.GetKind(), q.job.GetName(), waited)
+	return nil
+}
+
+// jobPods returns the pods controlled by a job matching fieldSelector. They
+// are listed by the first of the jobNameLabels set on them.
+func (c *Controller) jobPods(namespace string, job *unstructured.Unstructured, fieldSelector fields.Selector) ([]corev1.Pod, error) {
+	for _, label := range jobNameLabels {
+		pods, err := c.kubeclientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{label: job.GetName()}).String(),
+			FieldSelector: fieldSelector.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(pods.Items) == 0 {
+			continue
+		}
+		var owned []corev1.Pod
+		for _, pod := range pods.Items {
+			if owner := metav1.GetControllerOf(&pod); owner != nil && owner.UID == job.GetUID() {
+				owned = append(owned, pod)
+			}
+		}
+		return owned, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,376 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newQueueTestJob returns a TFJob of workers requesting cpu each, in state
+// with priority, created ago
+func newQueueTestJob(name string, workers int64, cpu string, state v1alpha1.JobQueueState, priority string, ago time.Duration) *unstructured.Unstructured {
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"tfReplicaSpecs": map[string]interface{}{
+				"Worker": map[string]interface{}{
+					"replicas": workers,
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"schedulerName": queueSchedulerName,
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":      "tensorflow",
+									"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	job.SetAPIVersion(kubeflowGroupVersion.String())
+	job.SetKind("TFJob")
+	job.SetName(name)
+	job.SetNamespace("submarine-user-test")
+	job.SetUID(types.UID(name))
+	job.SetCreationTimestamp(metav1.Time{Time: time.Now().Add(-ago)})
+	annotations := map[string]string{}
+	if state != "" {
+		annotations[queueStateAnnotation] = string(state)
+		annotations[queueSchedulersAnnotation] = `{"Worker":""}`
+	}
+	if priority != "" {
+		annotations[queuePriorityAnnotation] = priority
+	}
+	job.SetAnnotations(annotations)
+	return job
+}
+
+func newQueueTestSubmarine(cpu string) *v1alpha1.Submarine {
+	submarine := newTestSubmarine()
+	submarine.Spec.Queue = &v1alpha1.SubmarineQueue{
+		Enabled:  true,
+		Capacity: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+	}
+	return submarine
+}
+
+func TestQueueJobDemand(t *testing.T) {
+	job := newQueueTestJob("job", 3, "500m", "", "", 0)
+	demand, err := jobDemand(job, queuedJobTypes["tfjobs"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu := demand[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("1500m")) != 0 {
+		t.Errorf("cpu = %s, want 1500m", cpu.String())
+	}
+	if !fitsCapacity(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}, demand) {
+		t.Error("expected 1500m to fit in 2 CPUs with 500m used")
+	}
+	if fitsCapacity(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}, corev1.ResourceList{}, demand) {
+		t.Error("expected 1500m not to fit in 1 CPU")
+	}
+	if !fitsCapacity(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}, corev1.ResourceList{}, demand) {
+		t.Error("expected the CPU not to be limited without a CPU capacity")
+	}
+}
+
+func TestQueueAdmitJob(t *testing.T) {
+	job := newQueueTestJob("job", 1, "1", "", "", 0)
+	job.SetAnnotations(nil)
+	unstructured.RemoveNestedField(job.Object, "spec", "tfReplicaSpecs", "Worker", "template", "spec", "schedulerName")
+	raw, err := job.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &admissionv1.AdmissionRequest{
+		Resource:  metav1.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "tfjobs"},
+		Namespace: "submarine-user-test",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+
+	// Without a queue: not patched
+	c, _, _ := newTestController(t, testControllerOptions{submarines: []*v1alpha1.Submarine{newTestSubmarine()}})
+	if patch, err := c.admitJob(request); err != nil || patch != nil {
+		t.Fatalf("expected no patch without a queue, got %v, %v", patch, err)
+	}
+
+	// With a queue: the pods go to the queue scheduler
+	c, _, _ = newTestController(t, testControllerOptions{submarines: []*v1alpha1.Submarine{newQueueTestSubmarine("4")}})
+	patch, err := c.admitJob(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 2 {
+		t.Fatalf("expected 2 operations, got %v", patch)
+	}
+	if patch[0].Path != "/spec/tfReplicaSpecs/Worker/template/spec/schedulerName" || patch[0].Value != queueSchedulerName {
+		t.Errorf("unexpected scheduler operation %v", patch[0])
+	}
+	annotations, _ := json.Marshal(patch[1].Value)
+	if want := `{"submarine.k8s.io/queue-schedulers":"{\"Worker\":\"\"}","submarine.k8s.io/queue-state":"Queued","submarine.k8s.io/queue-submarine":"example-submarine"}`; string(annotations) != want {
+		t.Errorf("annotations = %s, want %s", annotations, want)
+	}
+	if c.workqueue.Len() != 0 {
+		t.Error("expected the Submarine to be enqueued after a delay")
+	}
+}
+
+func TestQueueReconcile(t *testing.T) {
+	submarine := newQueueTestSubmarine("4")
+	running := newQueueTestJob("running", 1, "1", v1alpha1.JobAdmitted, "", 3*time.Hour)
+	// Pods of an operator labelling them with job-name
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "urgent-worker-0",
+			Namespace: "submarine-user-test",
+			Labels:    map[string]string{"job-name": "urgent"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "kubeflow.org/v1", Kind: "TFJob", Name: "urgent", UID: "urgent", Controller: func() *bool { b := true; return &b }()},
+			},
+		},
+		Spec: corev1.PodSpec{SchedulerName: queueSchedulerName},
+	}
+	scheduled := pending.DeepCopy()
+	scheduled.Name = "urgent-ps-0"
+	scheduled.Spec.SchedulerName = "default-scheduler"
+	c, kubeClient, dynamicClient := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects: []runtime.Object{
+			running,
+			// Admitted first for its priority
+			newQueueTestJob("urgent", 2, "1", v1alpha1.JobQueued, "10", time.Minute),
+			// Does not fit in the capacity left, and holds the jobs behind it
+			newQueueTestJob("large", 2, "1", v1alpha1.JobQueued, "", 2*time.Hour),
+			newQueueTestJob("small", 1, "1", v1alpha1.JobQueued, "", time.Hour),
+			// Never fits
+			newQueueTestJob("huge", 5, "1", v1alpha1.JobQueued, "", 4*time.Hour),
+			pending,
+			scheduled,
+		},
+	})
+	if err := c.reconcileQueue(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+
+	tfjobs := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test")
+	urgent, err := tfjobs.Get(context.TODO(), "urgent", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := urgent.GetAnnotations()[queueStateAnnotation]; state != string(v1alpha1.JobAdmitted) {
+		t.Errorf("state of urgent = %s, want %s", state, v1alpha1.JobAdmitted)
+	}
+	if _, found, _ := unstructured.NestedString(urgent.Object, "spec", "tfReplicaSpecs", "Worker", "template", "spec", "schedulerName"); found {
+		t.Error("expected the scheduler of urgent to be removed")
+	}
+	if pods, _ := kubeClient.CoreV1().Pods("submarine-user-test").List(context.TODO(), metav1.ListOptions{}); len(pods.Items) != 1 || pods.Items[0].Name != scheduled.Name {
+		t.Errorf("expected only the pending pod of urgent to be deleted, got %v", pods.Items)
+	}
+
+	for name, want := range map[string]string{"large": "1", "small": "2"} {
+		job, err := tfjobs.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state := job.GetAnnotations()[queueStateAnnotation]; state != string(v1alpha1.JobQueued) {
+			t.Errorf("state of %s = %s, want %s", name, state, v1alpha1.JobQueued)
+		}
+		if position := job.GetAnnotations()[queuePositionAnnotation]; position != want {
+			t.Errorf("position of %s = %s, want %s", name, position, want)
+		}
+	}
+	huge, _ := tfjobs.Get(context.TODO(), "huge", metav1.GetOptions{})
+	if state := huge.GetAnnotations()[queueStateAnnotation]; state != string(v1alpha1.JobExceedsCapacity) {
+		t.Errorf("state of huge = %s, want %s", state, v1alpha1.JobExceedsCapacity)
+	}
+
+	status := submarine.Status.Queue
+	if status == nil || status.Admitted != 2 || status.Queued != 3 {
+		t.Fatalf("unexpected queue status %+v", status)
+	}
+	if used := status.Used[corev1.ResourceCPU]; used.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("used cpu = %s, want 3", used.String())
+	}
+
+	// Disabling the queue admits every queued job
+	submarine.Spec.Queue.Enabled = false
+	if err := c.reconcileQueue(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"large", "small", "huge"} {
+		job, _ := tfjobs.Get(context.TODO(), name, metav1.GetOptions{})
+		if state := job.GetAnnotations()[queueStateAnnotation]; state != string(v1alpha1.JobAdmitted) {
+			t.Errorf("state of %s = %s, want %s", name, state, v1alpha1.JobAdmitted)
+		}
+	}
+	if submarine.Status.Queue != nil {
+		t.Error("expected no queue status once disabled")
+	}
+}
+
+// newCountedTestJob returns a job of jobType whose replicas request cpu
+func newCountedTestJob(jobType experimentJob, name string, replicas int64, cpu string, phase v1alpha1.ExperimentPhase) *unstructured.Unstructured {
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			jobType.replicaSpecs: map[string]interface{}{
+				"Worker": map[string]interface{}{
+					"replicas": replicas,
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":      "worker",
+									"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": string(phase), "status": "True"},
+			},
+		},
+	}}
+	job.SetAPIVersion(kubeflowGroupVersion.String())
+	job.SetKind(jobType.kind)
+	job.SetName(name)
+	job.SetNamespace("submarine-user-test")
+	return job
+}
+
+func TestQueueCountedJobs(t *testing.T) {
+	submarine := newQueueTestSubmarine("4")
+	c, _, dynamicClient := newTestController(t, testControllerOptions{
+		submarines: []*v1alpha1.Submarine{submarine},
+		objects: []runtime.Object{
+			// Running: uses 2 cpus of the capacity
+			newCountedTestJob(countedJobTypes["mpijobs"], "mpi", 2, "1", v1alpha1.ExperimentRunning),
+			// Finished: uses none
+			newCountedTestJob(countedJobTypes["xgboostjobs"], "xgboost", 4, "1", v1alpha1.ExperimentSucceeded),
+			newQueueTestJob("fits", 2, "1", v1alpha1.JobQueued, "", 2*time.Hour),
+			newQueueTestJob("waits", 1, "1", v1alpha1.JobQueued, "", time.Hour),
+		},
+	})
+	if err := c.reconcileQueue(submarine, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+
+	tfjobs := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test")
+	for name, want := range map[string]v1alpha1.JobQueueState{"fits": v1alpha1.JobAdmitted, "waits": v1alpha1.JobQueued} {
+		job, err := tfjobs.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state := job.GetAnnotations()[queueStateAnnotation]; state != string(want) {
+			t.Errorf("state of %s = %s, want %s", name, state, want)
+		}
+	}
+	status := submarine.Status.Queue
+	if status == nil || status.Admitted != 2 || status.Queued != 1 {
+		t.Fatalf("unexpected queue status %+v", status)
+	}
+	if used := status.Used[corev1.ResourceCPU]; used.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("used cpu = %s, want 4", used.String())
+	}
+	mpijob, err := dynamicClient.Resource(kubeflowGroupVersion.WithResource("mpijobs")).Namespace("submarine-user-test").Get(context.TODO(), "mpi", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mpijob.GetAnnotations()) != 0 {
+		t.Errorf("expected the MPIJob not to be queued, got %v", mpijob.GetAnnotations())
+	}
+}
+
+func TestQueueSharedNamespace(t *testing.T) {
+	holder := newQueueTestSubmarine("4")
+	other := newQueueTestSubmarine("4")
+	other.Name = "other-submarine"
+	unqueued := newTestSubmarine()
+	unqueued.Name = "unqueued-submarine"
+	held := newQueueTestJob("held", 1, "1", v1alpha1.JobQueued, "", time.Hour)
+	ownedByOther := newQueueTestJob("owned-by-other", 1, "1", v1alpha1.JobQueued, "", time.Hour)
+	ownedByOther.SetAnnotations(map[string]string{
+		queueStateAnnotation:      string(v1alpha1.JobQueued),
+		queueSchedulersAnnotation: `{"Worker":""}`,
+		queueSubmarineAnnotation:  other.Name,
+	})
+	c, _, dynamicClient := newTestController(t, testControllerOptions{submarines: []*v1alpha1.Submarine{holder, other, unqueued}, objects: []runtime.Object{held, ownedByOther}})
+	tfjobs := dynamicClient.Resource(kubeflowGroupVersion.WithResource("tfjobs")).Namespace("submarine-user-test")
+	state := func(name string) string {
+		job, err := tfjobs.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job.GetAnnotations()[queueStateAnnotation]
+	}
+
+	// A Submarine without a queue leaves the jobs to the enabled queues
+	if err := c.reconcileQueue(unqueued, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"held", "owned-by-other"} {
+		if got := state(name); got != string(v1alpha1.JobQueued) {
+			t.Errorf("state of %s = %s, want %s", name, got, v1alpha1.JobQueued)
+		}
+	}
+
+	// The queue only admits its own jobs
+	if err := c.reconcileQueue(holder, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := state("held"); got != string(v1alpha1.JobAdmitted) {
+		t.Errorf("state of held = %s, want %s", got, v1alpha1.JobAdmitted)
+	}
+	if got := state("owned-by-other"); got != string(v1alpha1.JobQueued) {
+		t.Errorf("state of owned-by-other = %s, want %s", got, v1alpha1.JobQueued)
+	}
+
+	// The jobs of a disabled queue are taken over
+	other.Spec.Queue.Enabled = false
+	if err := c.reconcileQueue(other, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := state("owned-by-other"); got != string(v1alpha1.JobQueued) {
+		t.Errorf("state of owned-by-other = %s, want %s", got, v1alpha1.JobQueued)
+	}
+	if err := c.reconcileQueue(holder, "submarine-user-test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := state("owned-by-other"); got != string(v1alpha1.JobAdmitted) {
+		t.Errorf("state of owned-by-other = %s, want %s", got, v1alpha1.JobAdmitted)
+	}
+}
@@ -0,0 +1,244 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"submarine-cloud-v2/pkg/helm"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	traefikscheme "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/generated/clientset/versioned/scheme"
+)
+
+// renderScheme knows every type of object the operator creates, so that
+// rendered objects carry their apiVersion and kind.
+var renderScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(renderScheme))
+	utilruntime.Must(traefikscheme.AddToScheme(renderScheme))
+}
+
+// runRender implements "submarine-operator render". It prints every manifest
+// the operator would create for a Submarine without contacting a cluster.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	filename := fs.String("f", "", "Path to the Submarine resource to render, or - for stdin")
+	namespace := fs.String("n", "default", "Namespace of the Submarine if the resource does not specify one")
+	chartsDir := fs.String("charts", "", "Directory containing the subcharts, instead of the charts embedded in the binary")
+	overrides := chartOverrides{}
+	fs.Var(overrides, "chart", "Source of a subchart as NAME=SOURCE, i.e. a directory or REPO_URL/CHART[@VERSION], can be repeated")
+	fs.Parse(args)
+
+	if *filename == "" {
+		fs.Usage()
+		return fmt.Errorf("missing -f")
+	}
+	sources := localChartOverrides(*chartsDir)
+	for name, source := range overrides {
+		sources[name] = source
+	}
+
+	submarine, err := readSubmarine(*filename)
+	if err != nil {
+		return err
+	}
+	if submarine.Namespace == "" {
+		submarine.Namespace = *namespace
+	}
+
+	return renderSubmarine(submarine, sources, os.Stdout)
+}
+
+// readSubmarine reads a Submarine resource from a YAML or JSON file
+func readSubmarine(filename string) (*v1alpha1.Submarine, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	submarine := &v1alpha1.Submarine{}
+	if err := yaml.UnmarshalStrict(data, submarine); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", filename, err)
+	}
+	if submarine.Kind != "Submarine" {
+		return nil, fmt.Errorf("%s: expected kind Submarine, got %q", filename, submarine.Kind)
+	}
+	return submarine, nil
+}
+
+// renderSubmarine writes the objects created by the controller for a
+// Submarine, followed by the manifests of the subcharts, as a multi-document
+// YAML stream. The subcharts are loaded from sources, or else embedded.
+func renderSubmarine(submarine *v1alpha1.Submarine, sources chartOverrides, out io.Writer) error {
+	for _, obj := range newSubmarineObjects(submarine) {
+		if err := setRenderedMetadata(obj, submarine.Namespace); err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "---\n%s", data)
+	}
+
+	for _, name := range subChartsOf(submarine) {
+		manifest, err := helm.TemplateChart(subChartSource(name, sources), name, submarine.Namespace, subChartValues(submarine, name))
+		if err != nil {
+			return fmt.Errorf("error rendering chart %s: %v", name, err)
+		}
+		// Each manifest of a chart starts with a document separator
+		fmt.Fprintf(out, "%s\n", strings.TrimSpace(manifest))
+	}
+
+	return nil
+}
+
+// newSubmarineObjects returns the desired state of every object that
+// syncHandler creates for a Submarine, in the same order. Which optional
+// objects exist is decided by the same functions as in syncHandler, e.g.
+// storageEnabled or podDisruptionBudgetEnabled.
+func newSubmarineObjects(submarine *v1alpha1.Submarine) []runtime.Object {
+	namespace := submarine.Namespace
+	var objects []runtime.Object
+
+	// submarine-server
+	objects = append(objects,
+		newSubmarineServerServiceAccount(submarine),
+		newSubmarineServerService(submarine),
+	)
+	if serverConfigEnabled(submarine) {
+		objects = append(objects, newSubmarineServerConfigMap(submarine))
+	}
+	if monitoringEnabled(submarine) {
+		objects = append(objects, newSubmarineServerMetricsConfigMap(submarine))
+	}
+	objects = append(objects, newSubmarineServerDeployment(submarine))
+	if serverAutoscalingEnabled(submarine) {
+		objects = append(objects, newSubmarineServerHPA(submarine))
+	}
+
+	// submarine-database
+	if storageEnabled(submarine) {
+		databasePV := newSubmarinePersistentVolume(submarine, "database", clusterScopedName(databaseName(submarine)+"-pv", namespace), submarine.Spec.Database.StorageSize)
+		databasePVCName := databaseName(submarine) + "-pvc"
+		objects = append(objects,
+			databasePV,
+			newSubmarinePersistentVolumeClaim(submarine, "database", databasePVCName, databasePV.Name, submarine.Spec.Database.StorageSize),
+			newSubmarineDatabaseDeployment(submarine, databasePVCName),
+			newSubmarineDatabaseService(submarine),
+		)
+	}
+
+	// PodDisruptionBudgets
+	if replicas := serverMinReplicas(submarine); podDisruptionBudgetEnabled(replicas) {
+		objects = append(objects, newSubmarinePodDisruptionBudget(submarine, "server", serverName(submarine)+"-pdb", map[string]string{"run": serverName(submarine)}, replicas))
+	}
+	if replicas := databaseReplicas(submarine); storageEnabled(submarine) && podDisruptionBudgetEnabled(replicas) {
+		objects = append(objects, newSubmarinePodDisruptionBudget(submarine, "database", databaseName(submarine)+"-pdb", map[string]string{"app": databaseName(submarine)}, replicas))
+	}
+
+	// Ingress and RBAC
+	objects = append(objects,
+		newSubmarineServerIngress(submarine, namespace),
+		newSubmarineServerClusterRole(submarine),
+		newSubmarineServerClusterRoleBinding(submarine, namespace),
+	)
+
+	// submarine-tensorboard
+	if storageEnabled(submarine) {
+		tensorboardPV := newSubmarinePersistentVolume(submarine, "tensorboard", clusterScopedName(tensorboardName(submarine)+"-pv", namespace), submarine.Spec.Tensorboard.StorageSize)
+		tensorboardPVCName := tensorboardName(submarine) + "-pvc"
+		objects = append(objects,
+			tensorboardPV,
+			newSubmarinePersistentVolumeClaim(submarine, "tensorboard", tensorboardPVCName, tensorboardPV.Name, submarine.Spec.Tensorboard.StorageSize),
+			newSubmarineTensorboardDeployment(submarine, tensorboardPVCName),
+			newSubmarineTensorboardService(submarine),
+			newSubmarineTensorboardIngressRoute(submarine),
+		)
+	}
+
+	// ServiceMonitors and PodMonitor, created if the Prometheus Operator is
+	// installed
+	if monitoringEnabled(submarine) {
+		monitors := newMonitors(submarine)
+		for _, resource := range []schema.GroupVersionResource{serviceMonitorResource, podMonitorResource} {
+			for _, monitor := range monitors[resource] {
+				objects = append(objects, monitor)
+			}
+		}
+	}
+
+	// Image pre-puller, without the images of the environments of the
+	// server, which are only known once it runs
+	if prePullEnabled(submarine) {
+		objects = append(objects, newPrePullDaemonSet(submarine, uniqueImages(submarine.Spec.PrePull.Images)))
+	}
+
+	// Receiver of the logs archived into a volume
+	if logArchiverEnabled(submarine) {
+		objects = append(objects, newLogArchiverDeployment(submarine))
+	}
+
+	return objects
+}
+
+// setRenderedMetadata fills in the fields that the API server would
+// otherwise derive from the request, and drops the ownerReferences: they
+// point to the Submarine by its UID, which only exists once it is created.
+func setRenderedMetadata(obj runtime.Object, namespace string) error {
+	gvks, _, err := renderScheme.ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	accessor.SetOwnerReferences(nil)
+	switch obj.(type) {
+	case *corev1.PersistentVolume, *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
+		// Cluster-scoped objects have no namespace
+		return nil
+	}
+	accessor.SetNamespace(namespace)
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "Update the golden files in testdata/render")
+
+// TestRender compares the output of "submarine-operator render" for each
+// Submarine in testdata/render with its golden file. Run
+// "go test -run TestRender -update" to regenerate the golden files.
+func TestRender(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/render/*.input.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no inputs in testdata/render")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".input.yaml")
+		t.Run(name, func(t *testing.T) {
+			submarine, err := readSubmarine(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if submarine.Namespace == "" {
+				submarine.Namespace = "submarine-user-test"
+			}
+
+			var out bytes.Buffer
+			if err := renderSubmarine(submarine, nil, &out); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata/render", name+".golden.yaml")
+			if *updateGolden {
+				if err := ioutil.WriteFile(golden, out.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), expected) {
+				t.Errorf("rendered manifests differ from %s; run with -update to regenerate", golden)
+			}
+		})
+	}
+}
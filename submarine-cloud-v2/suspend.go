@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// suspendedReplicasAnnotation records the replicas of a deployment
+	// before it was scaled to zero. It is written together with the new
+	// replicas, so that they are not lost if the status update fails.
+	suspendedReplicasAnnotation = "submarine.k8s.io/suspended-replicas"
+)
+
+// Reasons of the Suspended condition and of the suspension events
+const (
+	SubmarineSuspendedReason = "Suspended"
+	SubmarineResumedReason   = "Resumed"
+)
+
+// suspendedDeployments returns the names of the deployments a suspended
+// Submarine scales to zero. There is no mlflow Deployment: the operator does
+// not create one.
+func suspendedDeployments(submarine *v1alpha1.Submarine) []string {
+	names := []string{serverName(submarine), tensorboardName(submarine)}
+	if submarine.Spec.SuspendDatabase {
+		names = append(names, databaseName(submarine))
+	}
+	return names
+}
+
+// reconcileSuspension scales the deployments of a suspended Submarine to
+// zero, and restores their replicas once spec.suspend is unset. submarine
+// must be a copy, its status is updated. It returns whether the rest of the
+// reconcile is skipped: while the Submarine is suspended, and right after
+// it is resumed, so that the next reconcile sees the restored replicas
+// rather than the stale ones of the listers.
+func (c *Controller) reconcileSuspension(submarine *v1alpha1.Submarine) (bool, error) {
+	klog.Info("[reconcileSuspension]")
+
+	suspension := submarine.Status.Suspension
+
+	if !submarine.Spec.Suspend {
+		if suspension == nil || !suspension.Suspended {
+			return false, nil
+		}
+		for name := range suspension.Replicas {
+			if err := c.resumeDeployment(submarine, name); err != nil {
+				return false, err
+			}
+		}
+		now := metav1.Now()
+		suspension.Suspended = false
+		suspension.Replicas = nil
+		suspension.ResumeTime = &now
+		message := "The components are scaled back to their replicas"
+		setSuspendedCondition(submarine, metav1.ConditionFalse, SubmarineResumedReason, message)
+		c.recorder.Event(submarine, corev1.EventTypeNormal, SubmarineResumedReason, message)
+		return true, nil
+	}
+
+	if suspension == nil || !suspension.Suspended {
+		now := metav1.Now()
+		suspension = &v1alpha1.SubmarineSuspensionStatus{
+			Suspended:   true,
+			SuspendTime: &now,
+		}
+		submarine.Status.Suspension = suspension
+		c.recorder.Event(submarine, corev1.EventTypeNormal, SubmarineSuspendedReason, "Scaling the components to zero")
+	}
+	if suspension.Replicas == nil {
+		suspension.Replicas = map[string]int32{}
+	}
+
+	suspended := map[string]bool{}
+	for _, name := range suspendedDeployments(submarine) {
+		suspended[name] = true
+		replicas, found, err := c.suspendDeployment(submarine, name)
+		if err != nil {
+			return true, err
+		}
+		if found {
+			suspension.Replicas[name] = replicas
+		}
+	}
+
+	// Resume the deployments that are no longer suspended, i.e. the database
+	// once spec.suspendDatabase is unset
+	for name := range suspension.Replicas {
+		if !suspended[name] {
+			if err := c.resumeDeployment(submarine, name); err != nil {
+				return true, err
+			}
+			delete(suspension.Replicas, name)
+		}
+	}
+
+	setSuspendedCondition(submarine, metav1.ConditionTrue, SubmarineSuspendedReason, fmt.Sprintf("Suspended since %s", suspension.SuspendTime.UTC().Format("2006-01-02T15:04:05Z")))
+	return true, nil
+}
+
+// suspendDeployment scales a deployment to zero and returns its replicas
+// before the suspension. Deployments that do not exist are skipped.
+func (c *Controller) suspendDeployment(submarine *v1alpha1.Submarine, name string) (int32, bool, error) {
+	deployment, deployment_err := c.deploymentLister.Deployments(submarine.Namespace).Get(name)
+	if errors.IsNotFound(deployment_err) {
+		return 0, false, nil
+	}
+	if deployment_err != nil {
+		return 0, false, deployment_err
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	// A deployment scaled up while suspended keeps the replicas recorded
+	// when it was suspended
+	if value, ok := deployment.Annotations[suspendedReplicasAnnotation]; ok {
+		if parsed, err := strconv.ParseInt(value, 10, 32); err == nil {
+			if replicas == 0 {
+				return int32(parsed), true, nil
+			}
+			replicas = int32(parsed)
+		}
+	}
+	deploymentCopy := deployment.DeepCopy()
+	if deploymentCopy.Annotations == nil {
+		deploymentCopy.Annotations = map[string]string{}
+	}
+	deploymentCopy.Annotations[suspendedReplicasAnnotation] = strconv.Itoa(int(replicas))
+	zero := int32(0)
+	deploymentCopy.Spec.Replicas = &zero
+	klog.Info("	Suspend Deployment: ", name)
+	_, deployment_err = c.kubeclientset.AppsV1().Deployments(submarine.Namespace).Update(context.TODO(), deploymentCopy, metav1.UpdateOptions{})
+	return replicas, true, deployment_err
+}
+
+// resumeDeployment scales a deployment back to its replicas before the
+// suspension
+func (c *Controller) resumeDeployment(submarine *v1alpha1.Submarine, name string) error {
+	deployment, deployment_err := c.deploymentLister.Deployments(submarine.Namespace).Get(name)
+	if errors.IsNotFound(deployment_err) {
+		return nil
+	}
+	if deployment_err != nil {
+		return deployment_err
+	}
+
+	replicas := submarine.Status.Suspension.Replicas[name]
+	if value, ok := deployment.Annotations[suspendedReplicasAnnotation]; ok {
+		if parsed, err := strconv.ParseInt(value, 10, 32); err == nil {
+			replicas = int32(parsed)
+		}
+	} else if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas != 0 {
+		// Already resumed
+		return nil
+	}
+
+	deploymentCopy := deployment.DeepCopy()
+	delete(deploymentCopy.Annotations, suspendedReplicasAnnotation)
+	deploymentCopy.Spec.Replicas = &replicas
+	klog.Info("	Resume Deployment: ", name)
+	_, deployment_err = c.kubeclientset.AppsV1().Deployments(submarine.Namespace).Update(context.TODO(), deploymentCopy, metav1.UpdateOptions{})
+	return deployment_err
+}
+
+func setSuspendedCondition(submarine *v1alpha1.Submarine, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&submarine.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SubmarineSuspended,
+		Status:             status,
+		ObservedGeneration: submarine.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newSuspendTestDeployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "submarine-user-test"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func getReplicas(t *testing.T, kubeClient *kubefake.Clientset, name string) int32 {
+	t.Helper()
+	deployment, err := kubeClient.AppsV1().Deployments("submarine-user-test").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *deployment.Spec.Replicas
+}
+
+func TestReconcileSuspension(t *testing.T) {
+	submarine := newTestSubmarine()
+	submarine.Spec.Suspend = true
+
+	// Suspend: the server and the tensorboard are scaled to zero, the
+	// database keeps running
+	c, kubeClient, _ := newTestController(t, testControllerOptions{objects: []runtime.Object{newSuspendTestDeployment("example-submarine-server", 3), newSuspendTestDeployment("example-submarine-tensorboard", 1), newSuspendTestDeployment("example-submarine-database", 1)}})
+	paused, err := c.reconcileSuspension(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused {
+		t.Errorf("a suspended Submarine should not be reconciled")
+	}
+	if replicas := getReplicas(t, kubeClient, "example-submarine-server"); replicas != 0 {
+		t.Errorf("server replicas = %d, expected 0", replicas)
+	}
+	if replicas := getReplicas(t, kubeClient, "example-submarine-database"); replicas != 1 {
+		t.Errorf("database replicas = %d, expected 1", replicas)
+	}
+	suspension := submarine.Status.Suspension
+	if suspension == nil || !suspension.Suspended || suspension.SuspendTime == nil || suspension.Replicas["example-submarine-server"] != 3 {
+		t.Fatalf("expected the suspension to record 3 server replicas, got %+v", suspension)
+	}
+	if !meta.IsStatusConditionTrue(submarine.Status.Conditions, v1alpha1.SubmarineSuspended) {
+		t.Errorf("expected the Suspended condition to be true")
+	}
+
+	// Suspending the database as well
+	server, _ := kubeClient.AppsV1().Deployments("submarine-user-test").Get(context.TODO(), "example-submarine-server", metav1.GetOptions{})
+	submarine.Spec.SuspendDatabase = true
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{server, newSuspendTestDeployment("example-submarine-database", 1)}})
+	if _, err := c.reconcileSuspension(submarine); err != nil {
+		t.Fatal(err)
+	}
+	if replicas := getReplicas(t, kubeClient, "example-submarine-database"); replicas != 0 {
+		t.Errorf("database replicas = %d, expected 0", replicas)
+	}
+	if suspension.Replicas["example-submarine-server"] != 3 {
+		t.Errorf("the server replicas should be kept on later reconciles, got %+v", suspension.Replicas)
+	}
+
+	// Resume: the replicas before the suspension are restored
+	database, _ := kubeClient.AppsV1().Deployments("submarine-user-test").Get(context.TODO(), "example-submarine-database", metav1.GetOptions{})
+	submarine.Spec.Suspend = false
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{server, database}})
+	if _, err := c.reconcileSuspension(submarine); err != nil {
+		t.Fatal(err)
+	}
+	if replicas := getReplicas(t, kubeClient, "example-submarine-server"); replicas != 3 {
+		t.Errorf("server replicas = %d, expected 3", replicas)
+	}
+	if replicas := getReplicas(t, kubeClient, "example-submarine-database"); replicas != 1 {
+		t.Errorf("database replicas = %d, expected 1", replicas)
+	}
+	if suspension.Suspended || suspension.ResumeTime == nil || suspension.Replicas != nil {
+		t.Errorf("expected the suspension to be over, got %+v", suspension)
+	}
+
+	// Once resumed, the Submarine is reconciled again
+	if paused, err := c.reconcileSuspension(submarine); err != nil || paused {
+		t.Errorf("a resumed Submarine should be reconciled, got %v, %v", paused, err)
+	}
+}
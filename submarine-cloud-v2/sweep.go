@@ -0,0 +1,606 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	clientset "submarine-cloud-v2/pkg/generated/clientset/versioned"
+	submarinescheme "submarine-cloud-v2/pkg/generated/clientset/versioned/scheme"
+	informers "submarine-cloud-v2/pkg/generated/informers/externalversions/submarine/v1alpha1"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const sweepControllerAgentName = "submarine-sweep-controller"
+
+// sweepLogTailLines is how many lines of the logs of a trial are searched for
+// the objective metric
+const sweepLogTailLines = int64(1000)
+
+// Reasons of the events and of the Complete condition of a SubmarineSweep
+const (
+	TrialsRunningReason  = "TrialsRunning"
+	TrialsFinishedReason = "TrialsFinished"
+	// TrialCreated is used as part of the Event 'reason' when the
+	// SubmarineExperiment of a trial is created
+	TrialCreated = "TrialCreated"
+	// MessageTrialCreated is the message used for an Event fired when the
+	// SubmarineExperiment of a trial is created
+	MessageTrialCreated = "Created trial %s with %v"
+	// ObjectiveNotFound is used as part of the Event 'reason' when the
+	// objective metric of a succeeded trial cannot be read
+	ObjectiveNotFound = "ObjectiveNotFound"
+	// MessageObjectiveNotFound is the message used for an Event fired when
+	// the objective metric of a succeeded trial cannot be read
+	MessageObjectiveNotFound = "No %s in trial %s: %v"
+)
+
+// SweepController reconciles SubmarineSweeps into one SubmarineExperiment
+// per trial
+type SweepController struct {
+	kubeclientset      kubernetes.Interface
+	submarineclientset clientset.Interface
+
+	sweepsLister      listers.SubmarineSweepLister
+	sweepsSynced      cache.InformerSynced
+	experimentsLister listers.SubmarineExperimentLister
+	experimentsSynced cache.InformerSynced
+	podLister         corelisters.PodLister
+	// workqueue holds the keys of the SubmarineSweeps to reconcile
+	workqueue workqueue.RateLimitingInterface
+	recorder  record.EventRecorder
+}
+
+// NewSweepController returns a new SubmarineSweep controller
+func NewSweepController(
+	kubeclientset kubernetes.Interface,
+	submarineclientset clientset.Interface,
+	podInformer coreinformers.PodInformer,
+	experimentInformer informers.SubmarineExperimentInformer,
+	sweepInformer informers.SubmarineSweepInformer) *SweepController {
+
+	utilruntime.Must(submarinescheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: sweepControllerAgentName})
+
+	controller := &SweepController{
+		kubeclientset:      kubeclientset,
+		submarineclientset: submarineclientset,
+		sweepsLister:       sweepInformer.Lister(),
+		sweepsSynced:       sweepInformer.Informer().HasSynced,
+		experimentsLister:  experimentInformer.Lister(),
+		experimentsSynced:  experimentInformer.Informer().HasSynced,
+		podLister:          podInformer.Lister(),
+		workqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineSweeps"),
+		recorder:           recorder,
+	}
+
+	klog.Info("Setting up SubmarineSweep event handlers")
+	sweepInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueSweep,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueSweep(new)
+		},
+	})
+	experimentInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			if new.(*v1alpha1.SubmarineExperiment).ResourceVersion == old.(*v1alpha1.SubmarineExperiment).ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+
+	return controller
+}
+
+// Run waits for the caches to sync and processes SubmarineSweeps until
+// stopCh is closed
+func (c *SweepController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Info("Starting SubmarineSweep controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.sweepsSynced, c.experimentsSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down SubmarineSweep workers")
+
+	return nil
+}
+
+func (c *SweepController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *SweepController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(key); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(key)
+		utilruntime.HandleError(fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.Infof("Successfully synced '%s'", key)
+	return true
+}
+
+func (c *SweepController) enqueueSweep(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// handleObject enqueues the SubmarineSweep that controls obj
+func (c *SweepController) handleObject(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+	ownerRef := metav1.GetControllerOf(object)
+	if ownerRef == nil || ownerRef.Kind != "SubmarineSweep" {
+		return
+	}
+	sweep, err := c.sweepsLister.SubmarineSweeps(object.GetNamespace()).Get(ownerRef.Name)
+	if err != nil {
+		return
+	}
+	c.enqueueSweep(sweep)
+}
+
+// syncHandler reconciles the SubmarineSweep of key
+func (c *SweepController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	sweep, err := c.sweepsLister.SubmarineSweeps(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sweep = sweep.DeepCopy()
+	klog.Info("[syncSweep] ", key)
+
+	if err := c.reconcileTrials(sweep); err != nil {
+		return err
+	}
+	return c.updateSweepStatus(sweep)
+}
+
+// sweepTrials returns the parameter sets of the trials of a sweep. They only
+// depend on the spec and the UID of the sweep, so they are the same on every
+// reconcile.
+func sweepTrials(sweep *v1alpha1.SubmarineSweep) ([]map[string]string, error) {
+	maxTrials := -1
+	if sweep.Spec.MaxTrials != nil {
+		maxTrials = int(*sweep.Spec.MaxTrials)
+	}
+
+	var trials []map[string]string
+	switch sweep.Spec.Algorithm {
+	case v1alpha1.SweepList:
+		trials = sweep.Spec.Trials
+	case v1alpha1.SweepGrid:
+		trials = []map[string]string{{}}
+		for _, parameter := range sweep.Spec.Parameters {
+			values, err := gridValues(parameter)
+			if err != nil {
+				return nil, err
+			}
+			// The last parameter varies the fastest
+			var product []map[string]string
+			for _, trial := range trials {
+				for _, value := range values {
+					next := map[string]string{parameter.Name: value}
+					for name, value := range trial {
+						next[name] = value
+					}
+					product = append(product, next)
+				}
+			}
+			trials = product
+		}
+	case v1alpha1.SweepRandom:
+		if maxTrials < 0 {
+			return nil, fmt.Errorf("the Random algorithm requires maxTrials")
+		}
+		hash := fnv.New64a()
+		hash.Write([]byte(sweep.UID))
+		seed := int64(hash.Sum64())
+		for i := 0; i < maxTrials; i++ {
+			rng := rand.New(rand.NewSource(seed + int64(i)))
+			trial := map[string]string{}
+			for _, parameter := range sweep.Spec.Parameters {
+				value, err := randomValue(parameter, rng)
+				if err != nil {
+					return nil, err
+				}
+				trial[parameter.Name] = value
+			}
+			trials = append(trials, trial)
+		}
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", sweep.Spec.Algorithm)
+	}
+
+	if maxTrials >= 0 && len(trials) > maxTrials {
+		trials = trials[:maxTrials]
+	}
+	return trials, nil
+}
+
+// parameterRange parses the range of a parameter. It is integer if both
+// bounds are.
+func parameterRange(parameter v1alpha1.SweepParameter) (float64, float64, bool, error) {
+	min, err := strconv.ParseFloat(parameter.Min, 64)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid min of parameter %s: %v", parameter.Name, err)
+	}
+	max, err := strconv.ParseFloat(parameter.Max, 64)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid max of parameter %s: %v", parameter.Name, err)
+	}
+	if max < min {
+		return 0, 0, false, fmt.Errorf("max of parameter %s is less than min", parameter.Name)
+	}
+	_, minErr := strconv.Atoi(parameter.Min)
+	_, maxErr := strconv.Atoi(parameter.Max)
+	return min, max, minErr == nil && maxErr == nil, nil
+}
+
+func formatParameter(value float64, integer bool) string {
+	if integer {
+		return strconv.FormatInt(int64(math.Round(value)), 10)
+	}
+	// 10 digits hide the rounding errors of the steps, e.g. 0.30000000000000004
+	return strconv.FormatFloat(value, 'g', 10, 64)
+}
+
+// gridValues returns the values of a parameter, or its range divided by step
+func gridValues(parameter v1alpha1.SweepParameter) ([]string, error) {
+	if len(parameter.Values) != 0 {
+		return parameter.Values, nil
+	}
+	min, max, integer, err := parameterRange(parameter)
+	if err != nil {
+		return nil, err
+	}
+	step, err := strconv.ParseFloat(parameter.Step, 64)
+	if err != nil || step <= 0 {
+		return nil, fmt.Errorf("the Grid algorithm requires a positive step for parameter %s", parameter.Name)
+	}
+	var values []string
+	for i := 0; min+float64(i)*step <= max+step*1e-9; i++ {
+		values = append(values, formatParameter(min+float64(i)*step, integer))
+	}
+	return values, nil
+}
+
+// randomValue returns one of the values of a parameter, or a uniform value
+// of its range
+func randomValue(parameter v1alpha1.SweepParameter, rng *rand.Rand) (string, error) {
+	if len(parameter.Values) != 0 {
+		return parameter.Values[rng.Intn(len(parameter.Values))], nil
+	}
+	min, max, integer, err := parameterRange(parameter)
+	if err != nil {
+		return "", err
+	}
+	if integer {
+		return formatParameter(min+float64(rng.Int63n(int64(max-min)+1)), true), nil
+	}
+	return formatParameter(min+rng.Float64()*(max-min), false), nil
+}
+
+func sweepTrialName(sweep *v1alpha1.SubmarineSweep, index int) string {
+	return fmt.Sprintf("%s-%d", sweep.Name, index)
+}
+
+// newTrialExperiment returns the SubmarineExperiment of a trial, i.e. the
+// template with ${name} replaced by the value of the parameter name
+func newTrialExperiment(sweep *v1alpha1.SubmarineSweep, index int, parameters map[string]string) *v1alpha1.SubmarineExperiment {
+	var replacements []string
+	for name, value := range parameters {
+		replacements = append(replacements, "${"+name+"}", value)
+	}
+	replacer := strings.NewReplacer(replacements...)
+	replaceCommand := func(command []string) {
+		for i := range command {
+			command[i] = replacer.Replace(command[i])
+		}
+	}
+	replaceEnv := func(env []corev1.EnvVar) {
+		for i := range env {
+			env[i].Value = replacer.Replace(env[i].Value)
+		}
+	}
+
+	spec := *sweep.Spec.Template.DeepCopy()
+	replaceCommand(spec.Command)
+	replaceEnv(spec.Env)
+	for replicaType, replica := range spec.Replicas {
+		replaceCommand(replica.Command)
+		replaceEnv(replica.Env)
+		spec.Replicas[replicaType] = replica
+	}
+	if sweep.Spec.Objective.File != "" {
+		spec.TerminationMessagePath = sweep.Spec.Objective.File
+	}
+
+	return &v1alpha1.SubmarineExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sweepTrialName(sweep, index),
+			Namespace: sweep.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of":    "submarine",
+				"app.kubernetes.io/managed-by": "submarine-operator",
+				"submarine.k8s.io/sweep":       sweep.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(sweep, v1alpha1.SchemeGroupVersion.WithKind("SubmarineSweep")),
+			},
+		},
+		Spec: spec,
+	}
+}
+
+// objectivePattern returns the regular expression matching the value of the
+// objective metric
+func objectivePattern(objective v1alpha1.SweepObjective) (*regexp.Regexp, error) {
+	if objective.LogPattern != "" {
+		return regexp.Compile(objective.LogPattern)
+	}
+	return regexp.Compile(regexp.QuoteMeta(objective.Metric) + `\s*[=:]\s*([-+]?[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)`)
+}
+
+// parseObjective returns the value of the last match of pattern in text
+func parseObjective(pattern *regexp.Regexp, text string) (string, bool) {
+	matches := pattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 || len(matches[len(matches)-1]) < 2 {
+		return "", false
+	}
+	return matches[len(matches)-1][1], true
+}
+
+// readObjective reads the objective metric of a trial from the termination
+// messages or the logs of its pods, in the order of the status of the
+// experiment
+func (c *SweepController) readObjective(sweep *v1alpha1.SubmarineSweep, experiment *v1alpha1.SubmarineExperiment) (string, error) {
+	pattern, err := objectivePattern(sweep.Spec.Objective)
+	if err != nil {
+		return "", err
+	}
+	for _, podStatus := range experiment.Status.Pods {
+		var text string
+		if sweep.Spec.Objective.File != "" {
+			pod, err := c.podLister.Pods(experiment.Namespace).Get(podStatus.Name)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			for _, container := range pod.Status.ContainerStatuses {
+				if container.State.Terminated != nil {
+					text += container.State.Terminated.Message + "\n"
+				}
+			}
+		} else {
+			tailLines := sweepLogTailLines
+			logs, err := c.kubeclientset.CoreV1().Pods(experiment.Namespace).GetLogs(podStatus.Name, &corev1.PodLogOptions{TailLines: &tailLines}).DoRaw(context.TODO())
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			text = string(logs)
+		}
+		if value, ok := parseObjective(pattern, text); ok {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("no match of %s", pattern)
+}
+
+// betterObjective tells whether value is better than best
+func betterObjective(goal v1alpha1.SweepGoal, value string, best string) bool {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	b, err := strconv.ParseFloat(best, 64)
+	if err != nil {
+		return true
+	}
+	if goal == v1alpha1.SweepMinimize {
+		return v < b
+	}
+	return v > b
+}
+
+// reconcileTrials creates the experiments of the trials in order, keeping at
+// most spec.parallelism of them unfinished, and records their states, their
+// objectives and the best trial
+func (c *SweepController) reconcileTrials(sweep *v1alpha1.SubmarineSweep) error {
+	trials, err := sweepTrials(sweep)
+	if err != nil {
+		c.setSweepComplete(sweep, metav1.ConditionFalse, InvalidSpecReason, err.Error())
+		return nil
+	}
+	parallelism := int32(1)
+	if sweep.Spec.Parallelism != nil {
+		parallelism = *sweep.Spec.Parallelism
+	}
+	// Objectives are read once, when the trial succeeds
+	previous := map[string]v1alpha1.SweepTrialStatus{}
+	for _, trial := range sweep.Status.Trials {
+		previous[trial.Name] = trial
+	}
+
+	status := &sweep.Status
+	status.Trials = nil
+	status.TotalTrials = int32(len(trials))
+	status.Active, status.Succeeded, status.Failed = 0, 0, 0
+	status.BestTrial = nil
+	for i, parameters := range trials {
+		name := sweepTrialName(sweep, i)
+		experiment, experiment_err := c.experimentsLister.SubmarineExperiments(sweep.Namespace).Get(name)
+		// If the resource doesn't exist, we'll create it
+		if errors.IsNotFound(experiment_err) {
+			if status.Active >= parallelism {
+				continue
+			}
+			experiment, experiment_err = c.submarineclientset.SubmarineV1alpha1().SubmarineExperiments(sweep.Namespace).Create(context.TODO(), newTrialExperiment(sweep, i, parameters), metav1.CreateOptions{})
+			if experiment_err != nil {
+				klog.Info(experiment_err)
+			} else {
+				c.recorder.Eventf(sweep, corev1.EventTypeNormal, TrialCreated, MessageTrialCreated, name, parameters)
+			}
+			klog.Info("	Create SubmarineExperiment: ", name)
+		}
+		if experiment_err != nil {
+			return experiment_err
+		}
+		if !metav1.IsControlledBy(experiment, sweep) {
+			msg := fmt.Sprintf(MessageResourceExists, name)
+			c.recorder.Event(sweep, corev1.EventTypeWarning, ErrResourceExists, msg)
+			return fmt.Errorf(msg)
+		}
+
+		trial := v1alpha1.SweepTrialStatus{
+			Name:       name,
+			Parameters: parameters,
+			Phase:      experiment.Status.Phase,
+		}
+		switch trial.Phase {
+		case v1alpha1.ExperimentSucceeded:
+			status.Succeeded++
+			if previous[name].Phase == v1alpha1.ExperimentSucceeded {
+				trial.Objective = previous[name].Objective
+			} else if trial.Objective, err = c.readObjective(sweep, experiment); err != nil {
+				c.recorder.Eventf(sweep, corev1.EventTypeWarning, ObjectiveNotFound, MessageObjectiveNotFound, sweep.Spec.Objective.Metric, name, err)
+			}
+			if trial.Objective != "" && (status.BestTrial == nil || betterObjective(sweep.Spec.Objective.Goal, trial.Objective, status.BestTrial.Objective)) {
+				best := trial
+				status.BestTrial = &best
+			}
+		case v1alpha1.ExperimentFailed:
+			status.Failed++
+		default:
+			status.Active++
+		}
+		status.Trials = append(status.Trials, trial)
+	}
+
+	finished := status.Succeeded + status.Failed
+	if finished < status.TotalTrials {
+		status.CompletionTime = nil
+		c.setSweepComplete(sweep, metav1.ConditionFalse, TrialsRunningReason, fmt.Sprintf("%d/%d trials finished", finished, status.TotalTrials))
+		return nil
+	}
+	if status.CompletionTime == nil {
+		status.CompletionTime = &metav1.Time{Time: time.Now()}
+	}
+	c.setSweepComplete(sweep, metav1.ConditionTrue, TrialsFinishedReason, fmt.Sprintf("%d trials succeeded, %d failed", status.Succeeded, status.Failed))
+	return nil
+}
+
+func (c *SweepController) setSweepComplete(sweep *v1alpha1.SubmarineSweep, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&sweep.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SubmarineSweepComplete,
+		Status:             status,
+		ObservedGeneration: sweep.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateSweepStatus writes the status of a SubmarineSweep if it changed
+func (c *SweepController) updateSweepStatus(sweep *v1alpha1.SubmarineSweep) error {
+	current, err := c.sweepsLister.SubmarineSweeps(sweep.Namespace).Get(sweep.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(current.Status, sweep.Status) {
+		return nil
+	}
+	_, err = c.submarineclientset.SubmarineV1alpha1().SubmarineSweeps(sweep.Namespace).Update(context.TODO(), sweep, metav1.UpdateOptions{})
+	return err
+}
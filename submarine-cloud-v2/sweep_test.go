@@ -0,0 +1,214 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	submarinefake "submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// newSweepTestController returns a controller whose listers hold objs
+func newSweepTestController(objs ...metav1.Object) (*SweepController, *submarinefake.Clientset) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		indexer.Add(obj)
+	}
+	submarineClient := submarinefake.NewSimpleClientset()
+	return &SweepController{
+		kubeclientset:      kubefake.NewSimpleClientset(),
+		submarineclientset: submarineClient,
+		sweepsLister:       listers.NewSubmarineSweepLister(indexer),
+		experimentsLister:  listers.NewSubmarineExperimentLister(indexer),
+		podLister:          corelisters.NewPodLister(indexer),
+		recorder:           record.NewFakeRecorder(100),
+	}, submarineClient
+}
+
+func newTestSweep(algorithm v1alpha1.SweepAlgorithm, parameters ...v1alpha1.SweepParameter) *v1alpha1.SubmarineSweep {
+	sweep := &v1alpha1.SubmarineSweep{
+		ObjectMeta: metav1.ObjectMeta{Name: "mnist", Namespace: "submarine-user-test", UID: "sweep-uid"},
+		Spec: v1alpha1.SubmarineSweepSpec{
+			Algorithm:  algorithm,
+			Parameters: parameters,
+			Objective:  v1alpha1.SweepObjective{Metric: "accuracy", File: "/tmp/metrics"},
+		},
+	}
+	sweep.Spec.Template = newTestExperiment().Spec
+	sweep.Spec.Template.Command = []string{"python", "mnist.py", "--learning_rate=${lr}"}
+	return sweep
+}
+
+func TestSweepTrials(t *testing.T) {
+	// Grid: the last parameter varies the fastest, ranges are divided by step
+	sweep := newTestSweep(v1alpha1.SweepGrid,
+		v1alpha1.SweepParameter{Name: "lr", Values: []string{"0.01", "0.1"}},
+		v1alpha1.SweepParameter{Name: "dropout", Min: "0.1", Max: "0.3", Step: "0.1"})
+	trials, err := sweepTrials(sweep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"lr": "0.01", "dropout": "0.1"},
+		{"lr": "0.01", "dropout": "0.2"},
+		{"lr": "0.01", "dropout": "0.3"},
+		{"lr": "0.1", "dropout": "0.1"},
+		{"lr": "0.1", "dropout": "0.2"},
+		{"lr": "0.1", "dropout": "0.3"},
+	}
+	if !reflect.DeepEqual(trials, want) {
+		t.Errorf("grid trials = %v, want %v", trials, want)
+	}
+	maxTrials := int32(2)
+	sweep.Spec.MaxTrials = &maxTrials
+	if trials, _ := sweepTrials(sweep); len(trials) != 2 {
+		t.Errorf("expected maxTrials to cap the trials, got %v", trials)
+	}
+
+	// Random: the same trials on every reconcile, within the range
+	maxTrials = 5
+	sweep = newTestSweep(v1alpha1.SweepRandom, v1alpha1.SweepParameter{Name: "batch_size", Min: "100", Max: "200"})
+	sweep.Spec.MaxTrials = &maxTrials
+	trials, err = sweepTrials(sweep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := sweepTrials(sweep); !reflect.DeepEqual(trials, again) {
+		t.Errorf("expected the same random trials, got %v and %v", trials, again)
+	}
+	for _, trial := range trials {
+		if value, err := strconv.Atoi(trial["batch_size"]); err != nil || value < 100 || value > 200 {
+			t.Errorf("expected an integer from 100 to 200, got %q", trial["batch_size"])
+		}
+	}
+	sweep.Spec.MaxTrials = nil
+	if _, err := sweepTrials(sweep); err == nil {
+		t.Error("expected an error for Random without maxTrials")
+	}
+
+	// Invalid range
+	sweep = newTestSweep(v1alpha1.SweepGrid, v1alpha1.SweepParameter{Name: "lr", Min: "0.1", Max: "0.01", Step: "0.01"})
+	if _, err := sweepTrials(sweep); err == nil {
+		t.Error("expected an error for max less than min")
+	}
+}
+
+func TestNewTrialExperiment(t *testing.T) {
+	sweep := newTestSweep(v1alpha1.SweepList)
+	experiment := newTrialExperiment(sweep, 3, map[string]string{"lr": "0.05"})
+	if experiment.Name != "mnist-3" || !metav1.IsControlledBy(experiment, sweep) {
+		t.Errorf("unexpected trial %s owned by %v", experiment.Name, experiment.OwnerReferences)
+	}
+	if command := experiment.Spec.Command[2]; command != "--learning_rate=0.05" {
+		t.Errorf("command = %s, want --learning_rate=0.05", command)
+	}
+	if sweep.Spec.Template.Command[2] != "--learning_rate=${lr}" {
+		t.Error("expected the template to be unchanged")
+	}
+	if experiment.Spec.TerminationMessagePath != "/tmp/metrics" {
+		t.Errorf("terminationMessagePath = %s, want /tmp/metrics", experiment.Spec.TerminationMessagePath)
+	}
+}
+
+func TestParseObjective(t *testing.T) {
+	pattern, err := objectivePattern(v1alpha1.SweepObjective{Metric: "accuracy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "step 1 accuracy=0.5\nstep 2 accuracy: 0.93\nval_accuracy=1e-2\n"
+	// The last match wins, val_accuracy included
+	if value, ok := parseObjective(pattern, text); !ok || value != "1e-2" {
+		t.Errorf("value = %q, %v, want 1e-2", value, ok)
+	}
+	pattern, _ = objectivePattern(v1alpha1.SweepObjective{Metric: "accuracy", LogPattern: `^step \d+ accuracy: ([0-9.]+)`})
+	if _, ok := parseObjective(pattern, "loss=1"); ok {
+		t.Error("expected no match")
+	}
+}
+
+func TestReconcileTrials(t *testing.T) {
+	sweep := newTestSweep(v1alpha1.SweepList)
+	sweep.Spec.Trials = []map[string]string{{"lr": "0.01"}, {"lr": "0.1"}, {"lr": "1"}}
+
+	// One trial at a time
+	c, submarineClient := newSweepTestController(sweep)
+	if err := c.reconcileTrials(sweep); err != nil {
+		t.Fatal(err)
+	}
+	experiments, _ := submarineClient.SubmarineV1alpha1().SubmarineExperiments("submarine-user-test").List(context.TODO(), metav1.ListOptions{})
+	if len(experiments.Items) != 1 || experiments.Items[0].Name != "mnist-0" {
+		t.Fatalf("expected trial mnist-0, got %v", experiments.Items)
+	}
+
+	// The first two trials succeeded, the objective is read from the
+	// termination message
+	newTrial := func(index int, objective string) (*v1alpha1.SubmarineExperiment, *corev1.Pod) {
+		experiment := newTrialExperiment(sweep, index, sweep.Spec.Trials[index])
+		experiment.Status.Phase = v1alpha1.ExperimentSucceeded
+		experiment.Status.Pods = []v1alpha1.SubmarineExperimentPodStatus{{Name: experiment.Name + "-worker-0"}}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: experiment.Name + "-worker-0", Namespace: "submarine-user-test"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "accuracy=" + objective}}},
+				},
+			},
+		}
+		return experiment, pod
+	}
+	first, firstPod := newTrial(0, "0.91")
+	second, secondPod := newTrial(1, "0.95")
+	third := newTrialExperiment(sweep, 2, sweep.Spec.Trials[2])
+	c, _ = newSweepTestController(sweep, first, firstPod, second, secondPod)
+	if err := c.reconcileTrials(sweep); err != nil {
+		t.Fatal(err)
+	}
+	if sweep.Status.Succeeded != 2 || sweep.Status.Active != 1 || len(sweep.Status.Trials) != 3 {
+		t.Errorf("unexpected counts %+v", sweep.Status)
+	}
+	if best := sweep.Status.BestTrial; best == nil || best.Name != "mnist-1" || best.Objective != "0.95" {
+		t.Errorf("expected best trial mnist-1, got %+v", best)
+	}
+	if meta.IsStatusConditionTrue(sweep.Status.Conditions, v1alpha1.SubmarineSweepComplete) {
+		t.Error("expected the sweep to be incomplete")
+	}
+
+	// Minimize picks the other trial
+	sweep.Spec.Objective.Goal = v1alpha1.SweepMinimize
+	sweep.Status.Trials = nil
+	c, _ = newSweepTestController(sweep, first, firstPod, second, secondPod, third)
+	if err := c.reconcileTrials(sweep); err != nil {
+		t.Fatal(err)
+	}
+	if best := sweep.Status.BestTrial; best == nil || best.Name != "mnist-0" {
+		t.Errorf("expected best trial mnist-0, got %+v", best)
+	}
+}
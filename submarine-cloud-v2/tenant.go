@@ -0,0 +1,619 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientset "submarine-cloud-v2/pkg/generated/clientset/versioned"
+	submarinescheme "submarine-cloud-v2/pkg/generated/clientset/versioned/scheme"
+	informers "submarine-cloud-v2/pkg/generated/informers/externalversions/submarine/v1alpha1"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	rbacinformers "k8s.io/client-go/informers/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const tenantControllerAgentName = "submarine-tenant-controller"
+
+// tenantLabel is set on the namespace of a SubmarineTenant
+const tenantLabel = "submarine.k8s.io/tenant"
+
+// Names of the objects of a SubmarineTenant in its namespace
+const (
+	tenantObjectName       = "submarine-tenant"
+	tenantServerObjectName = "submarine-server"
+	tenantMemberObjectName = "submarine-tenant-member"
+)
+
+// Reasons of the Ready condition of a SubmarineTenant
+const (
+	TenantReconciledReason = "Reconciled"
+	NamespaceOwnedReason   = "NamespaceOwnedByOtherTenant"
+)
+
+// TenantController reconciles SubmarineTenants into a namespace with a
+// ResourceQuota, a LimitRange, and Roles and RoleBindings for the server of
+// the Submarine and the members of the tenant
+type TenantController struct {
+	kubeclientset      kubernetes.Interface
+	submarineclientset clientset.Interface
+
+	tenantsLister       listers.SubmarineTenantLister
+	tenantsSynced       cache.InformerSynced
+	submarinesLister    listers.SubmarineLister
+	namespaceLister     corelisters.NamespaceLister
+	resourcequotaLister corelisters.ResourceQuotaLister
+	limitrangeLister    corelisters.LimitRangeLister
+	roleLister          rbaclisters.RoleLister
+	rolebindingLister   rbaclisters.RoleBindingLister
+	// workqueue holds the names of the SubmarineTenants to reconcile
+	workqueue workqueue.RateLimitingInterface
+	recorder  record.EventRecorder
+}
+
+// NewTenantController returns a new SubmarineTenant controller
+func NewTenantController(
+	kubeclientset kubernetes.Interface,
+	submarineclientset clientset.Interface,
+	namespaceInformer coreinformers.NamespaceInformer,
+	resourcequotaInformer coreinformers.ResourceQuotaInformer,
+	limitrangeInformer coreinformers.LimitRangeInformer,
+	roleInformer rbacinformers.RoleInformer,
+	rolebindingInformer rbacinformers.RoleBindingInformer,
+	submarineInformer informers.SubmarineInformer,
+	tenantInformer informers.SubmarineTenantInformer) *TenantController {
+
+	utilruntime.Must(submarinescheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: tenantControllerAgentName})
+
+	controller := &TenantController{
+		kubeclientset:       kubeclientset,
+		submarineclientset:  submarineclientset,
+		tenantsLister:       tenantInformer.Lister(),
+		tenantsSynced:       tenantInformer.Informer().HasSynced,
+		submarinesLister:    submarineInformer.Lister(),
+		namespaceLister:     namespaceInformer.Lister(),
+		resourcequotaLister: resourcequotaInformer.Lister(),
+		limitrangeLister:    limitrangeInformer.Lister(),
+		roleLister:          roleInformer.Lister(),
+		rolebindingLister:   rolebindingInformer.Lister(),
+		workqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineTenants"),
+		recorder:            recorder,
+	}
+
+	klog.Info("Setting up SubmarineTenant event handlers")
+	tenantInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueTenant,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueTenant(new)
+		},
+	})
+	// The usage of the quota is reported in the status
+	resourcequotaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			if new.(*corev1.ResourceQuota).ResourceVersion == old.(*corev1.ResourceQuota).ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+
+	return controller
+}
+
+// Run waits for the caches to sync and processes SubmarineTenants until
+// stopCh is closed
+func (c *TenantController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Info("Starting SubmarineTenant controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.tenantsSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down SubmarineTenant workers")
+
+	return nil
+}
+
+func (c *TenantController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *TenantController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(key); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(key)
+		utilruntime.HandleError(fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.Infof("Successfully synced '%s'", key)
+	return true
+}
+
+func (c *TenantController) enqueueTenant(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// handleObject enqueues the SubmarineTenant that controls obj
+func (c *TenantController) handleObject(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+	ownerRef := metav1.GetControllerOf(object)
+	if ownerRef == nil || ownerRef.Kind != "SubmarineTenant" {
+		return
+	}
+	tenant, err := c.tenantsLister.Get(ownerRef.Name)
+	if err != nil {
+		return
+	}
+	c.enqueueTenant(tenant)
+}
+
+// syncHandler reconciles the SubmarineTenant named key
+func (c *TenantController) syncHandler(key string) error {
+	tenant, err := c.tenantsLister.Get(key)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tenant = tenant.DeepCopy()
+	klog.Info("[syncTenant] ", key)
+
+	if err := c.reconcileTenant(tenant); err != nil {
+		return err
+	}
+	return c.updateTenantStatus(tenant)
+}
+
+func tenantNamespace(tenant *v1alpha1.SubmarineTenant) string {
+	if tenant.Spec.Namespace != "" {
+		return tenant.Spec.Namespace
+	}
+	return tenant.Name
+}
+
+// tenantLabels are the labels of the objects of a SubmarineTenant
+func tenantLabels(tenant *v1alpha1.SubmarineTenant) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/part-of":    "submarine",
+		"app.kubernetes.io/managed-by": "submarine-operator",
+		tenantLabel:                    tenant.Name,
+	}
+}
+
+// tenantNamespaceLabels are the labels of the namespace of a
+// SubmarineTenant. part-of=kubeflow-profile marks it as a namespace of
+// workloads, like a Kubeflow profile.
+func tenantNamespaceLabels(tenant *v1alpha1.SubmarineTenant) map[string]string {
+	labels := map[string]string{}
+	for key, value := range tenant.Spec.NamespaceLabels {
+		labels[key] = value
+	}
+	for key, value := range tenantLabels(tenant) {
+		labels[key] = value
+	}
+	labels["app.kubernetes.io/part-of"] = "kubeflow-profile"
+	return labels
+}
+
+func tenantObjectMeta(tenant *v1alpha1.SubmarineTenant, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: tenantNamespace(tenant),
+		Labels:    tenantLabels(tenant),
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(tenant, v1alpha1.SchemeGroupVersion.WithKind("SubmarineTenant")),
+		},
+	}
+}
+
+func newTenantResourceQuota(tenant *v1alpha1.SubmarineTenant) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: tenantObjectMeta(tenant, tenantObjectName),
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: tenant.Spec.Quota,
+		},
+	}
+}
+
+func newTenantLimitRange(tenant *v1alpha1.SubmarineTenant) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: tenantObjectMeta(tenant, tenantObjectName),
+		Spec: corev1.LimitRangeSpec{
+			Limits: tenant.Spec.Limits,
+		},
+	}
+}
+
+// newTenantServerRole grants the server of the Submarine the permissions of
+// its ClusterRole in the namespace of the tenant
+func newTenantServerRole(tenant *v1alpha1.SubmarineTenant, submarine *v1alpha1.Submarine) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: tenantObjectMeta(tenant, tenantServerObjectName),
+		Rules:      newSubmarineServerClusterRole(submarine).Rules,
+	}
+}
+
+func newTenantServerRoleBinding(tenant *v1alpha1.SubmarineTenant, submarine *v1alpha1.Submarine) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: tenantObjectMeta(tenant, tenantServerObjectName),
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Namespace: submarine.Namespace,
+				Name:      serverName(submarine),
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     tenantServerObjectName,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+}
+
+// newTenantMemberRole lets the members manage the notebooks, jobs and
+// experiments, and read the pods and their logs
+func newTenantMemberRole(tenant *v1alpha1.SubmarineTenant, submarine *v1alpha1.Submarine) *rbacv1.Role {
+	resources := []string{"notebooks"}
+	if submarine != nil {
+		resources = operatorResources(submarine)
+	}
+	return &rbacv1.Role{
+		ObjectMeta: tenantObjectMeta(tenant, tenantMemberObjectName),
+		Rules: []rbacv1.PolicyRule{
+			{
+				Verbs:     []string{"get", "list", "watch", "create", "delete", "deletecollection", "patch", "update"},
+				APIGroups: []string{"kubeflow.org"},
+				Resources: resources,
+			},
+			{
+				Verbs:     []string{"get", "list", "watch", "create", "delete", "deletecollection", "patch", "update"},
+				APIGroups: []string{v1alpha1.SchemeGroupVersion.Group},
+				Resources: []string{"submarineexperiments", "submarinesweeps", "submarinetensorboards"},
+			},
+			{
+				Verbs:     []string{"get", "list", "watch"},
+				APIGroups: []string{""},
+				Resources: []string{"pods", "pods/log", "services", "events", "persistentvolumeclaims"},
+			},
+		},
+	}
+}
+
+func newTenantMemberRoleBinding(tenant *v1alpha1.SubmarineTenant) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: tenantObjectMeta(tenant, tenantMemberObjectName),
+		Subjects:   tenant.Spec.Members,
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     tenantMemberObjectName,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+}
+
+// reconcileTenant creates or updates the namespace of a SubmarineTenant and
+// its objects, and sets the Ready condition
+func (c *TenantController) reconcileTenant(tenant *v1alpha1.SubmarineTenant) error {
+	namespace := tenantNamespace(tenant)
+	tenant.Status.Namespace = namespace
+
+	// Step1: Create Namespace
+	ns, namespace_err := c.namespaceLister.Get(namespace)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(namespace_err) {
+		ns, namespace_err = c.kubeclientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: tenantNamespaceLabels(tenant),
+			},
+		}, metav1.CreateOptions{})
+		if namespace_err != nil {
+			klog.Info(namespace_err)
+		}
+		klog.Info("	Create Namespace: ", namespace)
+	}
+	if namespace_err != nil {
+		return namespace_err
+	}
+	if owner, ok := ns.Labels[tenantLabel]; ok && owner != tenant.Name {
+		c.setTenantReady(tenant, metav1.ConditionFalse, NamespaceOwnedReason, fmt.Sprintf("Namespace %s belongs to SubmarineTenant %q", namespace, owner))
+		return nil
+	}
+	// Label an existing namespace, keeping its other labels
+	desiredLabels := tenantNamespaceLabels(tenant)
+	nsCopy := ns.DeepCopy()
+	if nsCopy.Labels == nil {
+		nsCopy.Labels = map[string]string{}
+	}
+	for key, value := range desiredLabels {
+		nsCopy.Labels[key] = value
+	}
+	if !equality.Semantic.DeepEqual(ns.Labels, nsCopy.Labels) {
+		if _, err := c.kubeclientset.CoreV1().Namespaces().Update(context.TODO(), nsCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	// Step2: Create ResourceQuota
+	quota, err := c.reconcileTenantResourceQuota(tenant, namespace)
+	if err != nil {
+		return err
+	}
+	tenant.Status.Hard, tenant.Status.Used = nil, nil
+	if quota != nil {
+		tenant.Status.Hard = quota.Status.Hard
+		tenant.Status.Used = quota.Status.Used
+	}
+
+	// Step3: Create LimitRange
+	if err := c.reconcileTenantLimitRange(tenant, namespace); err != nil {
+		return err
+	}
+
+	// Step4: Create Roles and RoleBindings
+	submarine, err := c.submarinesLister.Submarines(tenant.Spec.Submarine.Namespace).Get(tenant.Spec.Submarine.Name)
+	if errors.IsNotFound(err) {
+		submarine = nil
+	} else if err != nil {
+		return err
+	}
+	if err := c.reconcileTenantRole(tenant, newTenantMemberRole(tenant, submarine), newTenantMemberRoleBinding(tenant)); err != nil {
+		return err
+	}
+	if submarine == nil {
+		c.setTenantReady(tenant, metav1.ConditionFalse, SubmarineNotFoundReason, fmt.Sprintf("Submarine %s/%s does not exist", tenant.Spec.Submarine.Namespace, tenant.Spec.Submarine.Name))
+		return nil
+	}
+	if err := c.reconcileTenantRole(tenant, newTenantServerRole(tenant, submarine), newTenantServerRoleBinding(tenant, submarine)); err != nil {
+		return err
+	}
+
+	c.setTenantReady(tenant, metav1.ConditionTrue, TenantReconciledReason, fmt.Sprintf("Namespace %s is ready", namespace))
+	return nil
+}
+
+// reconcileTenantResourceQuota creates or updates the ResourceQuota of a
+// SubmarineTenant, or deletes it when spec.quota is empty, and returns it
+func (c *TenantController) reconcileTenantResourceQuota(tenant *v1alpha1.SubmarineTenant, namespace string) (*corev1.ResourceQuota, error) {
+	desired := newTenantResourceQuota(tenant)
+	quota, quota_err := c.resourcequotaLister.ResourceQuotas(namespace).Get(tenantObjectName)
+	if len(tenant.Spec.Quota) == 0 {
+		if errors.IsNotFound(quota_err) {
+			return nil, nil
+		}
+		if quota_err != nil || !metav1.IsControlledBy(quota, tenant) {
+			return nil, quota_err
+		}
+		klog.Info("	Delete ResourceQuota: ", tenantObjectName)
+		err := c.kubeclientset.CoreV1().ResourceQuotas(namespace).Delete(context.TODO(), tenantObjectName, metav1.DeleteOptions{})
+		if errors.IsNotFound(err) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(quota_err) {
+		quota, quota_err = c.kubeclientset.CoreV1().ResourceQuotas(namespace).Create(context.TODO(), desired, metav1.CreateOptions{})
+		if quota_err != nil {
+			klog.Info(quota_err)
+		}
+		klog.Info("	Create ResourceQuota: ", tenantObjectName)
+	}
+	if quota_err != nil {
+		return nil, quota_err
+	}
+	if err := c.checkOwner(tenant, quota); err != nil {
+		return nil, err
+	}
+	if !equality.Semantic.DeepEqual(quota.Spec.Hard, desired.Spec.Hard) {
+		quotaCopy := quota.DeepCopy()
+		quotaCopy.Spec.Hard = desired.Spec.Hard
+		quota, quota_err = c.kubeclientset.CoreV1().ResourceQuotas(namespace).Update(context.TODO(), quotaCopy, metav1.UpdateOptions{})
+	}
+	return quota, quota_err
+}
+
+// reconcileTenantLimitRange creates or updates the LimitRange of a
+// SubmarineTenant, or deletes it when spec.limits is empty
+func (c *TenantController) reconcileTenantLimitRange(tenant *v1alpha1.SubmarineTenant, namespace string) error {
+	desired := newTenantLimitRange(tenant)
+	limitrange, limitrange_err := c.limitrangeLister.LimitRanges(namespace).Get(tenantObjectName)
+	if len(tenant.Spec.Limits) == 0 {
+		if errors.IsNotFound(limitrange_err) {
+			return nil
+		}
+		if limitrange_err != nil || !metav1.IsControlledBy(limitrange, tenant) {
+			return limitrange_err
+		}
+		klog.Info("	Delete LimitRange: ", tenantObjectName)
+		err := c.kubeclientset.CoreV1().LimitRanges(namespace).Delete(context.TODO(), tenantObjectName, metav1.DeleteOptions{})
+		if errors.IsNotFound(err) {
+			err = nil
+		}
+		return err
+	}
+
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(limitrange_err) {
+		limitrange, limitrange_err = c.kubeclientset.CoreV1().LimitRanges(namespace).Create(context.TODO(), desired, metav1.CreateOptions{})
+		if limitrange_err != nil {
+			klog.Info(limitrange_err)
+		}
+		klog.Info("	Create LimitRange: ", tenantObjectName)
+	}
+	if limitrange_err != nil {
+		return limitrange_err
+	}
+	if err := c.checkOwner(tenant, limitrange); err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(limitrange.Spec.Limits, desired.Spec.Limits) {
+		limitrangeCopy := limitrange.DeepCopy()
+		limitrangeCopy.Spec.Limits = desired.Spec.Limits
+		_, limitrange_err = c.kubeclientset.CoreV1().LimitRanges(namespace).Update(context.TODO(), limitrangeCopy, metav1.UpdateOptions{})
+	}
+	return limitrange_err
+}
+
+// reconcileTenantRole creates or updates a Role of a SubmarineTenant and
+// its RoleBinding
+func (c *TenantController) reconcileTenantRole(tenant *v1alpha1.SubmarineTenant, desiredRole *rbacv1.Role, desiredBinding *rbacv1.RoleBinding) error {
+	namespace := desiredRole.Namespace
+	role, role_err := c.roleLister.Roles(namespace).Get(desiredRole.Name)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(role_err) {
+		role, role_err = c.kubeclientset.RbacV1().Roles(namespace).Create(context.TODO(), desiredRole, metav1.CreateOptions{})
+		if role_err != nil {
+			klog.Info(role_err)
+		}
+		klog.Info("	Create Role: ", desiredRole.Name)
+	}
+	if role_err != nil {
+		return role_err
+	}
+	if err := c.checkOwner(tenant, role); err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(role.Rules, desiredRole.Rules) {
+		roleCopy := role.DeepCopy()
+		roleCopy.Rules = desiredRole.Rules
+		if _, role_err = c.kubeclientset.RbacV1().Roles(namespace).Update(context.TODO(), roleCopy, metav1.UpdateOptions{}); role_err != nil {
+			return role_err
+		}
+	}
+
+	rolebinding, rolebinding_err := c.rolebindingLister.RoleBindings(namespace).Get(desiredBinding.Name)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(rolebinding_err) {
+		rolebinding, rolebinding_err = c.kubeclientset.RbacV1().RoleBindings(namespace).Create(context.TODO(), desiredBinding, metav1.CreateOptions{})
+		if rolebinding_err != nil {
+			klog.Info(rolebinding_err)
+		}
+		klog.Info("	Create RoleBinding: ", desiredBinding.Name)
+	}
+	if rolebinding_err != nil {
+		return rolebinding_err
+	}
+	if err := c.checkOwner(tenant, rolebinding); err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(rolebinding.Subjects, desiredBinding.Subjects) {
+		rolebindingCopy := rolebinding.DeepCopy()
+		rolebindingCopy.Subjects = desiredBinding.Subjects
+		_, rolebinding_err = c.kubeclientset.RbacV1().RoleBindings(namespace).Update(context.TODO(), rolebindingCopy, metav1.UpdateOptions{})
+	}
+	return rolebinding_err
+}
+
+// checkOwner returns an error if obj is not controlled by the
+// SubmarineTenant
+func (c *TenantController) checkOwner(tenant *v1alpha1.SubmarineTenant, obj metav1.Object) error {
+	if metav1.IsControlledBy(obj, tenant) {
+		return nil
+	}
+	msg := fmt.Sprintf(MessageResourceExists, obj.GetNamespace()+"/"+obj.GetName())
+	c.recorder.Event(tenant, corev1.EventTypeWarning, ErrResourceExists, msg)
+	return fmt.Errorf(msg)
+}
+
+func (c *TenantController) setTenantReady(tenant *v1alpha1.SubmarineTenant, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&tenant.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SubmarineTenantReady,
+		Status:             status,
+		ObservedGeneration: tenant.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateTenantStatus writes the status of a SubmarineTenant if it changed
+func (c *TenantController) updateTenantStatus(tenant *v1alpha1.SubmarineTenant) error {
+	current, err := c.tenantsLister.Get(tenant.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(current.Status, tenant.Status) {
+		return nil
+	}
+	_, err = c.submarineclientset.SubmarineV1alpha1().SubmarineTenants().Update(context.TODO(), tenant, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,134 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	submarinefake "submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// newTenantTestController returns a controller whose listers and clientset
+// hold objs. Namespaces have their own indexer since their keys collide with
+// the ones of the cluster-scoped tenants.
+func newTenantTestController(objs ...metav1.Object) (*TenantController, *kubefake.Clientset) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	kubeClient := kubefake.NewSimpleClientset()
+	for _, obj := range objs {
+		if ns, ok := obj.(*corev1.Namespace); ok {
+			namespaceIndexer.Add(ns)
+			kubeClient.Tracker().Add(ns)
+		} else {
+			indexer.Add(obj)
+		}
+	}
+	return &TenantController{
+		kubeclientset:       kubeClient,
+		submarineclientset:  submarinefake.NewSimpleClientset(),
+		tenantsLister:       listers.NewSubmarineTenantLister(indexer),
+		submarinesLister:    listers.NewSubmarineLister(indexer),
+		namespaceLister:     corelisters.NewNamespaceLister(namespaceIndexer),
+		resourcequotaLister: corelisters.NewResourceQuotaLister(indexer),
+		limitrangeLister:    corelisters.NewLimitRangeLister(indexer),
+		roleLister:          rbaclisters.NewRoleLister(indexer),
+		rolebindingLister:   rbaclisters.NewRoleBindingLister(indexer),
+		recorder:            record.NewFakeRecorder(100),
+	}, kubeClient
+}
+
+func newTestTenant() *v1alpha1.SubmarineTenant {
+	return &v1alpha1.SubmarineTenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", UID: "tenant-uid"},
+		Spec: v1alpha1.SubmarineTenantSpec{
+			Submarine: v1alpha1.SubmarineReference{Namespace: "submarine-user-test", Name: "example-submarine"},
+			Quota:     corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("32")},
+			Members:   []rbacv1.Subject{{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "team-a"}},
+		},
+	}
+}
+
+func TestReconcileTenant(t *testing.T) {
+	tenant := newTestTenant()
+	submarine := newTestSubmarine()
+	c, kubeClient := newTenantTestController(tenant, submarine)
+	if err := c.reconcileTenant(tenant); err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(tenant.Status.Conditions, v1alpha1.SubmarineTenantReady) {
+		t.Errorf("expected the tenant to be ready, got %+v", tenant.Status.Conditions)
+	}
+
+	ns, err := kubeClient.CoreV1().Namespaces().Get(context.TODO(), "team-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Labels[tenantLabel] != "team-a" || ns.Labels["app.kubernetes.io/part-of"] != "kubeflow-profile" {
+		t.Errorf("unexpected labels of the namespace %v", ns.Labels)
+	}
+	quota, err := kubeClient.CoreV1().ResourceQuotas("team-a").Get(context.TODO(), tenantObjectName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu := quota.Spec.Hard[corev1.ResourceRequestsCPU]; cpu.String() != "32" {
+		t.Errorf("requests.cpu = %s, want 32", cpu.String())
+	}
+	if _, err := kubeClient.CoreV1().LimitRanges("team-a").Get(context.TODO(), tenantObjectName, metav1.GetOptions{}); err == nil {
+		t.Error("expected no LimitRange without spec.limits")
+	}
+	binding, err := kubeClient.RbacV1().RoleBindings("team-a").Get(context.TODO(), tenantServerObjectName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject := binding.Subjects[0]; subject.Namespace != "submarine-user-test" || subject.Name != serverName(submarine) {
+		t.Errorf("expected the service account of the server, got %+v", subject)
+	}
+	if _, err := kubeClient.RbacV1().RoleBindings("team-a").Get(context.TODO(), tenantMemberObjectName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the RoleBinding of the members, got %v", err)
+	}
+}
+
+func TestReconcileTenantNamespaceOwned(t *testing.T) {
+	tenant := newTestTenant()
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{tenantLabel: "team-b"}}}
+	c, kubeClient := newTenantTestController(tenant, ns)
+	if err := c.reconcileTenant(tenant); err != nil {
+		t.Fatal(err)
+	}
+	condition := meta.FindStatusCondition(tenant.Status.Conditions, v1alpha1.SubmarineTenantReady)
+	if condition == nil || condition.Reason != NamespaceOwnedReason {
+		t.Errorf("expected the Ready condition with reason %s, got %+v", NamespaceOwnedReason, condition)
+	}
+	if len(kubeClient.Actions()) != 0 {
+		t.Errorf("expected no actions, got %v", kubeClient.Actions())
+	}
+}
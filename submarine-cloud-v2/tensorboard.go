@@ -0,0 +1,621 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	clientset "submarine-cloud-v2/pkg/generated/clientset/versioned"
+	submarinescheme "submarine-cloud-v2/pkg/generated/clientset/versioned/scheme"
+	informers "submarine-cloud-v2/pkg/generated/informers/externalversions/submarine/v1alpha1"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	traefik "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/generated/clientset/versioned"
+	traefikinformers "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/generated/informers/externalversions/traefik/v1alpha1"
+	traefiklisters "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/generated/listers/traefik/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+)
+
+const tensorboardControllerAgentName = "submarine-tensorboard-controller"
+
+// lastActivityAnnotation is set by clients, e.g. the workbench, whenever a
+// SubmarineTensorboard is used. It postpones the deletion of an idle
+// SubmarineTensorboard.
+const lastActivityAnnotation = "submarine.k8s.io/last-activity"
+
+// Reasons of the events and of the Ready condition of a SubmarineTensorboard
+const (
+	TensorboardAvailableReason   = "DeploymentAvailable"
+	TensorboardUnavailableReason = "DeploymentUnavailable"
+	// SubmarineNotFoundReason is used when the Submarine or its tensorboard
+	// volume do not exist
+	SubmarineNotFoundReason = "SubmarineNotFound"
+	InvalidLogPathReason    = "InvalidLogPath"
+	// TensorboardExpired is used as part of the Event 'reason' when an idle
+	// SubmarineTensorboard is deleted
+	TensorboardExpired = "Expired"
+	// MessageTensorboardExpired is the message used for an Event fired when
+	// an idle SubmarineTensorboard is deleted
+	MessageTensorboardExpired = "Deleting SubmarineTensorboard, idle since %s"
+)
+
+// TensorboardController reconciles SubmarineTensorboards into a Deployment,
+// a Service and an IngressRoute each
+type TensorboardController struct {
+	kubeclientset      kubernetes.Interface
+	submarineclientset clientset.Interface
+	traefikclientset   traefik.Interface
+
+	tensorboardsLister          listers.SubmarineTensorboardLister
+	tensorboardsSynced          cache.InformerSynced
+	submarinesLister            listers.SubmarineLister
+	deploymentLister            appslisters.DeploymentLister
+	serviceLister               corelisters.ServiceLister
+	persistentvolumeclaimLister corelisters.PersistentVolumeClaimLister
+	ingressrouteLister          traefiklisters.IngressRouteLister
+	// workqueue holds the keys of the SubmarineTensorboards to reconcile
+	workqueue workqueue.RateLimitingInterface
+	recorder  record.EventRecorder
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// NewTensorboardController returns a new SubmarineTensorboard controller
+func NewTensorboardController(
+	kubeclientset kubernetes.Interface,
+	submarineclientset clientset.Interface,
+	traefikclientset traefik.Interface,
+	deploymentInformer appsinformers.DeploymentInformer,
+	serviceInformer coreinformers.ServiceInformer,
+	persistentvolumeclaimInformer coreinformers.PersistentVolumeClaimInformer,
+	ingressrouteInformer traefikinformers.IngressRouteInformer,
+	submarineInformer informers.SubmarineInformer,
+	tensorboardInformer informers.SubmarineTensorboardInformer) *TensorboardController {
+
+	utilruntime.Must(submarinescheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: tensorboardControllerAgentName})
+
+	controller := &TensorboardController{
+		kubeclientset:               kubeclientset,
+		submarineclientset:          submarineclientset,
+		traefikclientset:            traefikclientset,
+		tensorboardsLister:          tensorboardInformer.Lister(),
+		tensorboardsSynced:          tensorboardInformer.Informer().HasSynced,
+		submarinesLister:            submarineInformer.Lister(),
+		deploymentLister:            deploymentInformer.Lister(),
+		serviceLister:               serviceInformer.Lister(),
+		persistentvolumeclaimLister: persistentvolumeclaimInformer.Lister(),
+		ingressrouteLister:          ingressrouteInformer.Lister(),
+		workqueue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineTensorboards"),
+		recorder:                    recorder,
+		now:                         time.Now,
+	}
+
+	klog.Info("Setting up SubmarineTensorboard event handlers")
+	tensorboardInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueTensorboard,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueTensorboard(new)
+		},
+	})
+	// The objects of a deleted SubmarineTensorboard are garbage collected
+	deploymentInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			if new.(*appsv1.Deployment).ResourceVersion == old.(*appsv1.Deployment).ResourceVersion {
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	})
+
+	return controller
+}
+
+// Run waits for the caches to sync and processes SubmarineTensorboards until
+// stopCh is closed
+func (c *TensorboardController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Info("Starting SubmarineTensorboard controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.tensorboardsSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down SubmarineTensorboard workers")
+
+	return nil
+}
+
+func (c *TensorboardController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *TensorboardController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(key); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(key)
+		utilruntime.HandleError(fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.Infof("Successfully synced '%s'", key)
+	return true
+}
+
+func (c *TensorboardController) enqueueTensorboard(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// handleObject enqueues the SubmarineTensorboard that controls obj
+func (c *TensorboardController) handleObject(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+	ownerRef := metav1.GetControllerOf(object)
+	if ownerRef == nil || ownerRef.Kind != "SubmarineTensorboard" {
+		return
+	}
+	tensorboard, err := c.tensorboardsLister.SubmarineTensorboards(object.GetNamespace()).Get(ownerRef.Name)
+	if err != nil {
+		return
+	}
+	c.enqueueTensorboard(tensorboard)
+}
+
+// syncHandler reconciles the SubmarineTensorboard of key
+func (c *TensorboardController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	tensorboard, err := c.tensorboardsLister.SubmarineTensorboards(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tensorboard = tensorboard.DeepCopy()
+	klog.Info("[syncTensorboard] ", key)
+
+	expired, err := c.reconcileTensorboardTTL(tensorboard)
+	if err != nil || expired {
+		return err
+	}
+
+	deployment, err := c.reconcileTensorboardObjects(tensorboard)
+	if err != nil {
+		return err
+	}
+	return c.updateTensorboardStatus(tensorboard, deployment)
+}
+
+// reconcileTensorboardTTL deletes an idle SubmarineTensorboard once its TTL
+// has passed, and otherwise requeues it for then
+func (c *TensorboardController) reconcileTensorboardTTL(tensorboard *v1alpha1.SubmarineTensorboard) (bool, error) {
+	lastActivity := tensorboardLastActivity(tensorboard)
+	tensorboard.Status.LastActivityTime = &lastActivity
+	tensorboard.Status.ExpirationTime = nil
+	if tensorboard.Spec.TTLSecondsAfterIdle == nil {
+		return false, nil
+	}
+
+	expiration := lastActivity.Add(time.Duration(*tensorboard.Spec.TTLSecondsAfterIdle) * time.Second)
+	tensorboard.Status.ExpirationTime = &metav1.Time{Time: expiration}
+	if remaining := expiration.Sub(c.now()); remaining > 0 {
+		c.workqueue.AddAfter(tensorboard.Namespace+"/"+tensorboard.Name, remaining)
+		return false, nil
+	}
+
+	klog.Info("	Delete SubmarineTensorboard: ", tensorboard.Name)
+	c.recorder.Eventf(tensorboard, corev1.EventTypeNormal, TensorboardExpired, MessageTensorboardExpired, lastActivity.Format(time.RFC3339))
+	err := c.submarineclientset.SubmarineV1alpha1().SubmarineTensorboards(tensorboard.Namespace).Delete(context.TODO(), tensorboard.Name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		err = nil
+	}
+	return true, err
+}
+
+// tensorboardLastActivity returns the latest of the creation and of the
+// last-activity annotation. An invalid annotation is ignored.
+func tensorboardLastActivity(tensorboard *v1alpha1.SubmarineTensorboard) metav1.Time {
+	lastActivity := tensorboard.CreationTimestamp
+	if value, ok := tensorboard.Annotations[lastActivityAnnotation]; ok {
+		if activity, err := time.Parse(time.RFC3339, value); err == nil && activity.After(lastActivity.Time) {
+			lastActivity = metav1.Time{Time: activity}
+		}
+	}
+	return lastActivity
+}
+
+// tensorboardLogPath returns the directory of the logs of a
+// SubmarineTensorboard relative to the tensorboard volume, or an error if it
+// leaves the volume
+func tensorboardLogPath(tensorboard *v1alpha1.SubmarineTensorboard) (string, error) {
+	logPath := tensorboard.Spec.LogPath
+	if tensorboard.Spec.ExperimentID != "" {
+		logPath = tensorboard.Spec.ExperimentID
+	}
+	if logPath == "" {
+		return "", nil
+	}
+	cleaned := path.Clean(strings.TrimPrefix(logPath, "/"))
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("log path %q leaves the tensorboard volume", logPath)
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+func tensorboardInstanceName(tensorboard *v1alpha1.SubmarineTensorboard) string {
+	return "tensorboard-" + tensorboard.Name
+}
+
+func tensorboardPath(tensorboard *v1alpha1.SubmarineTensorboard) string {
+	return "/tensorboard/" + tensorboard.Name
+}
+
+// tensorboardInstanceLabels are the labels of the objects of a
+// SubmarineTensorboard
+func tensorboardInstanceLabels(tensorboard *v1alpha1.SubmarineTensorboard, selector map[string]string) map[string]string {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "tensorboard",
+		"app.kubernetes.io/instance":   tensorboard.Name,
+		"app.kubernetes.io/component":  "tensorboard",
+		"app.kubernetes.io/part-of":    "submarine",
+		"app.kubernetes.io/managed-by": "submarine-operator",
+		"submarine.k8s.io/submarine":   tensorboard.Spec.Submarine,
+	}
+	for key, value := range selector {
+		labels[key] = value
+	}
+	return labels
+}
+
+func tensorboardOwnerReferences(tensorboard *v1alpha1.SubmarineTensorboard) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		*metav1.NewControllerRef(tensorboard, v1alpha1.SchemeGroupVersion.WithKind("SubmarineTensorboard")),
+	}
+}
+
+func newTensorboardInstanceDeployment(tensorboard *v1alpha1.SubmarineTensorboard, submarine *v1alpha1.Submarine, logPath string) *appsv1.Deployment {
+	selector := map[string]string{
+		"app": tensorboardInstanceName(tensorboard) + "-pod",
+	}
+	image := tensorboard.Spec.Image
+	if image == "" {
+		image = tensorboardImage(submarine)
+	}
+	replicas := int32(1)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            tensorboardInstanceName(tensorboard),
+			Labels:          tensorboardInstanceLabels(tensorboard, nil),
+			OwnerReferences: tensorboardOwnerReferences(tensorboard),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: tensorboardInstanceLabels(tensorboard, selector),
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: submarine.Spec.ImagePullSecrets,
+					Containers: []corev1.Container{
+						{
+							Name:  "tensorboard",
+							Image: image,
+							Command: []string{
+								"tensorboard",
+								"--logdir=/logs",
+								"--path_prefix=" + tensorboardPath(tensorboard),
+							},
+							ImagePullPolicy: "IfNotPresent",
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: 6006,
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									MountPath: "/logs",
+									Name:      "volume",
+									SubPath:   path.Join(tensorboardName(submarine), logPath),
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "volume",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: tensorboardName(submarine) + "-pvc",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTensorboardInstanceService(tensorboard *v1alpha1.SubmarineTensorboard) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            tensorboardInstanceName(tensorboard),
+			Labels:          tensorboardInstanceLabels(tensorboard, nil),
+			OwnerReferences: tensorboardOwnerReferences(tensorboard),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app": tensorboardInstanceName(tensorboard) + "-pod",
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Protocol:   "TCP",
+					Port:       8080,
+					TargetPort: intstr.FromInt(6006),
+				},
+			},
+		},
+	}
+}
+
+func newTensorboardInstanceIngressRoute(tensorboard *v1alpha1.SubmarineTensorboard) *traefikv1alpha1.IngressRoute {
+	return &traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            tensorboardInstanceName(tensorboard),
+			Labels:          tensorboardInstanceLabels(tensorboard, nil),
+			OwnerReferences: tensorboardOwnerReferences(tensorboard),
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			EntryPoints: []string{
+				"web",
+			},
+			Routes: []traefikv1alpha1.Route{
+				{
+					Kind: "Rule",
+					// Traefik prefers the longer rule over the one of the
+					// shared Tensorboard at /tensorboard
+					Match: fmt.Sprintf("PathPrefix(`%s`)", tensorboardPath(tensorboard)),
+					Services: []traefikv1alpha1.Service{
+						{
+							LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+								Kind: "Service",
+								Name: tensorboardInstanceName(tensorboard),
+								Port: 8080,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// reconcileTensorboardObjects creates or updates the Deployment, the Service
+// and the IngressRoute of a SubmarineTensorboard, and returns the
+// Deployment. It sets the Ready condition when the Submarine, its
+// tensorboard volume or the log path are missing or invalid.
+func (c *TensorboardController) reconcileTensorboardObjects(tensorboard *v1alpha1.SubmarineTensorboard) (*appsv1.Deployment, error) {
+	namespace := tensorboard.Namespace
+	tensorboard.Status.Path = tensorboardPath(tensorboard)
+
+	logPath, err := tensorboardLogPath(tensorboard)
+	if err != nil {
+		c.setTensorboardReady(tensorboard, metav1.ConditionFalse, InvalidLogPathReason, err.Error())
+		return nil, nil
+	}
+	tensorboard.Status.LogPath = logPath
+
+	submarine, err := c.submarinesLister.Submarines(namespace).Get(tensorboard.Spec.Submarine)
+	if errors.IsNotFound(err) {
+		c.setTensorboardReady(tensorboard, metav1.ConditionFalse, SubmarineNotFoundReason, fmt.Sprintf("Submarine %q does not exist", tensorboard.Spec.Submarine))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pvcName := tensorboardName(submarine) + "-pvc"
+	if _, err := c.persistentvolumeclaimLister.PersistentVolumeClaims(namespace).Get(pvcName); errors.IsNotFound(err) {
+		c.setTensorboardReady(tensorboard, metav1.ConditionFalse, SubmarineNotFoundReason, fmt.Sprintf("The tensorboard volume %s of Submarine %q does not exist", pvcName, submarine.Name))
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Step1: Create Deployment
+	desired := newTensorboardInstanceDeployment(tensorboard, submarine, logPath)
+	deployment, deployment_err := c.deploymentLister.Deployments(namespace).Get(desired.Name)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(deployment_err) {
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Create(context.TODO(), desired, metav1.CreateOptions{})
+		if deployment_err != nil {
+			klog.Info(deployment_err)
+		}
+		klog.Info("	Create Deployment: ", desired.Name)
+	}
+	if deployment_err != nil {
+		return nil, deployment_err
+	}
+	if err := c.checkOwner(tensorboard, deployment); err != nil {
+		return nil, err
+	}
+	// Update the Deployment if the image or the log path changed
+	if !equality.Semantic.DeepDerivative(desired.Spec.Template.Spec, deployment.Spec.Template.Spec) {
+		deploymentCopy := deployment.DeepCopy()
+		deploymentCopy.Spec.Template = desired.Spec.Template
+		deployment, deployment_err = c.kubeclientset.AppsV1().Deployments(namespace).Update(context.TODO(), deploymentCopy, metav1.UpdateOptions{})
+		if deployment_err != nil {
+			return nil, deployment_err
+		}
+	}
+
+	// Step2: Create Service
+	service, service_err := c.serviceLister.Services(namespace).Get(tensorboardInstanceName(tensorboard))
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(service_err) {
+		service, service_err = c.kubeclientset.CoreV1().Services(namespace).Create(context.TODO(), newTensorboardInstanceService(tensorboard), metav1.CreateOptions{})
+		if service_err != nil {
+			klog.Info(service_err)
+		}
+		klog.Info("	Create Service: ", tensorboardInstanceName(tensorboard))
+	}
+	if service_err != nil {
+		return nil, service_err
+	}
+	if err := c.checkOwner(tensorboard, service); err != nil {
+		return nil, err
+	}
+
+	// Step3: Create IngressRoute
+	ingressroute, ingressroute_err := c.ingressrouteLister.IngressRoutes(namespace).Get(tensorboardInstanceName(tensorboard))
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(ingressroute_err) {
+		ingressroute, ingressroute_err = c.traefikclientset.TraefikV1alpha1().IngressRoutes(namespace).Create(context.TODO(), newTensorboardInstanceIngressRoute(tensorboard), metav1.CreateOptions{})
+		if ingressroute_err != nil {
+			klog.Info(ingressroute_err)
+		}
+		klog.Info("	Create IngressRoute: ", tensorboardInstanceName(tensorboard))
+	}
+	if ingressroute_err != nil {
+		return nil, ingressroute_err
+	}
+	if err := c.checkOwner(tensorboard, ingressroute); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// checkOwner returns an error if obj is not controlled by the
+// SubmarineTensorboard
+func (c *TensorboardController) checkOwner(tensorboard *v1alpha1.SubmarineTensorboard, obj metav1.Object) error {
+	if metav1.IsControlledBy(obj, tensorboard) {
+		return nil
+	}
+	msg := fmt.Sprintf(MessageResourceExists, obj.GetName())
+	c.recorder.Event(tensorboard, corev1.EventTypeWarning, ErrResourceExists, msg)
+	return fmt.Errorf(msg)
+}
+
+func (c *TensorboardController) setTensorboardReady(tensorboard *v1alpha1.SubmarineTensorboard, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&tensorboard.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SubmarineTensorboardReady,
+		Status:             status,
+		ObservedGeneration: tensorboard.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateTensorboardStatus sets the Ready condition from the Deployment and
+// writes the status if it changed
+func (c *TensorboardController) updateTensorboardStatus(tensorboard *v1alpha1.SubmarineTensorboard, deployment *appsv1.Deployment) error {
+	if deployment != nil {
+		status, reason := metav1.ConditionFalse, TensorboardUnavailableReason
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Type == appsv1.DeploymentAvailable && condition.Status == corev1.ConditionTrue {
+				status, reason = metav1.ConditionTrue, TensorboardAvailableReason
+			}
+		}
+		c.setTensorboardReady(tensorboard, status, reason, fmt.Sprintf("Deployment %s has %d/%d available replicas", deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.Replicas))
+	}
+
+	current, err := c.tensorboardsLister.SubmarineTensorboards(tensorboard.Namespace).Get(tensorboard.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(current.Status, tensorboard.Status) {
+		return nil
+	}
+	_, err = c.submarineclientset.SubmarineV1alpha1().SubmarineTensorboards(tensorboard.Namespace).Update(context.TODO(), tensorboard, metav1.UpdateOptions{})
+	return err
+}
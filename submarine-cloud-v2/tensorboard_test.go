@@ -0,0 +1,206 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	submarinefake "submarine-cloud-v2/pkg/generated/clientset/versioned/fake"
+	listers "submarine-cloud-v2/pkg/generated/listers/submarine/v1alpha1"
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	traefikfake "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/generated/clientset/versioned/fake"
+	traefiklisters "github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/generated/listers/traefik/v1alpha1"
+)
+
+var tensorboardTestNow = time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newTensorboardTestController returns a controller whose listers and
+// clientsets hold objs
+func newTensorboardTestController(objs ...metav1.Object) (*TensorboardController, *submarinefake.Clientset) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	kubeClient := kubefake.NewSimpleClientset()
+	submarineClient := submarinefake.NewSimpleClientset()
+	for _, obj := range objs {
+		indexer.Add(obj)
+		switch obj := obj.(type) {
+		case *v1alpha1.Submarine:
+			submarineClient.Tracker().Add(obj)
+		case *v1alpha1.SubmarineTensorboard:
+			submarineClient.Tracker().Add(obj)
+		case *corev1.PersistentVolumeClaim:
+			kubeClient.Tracker().Add(obj)
+		}
+	}
+	return &TensorboardController{
+		kubeclientset:               kubeClient,
+		submarineclientset:          submarineClient,
+		traefikclientset:            traefikfake.NewSimpleClientset(),
+		tensorboardsLister:          listers.NewSubmarineTensorboardLister(indexer),
+		submarinesLister:            listers.NewSubmarineLister(indexer),
+		deploymentLister:            appslisters.NewDeploymentLister(indexer),
+		serviceLister:               corelisters.NewServiceLister(indexer),
+		persistentvolumeclaimLister: corelisters.NewPersistentVolumeClaimLister(indexer),
+		ingressrouteLister:          traefiklisters.NewIngressRouteLister(indexer),
+		workqueue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SubmarineTensorboards"),
+		recorder:                    record.NewFakeRecorder(100),
+		now:                         func() time.Time { return tensorboardTestNow },
+	}, submarineClient
+}
+
+func newTestTensorboard(ttl *int32) *v1alpha1.SubmarineTensorboard {
+	return &v1alpha1.SubmarineTensorboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "mnist",
+			Namespace:         "submarine-user-test",
+			CreationTimestamp: metav1.Time{Time: tensorboardTestNow.Add(-2 * time.Hour)},
+		},
+		Spec: v1alpha1.SubmarineTensorboardSpec{
+			Submarine:           "example-submarine",
+			ExperimentID:        "experiment-1",
+			TTLSecondsAfterIdle: ttl,
+		},
+	}
+}
+
+func TestTensorboardLogPath(t *testing.T) {
+	tests := []struct {
+		experimentID string
+		logPath      string
+		want         string
+		wantErr      bool
+	}{
+		{"", "", "", false},
+		{"experiment-1", "ignored", "experiment-1", false},
+		{"", "/team-a/run-1/", "team-a/run-1", false},
+		{"", "team-a/../run-1", "run-1", false},
+		{"", "../other", "", true},
+		{"", "/team-a/../../other", "", true},
+	}
+	for _, test := range tests {
+		tensorboard := newTestTensorboard(nil)
+		tensorboard.Spec.ExperimentID = test.experimentID
+		tensorboard.Spec.LogPath = test.logPath
+		got, err := tensorboardLogPath(tensorboard)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("tensorboardLogPath(%q, %q) = %q, %v, want %q", test.experimentID, test.logPath, got, err, test.want)
+		}
+	}
+}
+
+func TestReconcileTensorboardTTL(t *testing.T) {
+	// Idle for two hours with a TTL of one hour: deleted
+	ttl := int32(3600)
+	tensorboard := newTestTensorboard(&ttl)
+	c, submarineClient := newTensorboardTestController(tensorboard)
+	expired, err := c.reconcileTensorboardTTL(tensorboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expired {
+		t.Fatal("expected the idle tensorboard to expire")
+	}
+	_, err = submarineClient.SubmarineV1alpha1().SubmarineTensorboards("submarine-user-test").Get(context.TODO(), "mnist", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the tensorboard to be deleted, got %v", err)
+	}
+
+	// Active 30 minutes ago: kept until 30 minutes from now
+	tensorboard = newTestTensorboard(&ttl)
+	tensorboard.Annotations = map[string]string{
+		lastActivityAnnotation: tensorboardTestNow.Add(-30 * time.Minute).Format(time.RFC3339),
+	}
+	c, _ = newTensorboardTestController(tensorboard)
+	expired, err = c.reconcileTensorboardTTL(tensorboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired {
+		t.Fatal("expected the active tensorboard to be kept")
+	}
+	if want := tensorboardTestNow.Add(30 * time.Minute); !tensorboard.Status.ExpirationTime.Time.Equal(want) {
+		t.Errorf("expirationTime = %s, want %s", tensorboard.Status.ExpirationTime, want)
+	}
+
+	// Without a TTL: never expires
+	tensorboard = newTestTensorboard(nil)
+	c, _ = newTensorboardTestController(tensorboard)
+	if expired, err = c.reconcileTensorboardTTL(tensorboard); err != nil || expired {
+		t.Fatalf("expected the tensorboard without TTL to be kept, got %v, %v", expired, err)
+	}
+	if tensorboard.Status.ExpirationTime != nil {
+		t.Errorf("expected no expirationTime, got %s", tensorboard.Status.ExpirationTime)
+	}
+}
+
+func TestReconcileTensorboardObjects(t *testing.T) {
+	// Without the Submarine: not ready
+	tensorboard := newTestTensorboard(nil)
+	c, _ := newTensorboardTestController(tensorboard)
+	if _, err := c.reconcileTensorboardObjects(tensorboard); err != nil {
+		t.Fatal(err)
+	}
+	if condition := meta.FindStatusCondition(tensorboard.Status.Conditions, v1alpha1.SubmarineTensorboardReady); condition == nil || condition.Reason != SubmarineNotFoundReason {
+		t.Fatalf("expected Ready condition with reason %s, got %v", SubmarineNotFoundReason, condition)
+	}
+
+	// With the Submarine and its volume: the objects are created
+	submarine := newTestSubmarine()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: tensorboardName(submarine) + "-pvc", Namespace: "submarine-user-test"},
+	}
+	tensorboard = newTestTensorboard(nil)
+	c, _ = newTensorboardTestController(tensorboard, submarine, pvc)
+	deployment, err := c.reconcileTensorboardObjects(tensorboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Image != tensorboardImage(submarine) {
+		t.Errorf("image = %s, want %s", container.Image, tensorboardImage(submarine))
+	}
+	if subPath := container.VolumeMounts[0].SubPath; subPath != tensorboardName(submarine)+"/experiment-1" {
+		t.Errorf("subPath = %s", subPath)
+	}
+	if tensorboard.Status.Path != "/tensorboard/mnist" {
+		t.Errorf("path = %s", tensorboard.Status.Path)
+	}
+	if _, err := c.kubeclientset.CoreV1().Services("submarine-user-test").Get(context.TODO(), "tensorboard-mnist", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the service to be created, got %v", err)
+	}
+	ingressroute, err := c.traefikclientset.TraefikV1alpha1().IngressRoutes("submarine-user-test").Get(context.TODO(), "tensorboard-mnist", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the ingressroute to be created, got %v", err)
+	}
+	if match := ingressroute.Spec.Routes[0].Match; match != "PathPrefix(`/tensorboard/mnist`)" {
+		t.Errorf("match = %s", match)
+	}
+}
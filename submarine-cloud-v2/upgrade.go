@@ -0,0 +1,430 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	"github.com/Masterminds/semver/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// databaseMigrationScript is run by the migration Job from the new
+	// database image. It gets FROM_VERSION and TO_VERSION, and applies the
+	// schema migrations in between.
+	databaseMigrationScript = "/tmp/database/upgrade.sh"
+	// databaseBackupDir is where the backup Job mounts the backups directory
+	// of the database volume
+	databaseBackupDir = "/backup"
+)
+
+// Reasons of the Upgrading condition and of the upgrade events
+const (
+	UpgradeInProgress = "UpgradeInProgress"
+	UpgradeSucceeded  = "UpgradeSucceeded"
+	UpgradeFailed     = "UpgradeFailed"
+	UpgradeCancelled  = "UpgradeCancelled"
+	DowngradeBlocked  = "DowngradeBlocked"
+	InvalidVersion    = "InvalidVersion"
+)
+
+// withVersion returns a copy of the Submarine that runs version
+func withVersion(submarine *v1alpha1.Submarine, version string) *v1alpha1.Submarine {
+	submarineCopy := submarine.DeepCopy()
+	submarineCopy.Spec.Version = version
+	return submarineCopy
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// upgradeJobName returns the name of the Job of a step of the upgrade to
+// version
+func upgradeJobName(submarine *v1alpha1.Submarine, step string, version string) string {
+	name := submarine.Name + "-" + step + "-" + invalidNameChars.ReplaceAllString(strings.ToLower(version), "-")
+	// Jobs label their pods with their name, which is limited to 63 characters
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+func databaseClientEnv(submarine *v1alpha1.Submarine) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "DATABASE_HOST",
+			Value: databaseName(submarine),
+		},
+		{
+			Name:  "MYSQL_ROOT_PASSWORD",
+			Value: databaseRootPassword,
+		},
+	}
+}
+
+func newSubmarineUpgradeJob(submarine *v1alpha1.Submarine, name string, container corev1.Container, volumes []corev1.Volume) *batchv1.Job {
+	backoffLimit := int32(2)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: submarineLabels(submarine, "database", nil),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(submarine, v1alpha1.SchemeGroupVersion.WithKind("Submarine")),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: submarineLabels(submarine, "database", nil),
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyNever,
+					ImagePullSecrets: submarine.Spec.ImagePullSecrets,
+					Containers:       []corev1.Container{container},
+					Volumes:          volumes,
+				},
+			},
+		},
+	}
+}
+
+// newSubmarineBackupJob returns the Job dumping every database with the
+// image of the version before the upgrade. The dump is written to the
+// backups directory of the database volume.
+func newSubmarineBackupJob(submarine *v1alpha1.Submarine, upgrade *v1alpha1.SubmarineUpgradeStatus) *batchv1.Job {
+	env := append(databaseClientEnv(submarine), corev1.EnvVar{
+		Name:  "BACKUP_FILE",
+		Value: databaseBackupDir + "/" + upgrade.Backup,
+	})
+	container := corev1.Container{
+		Name:            "backup",
+		Image:           databaseImage(withVersion(submarine, upgrade.FromVersion)),
+		ImagePullPolicy: "IfNotPresent",
+		Command: []string{"sh", "-c", `set -e
+mysqldump -h "$DATABASE_HOST" -uroot -p"$MYSQL_ROOT_PASSWORD" --all-databases --single-transaction > "$BACKUP_FILE.tmp"
+mv "$BACKUP_FILE.tmp" "$BACKUP_FILE"`},
+		Env: env,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				MountPath: databaseBackupDir,
+				Name:      "volume",
+				SubPath:   databaseName(submarine) + "-backups",
+			},
+		},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: "volume",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: databaseName(submarine) + "-pvc",
+				},
+			},
+		},
+	}
+	return newSubmarineUpgradeJob(submarine, upgradeJobName(submarine, "backup", upgrade.ToVersion), container, volumes)
+}
+
+// backupFile returns the file of the backup of the upgrade to version. The
+// backup Job of an earlier attempt of the same upgrade keeps its file, which
+// is read back from its BACKUP_FILE; otherwise the file is named after the
+// versions and the generation of the Submarine.
+func (c *Controller) backupFile(submarine *v1alpha1.Submarine, version string) (string, error) {
+	job, err := c.jobLister.Jobs(submarine.Namespace).Get(upgradeJobName(submarine, "backup", version))
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	if err == nil {
+		for _, container := range job.Spec.Template.Spec.Containers {
+			for _, env := range container.Env {
+				if env.Name == "BACKUP_FILE" && env.Value != "" {
+					return path.Base(env.Value), nil
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("%s-%s-to-%s-%d.sql", submarine.Name, submarine.Status.Version, version, submarine.Generation), nil
+}
+
+// newSubmarineMigrationJob returns the Job migrating the schema of the
+// running database with the image of the new version. Images without a
+// migration script have nothing to migrate.
+func newSubmarineMigrationJob(submarine *v1alpha1.Submarine, upgrade *v1alpha1.SubmarineUpgradeStatus) *batchv1.Job {
+	env := append(databaseClientEnv(submarine),
+		corev1.EnvVar{
+			Name:  "FROM_VERSION",
+			Value: upgrade.FromVersion,
+		},
+		corev1.EnvVar{
+			Name:  "TO_VERSION",
+			Value: upgrade.ToVersion,
+		},
+	)
+	container := corev1.Container{
+		Name:            "migrate",
+		Image:           databaseImage(withVersion(submarine, upgrade.ToVersion)),
+		ImagePullPolicy: "IfNotPresent",
+		Command: []string{"sh", "-c", `if [ ! -x ` + databaseMigrationScript + ` ]; then
+  echo "No schema migration in this image"
+  exit 0
+fi
+exec ` + databaseMigrationScript},
+		Env: env,
+	}
+	return newSubmarineUpgradeJob(submarine, upgradeJobName(submarine, "migrate", upgrade.ToVersion), container, nil)
+}
+
+// reconcileUpgrade sequences a change of spec.version: the database is
+// backed up, its schema is migrated, and only then the server and the
+// database are rolled to the new version. submarine must be a copy, its
+// status is updated with the progress. It returns the Submarine the
+// components are created from, i.e. with the version they should run.
+func (c *Controller) reconcileUpgrade(submarine *v1alpha1.Submarine) (*v1alpha1.Submarine, error) {
+	klog.Info("[reconcileUpgrade]")
+
+	current := submarine.Status.Version
+	target := submarine.Spec.Version
+	upgrade := submarine.Status.Upgrade
+
+	// A new Submarine runs spec.version right away
+	if current == "" {
+		submarine.Status.Version = target
+		return withVersion(submarine, target), nil
+	}
+
+	if current == target {
+		if upgrade != nil && (upgrade.Phase == v1alpha1.UpgradePhaseBackingUp || upgrade.Phase == v1alpha1.UpgradePhaseMigrating) {
+			// spec.version was set back before the schema was migrated
+			c.stopUpgrade(submarine, UpgradeCancelled, fmt.Sprintf("Upgrade to %s cancelled, spec.version was set back to %s", upgrade.ToVersion, current))
+		}
+		return withVersion(submarine, current), nil
+	}
+
+	from, from_err := semver.NewVersion(current)
+	to, to_err := semver.NewVersion(target)
+	if from_err != nil || to_err != nil {
+		c.blockUpgrade(submarine, InvalidVersion, fmt.Sprintf("Cannot upgrade from %q to %q, versions must follow semantic versioning", current, target))
+		return withVersion(submarine, current), nil
+	}
+	if to.LessThan(from) {
+		c.blockUpgrade(submarine, DowngradeBlocked, fmt.Sprintf("Downgrade from %s to %s is not supported, set spec.version back to %s", current, target, current))
+		return withVersion(submarine, current), nil
+	}
+
+	// Start a new upgrade unless this one is already known
+	if upgrade == nil || upgrade.FromVersion != current || upgrade.ToVersion != target {
+		now := metav1.Now()
+		upgrade = &v1alpha1.SubmarineUpgradeStatus{
+			FromVersion: current,
+			ToVersion:   target,
+			Phase:       v1alpha1.UpgradePhaseBackingUp,
+			StartTime:   &now,
+		}
+		submarine.Status.Upgrade = upgrade
+		c.recorder.Eventf(submarine, corev1.EventTypeNormal, UpgradeInProgress, "Upgrading from %s to %s", current, target)
+
+		if storageEnabled(submarine) {
+			backup, err := c.backupFile(submarine, target)
+			if err != nil {
+				return nil, err
+			}
+			upgrade.Backup = backup
+		}
+	}
+
+	// Without a valid spec.storage there is no database to back up or
+	// migrate
+	if !storageEnabled(submarine) {
+		upgrade.Message = fmt.Sprintf("No database to back up or migrate without a valid spec.storage, rolling out %s", target)
+		c.recorder.Event(submarine, corev1.EventTypeWarning, UpgradeInProgress, upgrade.Message)
+		upgrade.Phase = v1alpha1.UpgradePhaseRollingOut
+		submarine.Status.Version = target
+		setUpgradingCondition(submarine, metav1.ConditionTrue, UpgradeInProgress, fmt.Sprintf("Rolling out %s", target))
+		return withVersion(submarine, target), nil
+	}
+
+	backupJob := newSubmarineBackupJob(submarine, upgrade)
+	migrationJob := newSubmarineMigrationJob(submarine, upgrade)
+
+	// Retry a failed upgrade once its failed Job is deleted
+	if upgrade.Phase == v1alpha1.UpgradePhaseFailed {
+		for _, name := range []string{backupJob.Name, migrationJob.Name} {
+			job, job_err := c.jobLister.Jobs(submarine.Namespace).Get(name)
+			if job_err != nil && !errors.IsNotFound(job_err) {
+				return nil, job_err
+			}
+			if job_err == nil && jobFinished(job, batchv1.JobFailed) {
+				return withVersion(submarine, current), nil
+			}
+		}
+		klog.Info("	Retry upgrade to ", target)
+		upgrade.Phase = v1alpha1.UpgradePhaseBackingUp
+		upgrade.Message = ""
+	}
+
+	if upgrade.Phase == v1alpha1.UpgradePhaseBackingUp {
+		job, err := c.newUpgradeJob(submarine, backupJob)
+		if err != nil {
+			return nil, err
+		}
+		if jobFinished(job, batchv1.JobFailed) {
+			c.stopUpgrade(submarine, UpgradeFailed, fmt.Sprintf("Backup Job %s failed, delete it to retry", job.Name))
+			return withVersion(submarine, current), nil
+		}
+		if !jobFinished(job, batchv1.JobComplete) {
+			setUpgradingCondition(submarine, metav1.ConditionTrue, UpgradeInProgress, fmt.Sprintf("Backing up the database before the upgrade to %s", target))
+			return withVersion(submarine, current), nil
+		}
+		upgrade.Phase = v1alpha1.UpgradePhaseMigrating
+	}
+
+	if upgrade.Phase == v1alpha1.UpgradePhaseMigrating {
+		job, err := c.newUpgradeJob(submarine, migrationJob)
+		if err != nil {
+			return nil, err
+		}
+		if jobFinished(job, batchv1.JobFailed) {
+			c.stopUpgrade(submarine, UpgradeFailed, fmt.Sprintf("Migration Job %s failed, delete it to retry. The backup is %s", job.Name, upgrade.Backup))
+			return withVersion(submarine, current), nil
+		}
+		if !jobFinished(job, batchv1.JobComplete) {
+			setUpgradingCondition(submarine, metav1.ConditionTrue, UpgradeInProgress, fmt.Sprintf("Migrating the database schema to %s", target))
+			return withVersion(submarine, current), nil
+		}
+	}
+
+	// The schema is migrated: from now on the components run the new
+	// version, and going back is a downgrade
+	upgrade.Phase = v1alpha1.UpgradePhaseRollingOut
+	submarine.Status.Version = target
+	setUpgradingCondition(submarine, metav1.ConditionTrue, UpgradeInProgress, fmt.Sprintf("Rolling out %s", target))
+	c.recorder.Eventf(submarine, corev1.EventTypeNormal, UpgradeInProgress, "Database schema migrated to %s, rolling out", target)
+	return withVersion(submarine, target), nil
+}
+
+// completeUpgrade finishes an upgrade once the server runs the new version
+func (c *Controller) completeUpgrade(submarine *v1alpha1.Submarine, serverDeployment *appsv1.Deployment) {
+	upgrade := submarine.Status.Upgrade
+	if upgrade == nil || upgrade.Phase != v1alpha1.UpgradePhaseRollingOut || !deploymentRolledOut(serverDeployment) {
+		return
+	}
+	now := metav1.Now()
+	upgrade.Phase = v1alpha1.UpgradePhaseSucceeded
+	upgrade.CompletionTime = &now
+	upgrade.Message = fmt.Sprintf("Upgraded from %s to %s", upgrade.FromVersion, upgrade.ToVersion)
+	setUpgradingCondition(submarine, metav1.ConditionFalse, UpgradeSucceeded, upgrade.Message)
+	c.recorder.Event(submarine, corev1.EventTypeNormal, UpgradeSucceeded, upgrade.Message)
+}
+
+// stopUpgrade marks the upgrade in progress as failed
+func (c *Controller) stopUpgrade(submarine *v1alpha1.Submarine, reason string, message string) {
+	upgrade := submarine.Status.Upgrade
+	upgrade.Phase = v1alpha1.UpgradePhaseFailed
+	upgrade.Message = message
+	setUpgradingCondition(submarine, metav1.ConditionFalse, reason, message)
+	c.recorder.Event(submarine, corev1.EventTypeWarning, reason, message)
+}
+
+// blockUpgrade refuses a change of spec.version, the components keep
+// running the current version
+func (c *Controller) blockUpgrade(submarine *v1alpha1.Submarine, reason string, message string) {
+	condition := meta.FindStatusCondition(submarine.Status.Conditions, v1alpha1.SubmarineUpgrading)
+	if condition == nil || condition.Reason != reason || condition.Message != message {
+		c.recorder.Event(submarine, corev1.EventTypeWarning, reason, message)
+	}
+	setUpgradingCondition(submarine, metav1.ConditionFalse, reason, message)
+}
+
+func setUpgradingCondition(submarine *v1alpha1.Submarine, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&submarine.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SubmarineUpgrading,
+		Status:             status,
+		ObservedGeneration: submarine.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// newUpgradeJob creates the Job of a step of the upgrade unless it exists
+func (c *Controller) newUpgradeJob(submarine *v1alpha1.Submarine, desired *batchv1.Job) (*batchv1.Job, error) {
+	namespace := submarine.Namespace
+	job, job_err := c.jobLister.Jobs(namespace).Get(desired.Name)
+	// If the resource doesn't exist, we'll create it
+	if errors.IsNotFound(job_err) {
+		job, job_err = c.kubeclientset.BatchV1().Jobs(namespace).Create(context.TODO(), desired, metav1.CreateOptions{})
+		if job_err != nil {
+			klog.Info(job_err)
+		}
+		klog.Info("	Create Job: ", job.Name)
+	}
+
+	// If an error occurs during Get/Create, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if job_err != nil {
+		return nil, job_err
+	}
+
+	if err := c.claimObject(submarine, job, "database", func(patch []byte) error {
+		job, job_err = c.kubeclientset.BatchV1().Jobs(namespace).Patch(context.TODO(), job.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return job_err
+	}); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// jobFinished reports whether a Job has the condition Complete or Failed
+func jobFinished(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// deploymentRolledOut reports whether every replica of a deployment runs
+// its latest template, like "kubectl rollout status"
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.Replicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
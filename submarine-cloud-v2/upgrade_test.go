@@ -0,0 +1,225 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	v1alpha1 "submarine-cloud-v2/pkg/submarine/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newUpgradeTestSubmarine returns a Submarine running statusVersion whose
+// spec asks for specVersion
+func newUpgradeTestSubmarine(specVersion string, statusVersion string) *v1alpha1.Submarine {
+	submarine := newTestSubmarine()
+	submarine.Spec.Version = specVersion
+	submarine.Spec.Storage = &v1alpha1.SubmarineStorage{StorageType: "host", HostPath: "/tmp"}
+	submarine.Status.Version = statusVersion
+	return submarine
+}
+
+func finishedJob(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.Job {
+	job.Namespace = "submarine-user-test"
+	job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+	return job
+}
+
+func TestReconcileUpgradeNewSubmarine(t *testing.T) {
+	c, _, _ := newTestController(t, testControllerOptions{})
+	submarine := newUpgradeTestSubmarine("0.6.0", "")
+
+	deployed, err := c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Spec.Version != "0.6.0" || submarine.Status.Version != "0.6.0" || submarine.Status.Upgrade != nil {
+		t.Errorf("a new Submarine should run spec.version right away, got %q, status %+v", deployed.Spec.Version, submarine.Status)
+	}
+}
+
+func TestReconcileUpgradeBlocksDowngrade(t *testing.T) {
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	submarine := newUpgradeTestSubmarine("0.5.0", "0.6.0")
+
+	deployed, err := c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Spec.Version != "0.6.0" {
+		t.Errorf("deployed version = %q, expected the current version 0.6.0", deployed.Spec.Version)
+	}
+	condition := meta.FindStatusCondition(submarine.Status.Conditions, v1alpha1.SubmarineUpgrading)
+	if condition == nil || condition.Reason != DowngradeBlocked {
+		t.Errorf("expected the Upgrading condition with reason %s, got %+v", DowngradeBlocked, condition)
+	}
+	if jobs, _ := kubeClient.BatchV1().Jobs("submarine-user-test").List(context.TODO(), metav1.ListOptions{}); len(jobs.Items) != 0 {
+		t.Errorf("a downgrade should not create Jobs, got %d", len(jobs.Items))
+	}
+}
+
+func TestReconcileUpgradeSequence(t *testing.T) {
+	// Step 1: the backup Job is created, the components keep the old version
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	submarine := newUpgradeTestSubmarine("0.7.0", "0.6.0")
+	deployed, err := c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgrade := submarine.Status.Upgrade
+	if deployed.Spec.Version != "0.6.0" || upgrade == nil || upgrade.Phase != v1alpha1.UpgradePhaseBackingUp {
+		t.Fatalf("expected a backup at version 0.6.0, got %q, upgrade %+v", deployed.Spec.Version, upgrade)
+	}
+	backupJob, err := kubeClient.BatchV1().Jobs("submarine-user-test").Get(context.TODO(), "example-submarine-backup-0-7-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := backupJob.Spec.Template.Spec.Containers[0].Image; image != "apache/submarine:database-0.6.0" {
+		t.Errorf("backup image = %q, expected the image of the old version", image)
+	}
+
+	// Step 2: the backup succeeded, the migration Job is created from the
+	// new database image
+	c, kubeClient, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{finishedJob(backupJob, batchv1.JobComplete)}})
+	deployed, err = c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Spec.Version != "0.6.0" || upgrade.Phase != v1alpha1.UpgradePhaseMigrating {
+		t.Fatalf("expected a migration at version 0.6.0, got %q, upgrade %+v", deployed.Spec.Version, upgrade)
+	}
+	migrationJob, err := kubeClient.BatchV1().Jobs("submarine-user-test").Get(context.TODO(), "example-submarine-migrate-0-7-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := migrationJob.Spec.Template.Spec.Containers[0].Image; image != "apache/submarine:database-0.7.0" {
+		t.Errorf("migration image = %q, expected the image of the new version", image)
+	}
+
+	// Step 3: the migration succeeded, the components roll to the new version
+	c, _, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{finishedJob(backupJob, batchv1.JobComplete), finishedJob(migrationJob, batchv1.JobComplete)}})
+	deployed, err = c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Spec.Version != "0.7.0" || submarine.Status.Version != "0.7.0" || upgrade.Phase != v1alpha1.UpgradePhaseRollingOut {
+		t.Fatalf("expected a rollout of 0.7.0, got %q, status %+v", deployed.Spec.Version, submarine.Status)
+	}
+
+	// Step 4: going back is a downgrade now
+	reverted := submarine.DeepCopy()
+	reverted.Spec.Version = "0.6.0"
+	if deployed, err = c.reconcileUpgrade(reverted); err != nil || deployed.Spec.Version != "0.7.0" {
+		t.Errorf("reverting after the migration should be blocked, got %q, %v", deployed.Spec.Version, err)
+	}
+}
+
+func TestReconcileUpgradeFailure(t *testing.T) {
+	submarine := newUpgradeTestSubmarine("0.7.0", "0.6.0")
+	c, _, _ := newTestController(t, testControllerOptions{})
+	if _, err := c.reconcileUpgrade(submarine); err != nil {
+		t.Fatal(err)
+	}
+	backupJob := newSubmarineBackupJob(submarine, submarine.Status.Upgrade)
+
+	c, _, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{finishedJob(backupJob, batchv1.JobFailed)}})
+	deployed, err := c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Spec.Version != "0.6.0" || submarine.Status.Upgrade.Phase != v1alpha1.UpgradePhaseFailed {
+		t.Fatalf("expected a failed upgrade at version 0.6.0, got %q, upgrade %+v", deployed.Spec.Version, submarine.Status.Upgrade)
+	}
+	condition := meta.FindStatusCondition(submarine.Status.Conditions, v1alpha1.SubmarineUpgrading)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != UpgradeFailed {
+		t.Errorf("expected the Upgrading condition with reason %s, got %+v", UpgradeFailed, condition)
+	}
+
+	// The upgrade stays failed as long as the failed Job exists
+	if _, err := c.reconcileUpgrade(submarine); err != nil || submarine.Status.Upgrade.Phase != v1alpha1.UpgradePhaseFailed {
+		t.Errorf("expected the upgrade to stay failed, got %+v, %v", submarine.Status.Upgrade, err)
+	}
+
+	// Deleting the failed Job retries the upgrade
+	c, _, _ = newTestController(t, testControllerOptions{})
+	if _, err := c.reconcileUpgrade(submarine); err != nil || submarine.Status.Upgrade.Phase != v1alpha1.UpgradePhaseBackingUp {
+		t.Errorf("expected the upgrade to be retried, got %+v, %v", submarine.Status.Upgrade, err)
+	}
+}
+
+func TestReconcileUpgradeBackupFile(t *testing.T) {
+	submarine := newUpgradeTestSubmarine("0.7.0", "0.6.0")
+	submarine.Generation = 3
+	c, _, _ := newTestController(t, testControllerOptions{})
+	if _, err := c.reconcileUpgrade(submarine); err != nil {
+		t.Fatal(err)
+	}
+	if backup := submarine.Status.Upgrade.Backup; backup != "example-submarine-0.6.0-to-0.7.0-3.sql" {
+		t.Errorf("backup = %q", backup)
+	}
+
+	// A restarted upgrade keeps the file of the existing backup Job
+	earlier := newSubmarineBackupJob(submarine, &v1alpha1.SubmarineUpgradeStatus{
+		FromVersion: "0.6.0",
+		ToVersion:   "0.7.0",
+		Backup:      "example-submarine-0.6.0-to-0.7.0-1.sql",
+	})
+	earlier.Namespace = "submarine-user-test"
+	submarine.Status.Upgrade = nil
+	c, _, _ = newTestController(t, testControllerOptions{objects: []runtime.Object{earlier}})
+	if _, err := c.reconcileUpgrade(submarine); err != nil {
+		t.Fatal(err)
+	}
+	if backup := submarine.Status.Upgrade.Backup; backup != "example-submarine-0.6.0-to-0.7.0-1.sql" {
+		t.Errorf("backup = %q, expected the file of the existing Job", backup)
+	}
+}
+
+func TestReconcileUpgradeWithoutStorage(t *testing.T) {
+	submarine := newUpgradeTestSubmarine("0.7.0", "0.6.0")
+	submarine.Spec.Storage = nil
+	c, kubeClient, _ := newTestController(t, testControllerOptions{})
+	deployed, err := c.reconcileUpgrade(submarine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgrade := submarine.Status.Upgrade
+	if deployed.Spec.Version != "0.7.0" || upgrade.Phase != v1alpha1.UpgradePhaseRollingOut || upgrade.Backup != "" {
+		t.Fatalf("expected a rollout of 0.7.0 without a backup, got %q, upgrade %+v", deployed.Spec.Version, upgrade)
+	}
+	if jobs, _ := kubeClient.BatchV1().Jobs("submarine-user-test").List(context.TODO(), metav1.ListOptions{}); len(jobs.Items) != 0 {
+		t.Errorf("expected no Jobs without a database, got %d", len(jobs.Items))
+	}
+}
+
+func TestUpgradeJobName(t *testing.T) {
+	submarine := newUpgradeTestSubmarine("0.6.0-SNAPSHOT", "")
+	if name := upgradeJobName(submarine, "migrate", "0.6.0-SNAPSHOT"); name != "example-submarine-migrate-0-6-0-snapshot" {
+		t.Errorf("upgradeJobName() = %q", name)
+	}
+	submarine.Name = "a-very-long-name-for-a-submarine-that-does-not-fit-into-a-label"
+	if name := upgradeJobName(submarine, "migrate", "0.6.0"); len(name) > 63 {
+		t.Errorf("upgradeJobName() = %q, longer than 63 characters", name)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// jsonPatchOperation is an operation of the JSON patch returned by a
+// mutating webhook
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// admitFunc reviews an admission request and returns the JSON patch of its
+// object, if any. An error denies the request.
+type admitFunc func(request *admissionv1.AdmissionRequest) ([]jsonPatchOperation, error)
+
+// jsonPointer escapes a key of a JSON pointer, e.g. an annotation
+func jsonPointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// serveAdmission decodes an AdmissionReview, reviews its request with admit
+// and writes the response
+func serveAdmission(admit admitFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("invalid AdmissionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := &admissionv1.AdmissionResponse{
+			UID:     review.Request.UID,
+			Allowed: true,
+		}
+		patch, err := admit(review.Request)
+		if err != nil {
+			klog.Info("Deny ", review.Request.Kind.Kind, " ", review.Request.Namespace, "/", review.Request.Name, ": ", err)
+			response.Allowed = false
+			response.Result = &metav1.Status{Message: err.Error()}
+		} else if len(patch) != 0 {
+			patchBytes, err := json.Marshal(patch)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			patchType := admissionv1.PatchTypeJSONPatch
+			response.Patch = patchBytes
+			response.PatchType = &patchType
+		}
+
+		review.Response = response
+		review.Request = nil
+		out, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+	}
+}
+
+// runWebhookServer serves the admission webhooks of handlers, by path, over
+// HTTPS with the tls.crt and tls.key of certDir until stopCh is closed
+func runWebhookServer(port int, certDir string, handlers map[string]admitFunc, stopCh <-chan struct{}) error {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	for path, admit := range handlers {
+		mux.Handle(path, serveAdmission(admit))
+	}
+	server := &http.Server{
+		Addr:      fmt.Sprintf(":%d", port),
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	go func() {
+		<-stopCh
+		server.Shutdown(context.TODO())
+	}()
+
+	klog.Info("Serving admission webhooks on port ", port)
+	if err := server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}